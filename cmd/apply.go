@@ -1,8 +1,8 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 
@@ -10,6 +10,7 @@ import (
 	"github.com/kazuma-desu/etu/pkg/config"
 	"github.com/kazuma-desu/etu/pkg/models"
 	"github.com/kazuma-desu/etu/pkg/output"
+	"github.com/kazuma-desu/etu/pkg/parsers"
 	"github.com/kazuma-desu/etu/pkg/validator"
 )
 
@@ -19,7 +20,10 @@ var (
 	applyCmd = &cobra.Command{
 		Use:   "apply -f <file>",
 		Short: "Apply configuration to etcd",
-		Long:  `Parse, validate, and apply configuration from a file to etcd.`,
+		Long: `Parse, validate, and apply configuration from a file to etcd.
+
+--prefix mounts the file's keys under a prefix. diff takes the same prefix
+as --mount, since its --prefix filters the comparison instead.`,
 		Example: `  # Apply configuration
   etu apply -f config.txt
 
@@ -34,7 +38,15 @@ var (
   etu apply -f config.txt -o json
 
   # Strict validation (warnings as errors)
-  etu apply -f config.txt --strict`,
+  etu apply -f config.txt --strict
+
+  # Deploy one file to several locations
+  etu apply -f service.yaml --prefix /services/api
+  etu apply -f service.yaml --prefix /services/worker --key-map worker-keys.yaml
+
+  # Multi-document YAML; documents with a "$etu: {context: ...}" header
+  # are applied to that context
+  etu apply -f environments.yaml`,
 		RunE: runApply,
 	}
 )
@@ -45,7 +57,11 @@ func init() {
 	applyCmd.Flags().StringVarP(&applyOpts.FilePath, "file", "f", "",
 		"path to configuration file or '-' for stdin (required)")
 	applyCmd.Flags().StringVar((*string)(&applyOpts.Format), "format", "",
-		"file format: auto, etcdctl (overrides config)")
+		"file format: auto, etcdctl, yaml, json, toml, dotenv, properties (overrides config)")
+	applyCmd.Flags().StringVar(&applyOpts.Prefix, "prefix", "",
+		"mount the file's keys under this prefix")
+	applyCmd.Flags().StringVar(&applyOpts.KeyMap, "key-map", "",
+		"YAML file of rules that rewrite keys before validation")
 	applyCmd.Flags().BoolVar(&applyOpts.DryRun, "dry-run", false,
 		"preview changes without applying to etcd")
 	applyCmd.Flags().BoolVar(&applyOpts.NoValidate, "no-validate", false,
@@ -53,11 +69,16 @@ func init() {
 	applyCmd.Flags().BoolVar(&applyOpts.Strict, "strict", false,
 		"treat validation warnings as errors (overrides config)")
 
+	addFlattenFlags(applyCmd)
+	addTemplateFlags(applyCmd)
+
 	if err := applyCmd.MarkFlagRequired("file"); err != nil {
 		panic(fmt.Sprintf("failed to mark flag as required: %v", err))
 	}
 
 	registerFileCompletion(applyCmd, "file")
+	registerFileCompletion(applyCmd, "key-map")
+	registerPrefixCompletion(applyCmd, "prefix")
 }
 
 func runApply(cmd *cobra.Command, _ []string) error {
@@ -77,17 +98,16 @@ func runApply(cmd *cobra.Command, _ []string) error {
 	noValidate := resolveNoValidateOption(applyOpts.NoValidate, cmd.Flags().Changed("no-validate"), appCfg)
 	strict := resolveStrictOption(applyOpts.Strict, cmd.Flags().Changed("strict"), appCfg)
 
-	filePath := applyOpts.FilePath
-	if filePath == "-" {
-		tmpPath, err := stdinToTempFile()
-		if err != nil {
-			return err
-		}
-		defer os.Remove(tmpPath)
-		filePath = tmpPath
+	pairs, err := parseConfigFile(ctx, applyOpts.FilePath, applyOpts.Format, appCfg)
+	if err != nil {
+		return err
+	}
+	if err := rewriteKeys(pairs, applyOpts.KeyMap, applyOpts.Prefix); err != nil {
+		return err
 	}
 
-	pairs, err := parseConfigFile(ctx, filePath, applyOpts.Format, appCfg)
+	// YAML documents may target other contexts than the selected one
+	groups, err := resolveContextGroups(pairs)
 	if err != nil {
 		return err
 	}
@@ -96,7 +116,7 @@ func runApply(cmd *cobra.Command, _ []string) error {
 	if !noValidate {
 		logVerboseInfo("Validating configuration")
 
-		v := validator.NewValidator(strict)
+		v := validator.NewValidatorWithLayout(strict, flattenOpts.Layout)
 		result := v.Validate(pairs)
 
 		if !isQuietOutput() {
@@ -115,22 +135,57 @@ func runApply(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
-	var cfg *client.Config
+	// Record value types next to the keys so export can restore them
+	if flattenOpts.PreserveTypes {
+		for i := range groups {
+			sidecars := parsers.TypeSidecars(groups[i].pairs)
+			groups[i].pairs = append(groups[i].pairs, sidecars...)
+			pairs = append(pairs, sidecars...)
+		}
+	}
+
+	configs := make([]*client.Config, len(groups))
 	if !applyOpts.DryRun {
-		var cfgErr error
-		cfg, cfgErr = config.GetEtcdConfigWithContext(contextName)
-		if cfgErr != nil {
-			return wrapNotConnectedError(cfgErr)
+		// Resolve every context before writing anything
+		for i, group := range groups {
+			cfg, err := config.GetEtcdConfigWithContext(group.context)
+			if err != nil {
+				return wrapNotConnectedError(err)
+			}
+			configs[i] = cfg
 		}
 	}
 
+	var dryRunOps []client.Operation
+	for i, group := range groups {
+		ops, err := applyPairs(ctx, group, configs[i])
+		if err != nil {
+			return err
+		}
+		dryRunOps = append(dryRunOps, ops...)
+	}
+
+	if applyOpts.DryRun {
+		return output.PrintDryRunOperations(dryRunViewOps(dryRunOps), outputFormat)
+	}
+
+	return output.PrintApplyResultsWithFormat(pairs, outputFormat, applyOpts.DryRun)
+}
+
+// applyPairs writes one context group to etcd, or records its operations
+// when running dry.
+func applyPairs(ctx context.Context, group contextGroup, cfg *client.Config) ([]client.Operation, error) {
 	etcdClient, cleanup, err := newEtcdClientOrDryRun(applyOpts.DryRun, cfg)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer cleanup()
 
-	logVerboseInfo(fmt.Sprintf("Applying %d items to etcd", len(pairs)))
+	if group.context != "" {
+		logVerboseInfo(fmt.Sprintf("Applying %d items to etcd context %s", len(group.pairs), group.context))
+	} else {
+		logVerboseInfo(fmt.Sprintf("Applying %d items to etcd", len(group.pairs)))
+	}
 
 	var onProgress client.ProgressFunc
 	if outputFormat == output.FormatSimple.String() && !applyOpts.DryRun {
@@ -139,27 +194,48 @@ func runApply(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
-	result, err := etcdClient.PutAllWithProgress(ctx, pairs, onProgress)
+	result, err := etcdClient.PutAllWithProgress(ctx, group.pairs, onProgress)
 	if err != nil {
 		if result != nil && result.Succeeded > 0 {
 			output.Warning(fmt.Sprintf("Partial failure: %d/%d items applied before error",
 				result.Succeeded, result.Total))
 		}
-		return wrapContextError(fmt.Errorf("failed to apply configuration: %w", err))
+		return nil, wrapContextError(fmt.Errorf("failed to apply configuration: %w", err))
 	}
 
-	if recorder, ok := etcdClient.(client.OperationRecorder); ok {
-		ops := recorder.Operations()
-		viewOps := make([]output.DryRunOperation, len(ops))
-		for i, op := range ops {
-			viewOps[i] = output.DryRunOperation{
-				Type:  op.Type,
-				Key:   op.Key,
-				Value: op.Value,
-			}
+	if !applyOpts.DryRun {
+		if err := removeStaleTypes(ctx, etcdClient, group.pairs); err != nil {
+			return nil, wrapContextError(fmt.Errorf("failed to remove stale value types: %w", err))
 		}
-		return output.PrintDryRunOperations(viewOps, outputFormat)
 	}
 
-	return output.PrintApplyResultsWithFormat(pairs, outputFormat, applyOpts.DryRun)
+	if recorder, ok := etcdClient.(client.OperationRecorder); ok {
+		return recorder.Operations(), nil
+	}
+	return nil, nil
+}
+
+// removeStaleTypes deletes the type sidecars of the keys in pairs that were
+// written without recording a type, so that their old type is not restored.
+func removeStaleTypes(ctx context.Context, etcdClient client.EtcdClient, pairs []*models.ConfigPair) error {
+	kvs, _, err := client.GetAllWithPrefix(ctx, etcdClient, parsers.TypesPrefix+"/", &client.PageOptions{KeysOnly: true})
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(kvs))
+	for _, kv := range kvs {
+		existing[kv.Key] = true
+	}
+
+	var units []txnUnit
+	for _, pair := range pairs {
+		if parsers.IsTypeKey(pair.Key) || (flattenOpts.PreserveTypes && pair.Type != "") {
+			continue
+		}
+		if key := parsers.TypeKey(pair.Key); existing[key] {
+			units = append(units, txnUnit{ops: []client.TxnOp{{Type: client.TxnOpDelete, Key: key}}})
+		}
+	}
+	_, _, err = commitUnits(ctx, etcdClient, units)
+	return err
 }
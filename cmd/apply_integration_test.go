@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/parsers"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -241,4 +242,49 @@ value
 		require.NoError(t, err)
 		assert.Equal(t, "value", value)
 	})
+
+	t.Run("Apply without preserve-types drops recorded types", func(t *testing.T) {
+		tempDir := setupTestContext(t, endpoint)
+		configFile := filepath.Join(tempDir, "typed.json")
+		require.NoError(t, os.WriteFile(configFile, []byte(`{"typed": {"port": 8080}}`), 0644))
+
+		oldHome := os.Getenv("HOME")
+		os.Setenv("HOME", tempDir)
+		defer os.Setenv("HOME", oldHome)
+		defer func() { flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON} }()
+
+		applyOpts.FilePath = configFile
+		applyOpts.Format = "json"
+		applyOpts.DryRun = false
+		applyOpts.NoValidate = false
+		applyOpts.Strict = false
+
+		cfg := &client.Config{
+			Endpoints:   []string{endpoint},
+			DialTimeout: 5 * time.Second,
+		}
+		etcdClient, err := client.NewClient(cfg)
+		require.NoError(t, err)
+		defer etcdClient.Close()
+		ctx := context.Background()
+
+		flattenOpts.PreserveTypes = true
+		require.NoError(t, runApply(applyCmd, []string{}))
+		resp, err := etcdClient.GetWithOptions(ctx, parsers.TypeKey("/typed/port"), nil)
+		require.NoError(t, err)
+		require.Len(t, resp.Kvs, 1)
+
+		// Sidecars live outside "/"
+		resp, err = etcdClient.GetWithOptions(ctx, "/", &client.GetOptions{Prefix: true, KeysOnly: true})
+		require.NoError(t, err)
+		for _, kv := range resp.Kvs {
+			assert.False(t, parsers.IsTypeKey(kv.Key), kv.Key)
+		}
+
+		flattenOpts.PreserveTypes = false
+		require.NoError(t, runApply(applyCmd, []string{}))
+		resp, err = etcdClient.GetWithOptions(ctx, parsers.TypeKey("/typed/port"), nil)
+		require.NoError(t, err)
+		assert.Empty(t, resp.Kvs)
+	})
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -8,7 +9,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/kazuma-desu/etu/pkg/client"
 	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/parsers"
 )
 
 func TestApplyCommand_Stdin(t *testing.T) {
@@ -44,6 +47,21 @@ value2
 			format:      models.FormatJSON,
 			expectError: false,
 		},
+		{
+			name: "multi-document YAML with contexts from stdin",
+			content: `$etu:
+  context: staging
+test:
+  key1: value1
+---
+$etu:
+  context: production
+test:
+  key1: value1
+`,
+			format:      models.FormatYAML,
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -79,6 +97,56 @@ value2
 	}
 }
 
+func TestApplyCommand_UnknownDocumentContext(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+	resetApplyFlags()
+	defer resetApplyFlags()
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "$etu:\n  context: nowhere\ntest:\n  key1: value1\n"
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	applyOpts.FilePath = file
+
+	err := runApply(applyCmd, []string{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nowhere")
+}
+
+func TestRemoveStaleTypes(t *testing.T) {
+	t.Cleanup(func() { flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON} })
+
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return &client.GetResponse{Kvs: []*client.KeyValue{
+				{Key: ".etu/types/app/port"},
+				{Key: ".etu/types/app/name"},
+			}}, nil
+		},
+	}
+	pairs := []*models.ConfigPair{
+		{Key: "/app/port", Value: "8080", Type: models.TypeInt},
+		{Key: "/app/name", Value: "api"},
+		{Key: "/app/new", Value: "x"},
+	}
+
+	// Without --preserve-types every written key loses its recorded type
+	require.NoError(t, removeStaleTypes(context.Background(), mock, pairs))
+	require.Len(t, mock.TxnCalls, 1)
+	assert.Equal(t, []client.TxnOp{
+		{Type: client.TxnOpDelete, Key: ".etu/types/app/port"},
+		{Type: client.TxnOpDelete, Key: ".etu/types/app/name"},
+	}, mock.TxnCalls[0].Ops)
+	assert.Equal(t, parsers.TypesPrefix+"/", mock.GetWithOptionsCalls[0].Key)
+
+	// With it, only keys without a type do
+	flattenOpts.PreserveTypes = true
+	mock.TxnCalls = nil
+	require.NoError(t, removeStaleTypes(context.Background(), mock, append(pairs, parsers.TypeSidecars(pairs)...)))
+	require.Len(t, mock.TxnCalls, 1)
+	assert.Equal(t, []client.TxnOp{{Type: client.TxnOpDelete, Key: ".etu/types/app/name"}}, mock.TxnCalls[0].Ops)
+}
+
 func resetApplyFlags() {
 	applyOpts.FilePath = ""
 	applyOpts.Format = ""
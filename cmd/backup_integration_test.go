@@ -0,0 +1,106 @@
+//go:build integration
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRestore_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	tempDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	defer os.Setenv("HOME", oldHome)
+
+	fullEndpoint := setupEtcdContainerForCmd(t)
+	ctx := context.Background()
+
+	etcdClient, err := client.NewClient(&client.Config{Endpoints: []string{fullEndpoint}})
+	require.NoError(t, err)
+	defer etcdClient.Close()
+
+	appCfg := &config.Config{
+		Contexts: map[string]*config.ContextConfig{
+			"test": {Endpoints: []string{fullEndpoint}},
+		},
+		CurrentContext: "test",
+	}
+	require.NoError(t, config.SaveConfig(appCfg))
+
+	testData := map[string]string{
+		"/backup/app/a":     "1",
+		"/backup/app/empty": "",
+		"/backup/app/b/c":   "nested",
+	}
+	for k, v := range testData {
+		require.NoError(t, etcdClient.Put(ctx, k, v))
+	}
+
+	archive := filepath.Join(tempDir, "app.etub")
+	originalBackupOpts, originalRestoreOpts := backupOpts, restoreOpts
+	t.Cleanup(func() {
+		backupOpts, restoreOpts = originalBackupOpts, originalRestoreOpts
+	})
+
+	backupOpts.prefix = "/backup/app"
+	backupOpts.file = archive
+	_, err = testutil.CaptureStdout(func() error {
+		return runBackup(backupCmd, nil)
+	})
+	require.NoError(t, err)
+
+	t.Run("restore under new prefix", func(t *testing.T) {
+		restoreOpts.file = archive
+		restoreOpts.toPrefix = "/restored/app"
+		restoreOpts.force = true
+
+		_, err := testutil.CaptureStdout(func() error {
+			return runRestore(restoreCmd, nil)
+		})
+		require.NoError(t, err)
+
+		resp, err := etcdClient.GetWithOptions(ctx, "/restored/app", &client.GetOptions{Prefix: true})
+		require.NoError(t, err)
+
+		got := make(map[string]string)
+		for _, kv := range resp.Kvs {
+			got[kv.Key] = kv.Value
+		}
+		assert.Equal(t, map[string]string{
+			"/restored/app/a":     "1",
+			"/restored/app/empty": "",
+			"/restored/app/b/c":   "nested",
+		}, got)
+	})
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		restoreOpts = originalRestoreOpts
+		restoreOpts.file = archive
+		restoreOpts.toPrefix = "/dryrun/app"
+		restoreOpts.dryRun = true
+
+		output, err := testutil.CaptureStdout(func() error {
+			return runRestore(restoreCmd, nil)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, output, "/dryrun/app/a")
+
+		resp, err := etcdClient.GetWithOptions(ctx, "/dryrun/", &client.GetOptions{Prefix: true, CountOnly: true})
+		require.NoError(t, err)
+		assert.Zero(t, resp.Count)
+	})
+}
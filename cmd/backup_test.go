@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/backup"
+	"github.com/kazuma-desu/etu/pkg/client"
+)
+
+func TestCollectBackup(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return &client.GetResponse{
+				Revision: 99,
+				Kvs: []*client.KeyValue{
+					{Key: "/app/a", Value: "1", CreateRevision: 3, ModRevision: 8, Version: 2},
+					{Key: "/app/b", Value: "", CreateRevision: 4, ModRevision: 4, Version: 1, Lease: 55},
+				},
+			}, nil
+		},
+		StatusFunc: func(_ context.Context, _ string) (*client.StatusResponse, error) {
+			return &client.StatusResponse{ClusterID: 0xabc}, nil
+		},
+	}
+
+	header, entries, err := collectBackup(context.Background(), mock, "/app/", []string{"localhost:2379"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/app/", header.Prefix)
+	assert.Equal(t, int64(99), header.Revision)
+	assert.Equal(t, uint64(0xabc), header.ClusterID)
+	assert.Equal(t, []string{"localhost:2379"}, header.Endpoints)
+	assert.False(t, header.CreatedAt.IsZero())
+
+	assert.Equal(t, []backup.Entry{
+		{Key: "/app/a", Value: "1", CreateRevision: 3, ModRevision: 8, Version: 2},
+		{Key: "/app/b", Value: "", CreateRevision: 4, ModRevision: 4, Version: 1, Lease: 55},
+	}, entries)
+	assert.Equal(t, []string{"localhost:2379"}, mock.StatusCalls)
+}
+
+func TestCollectBackup_StatusFailureIsNotFatal(t *testing.T) {
+	mock := &client.MockClient{
+		StatusFunc: func(_ context.Context, _ string) (*client.StatusResponse, error) {
+			return nil, errors.New("status unavailable")
+		},
+	}
+
+	header, entries, err := collectBackup(context.Background(), mock, "/app/", []string{"localhost:2379"})
+	require.NoError(t, err)
+	assert.Zero(t, header.ClusterID)
+	assert.Empty(t, entries)
+}
+
+func TestCollectBackup_ReadError(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return nil, errors.New("connection lost")
+		},
+	}
+
+	_, _, err := collectBackup(context.Background(), mock, "/app/", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read keys")
+}
@@ -1,12 +1,30 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/kazuma-desu/etu/pkg/client"
 	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/glob"
+)
+
+const (
+	// completionTimeout bounds the etcd request behind one key completion,
+	// so a slow or unreachable cluster never hangs the shell.
+	completionTimeout = 2 * time.Second
+
+	// completionCacheTTL is how long listed children are reused. Each TAB
+	// starts a new process, so the cache lives on disk, one file per context.
+	completionCacheTTL = 30 * time.Second
 )
 
 var completionCmd = &cobra.Command{
@@ -14,6 +32,11 @@ var completionCmd = &cobra.Command{
 	Short: "Generate shell completion scripts",
 	Long: `Generate shell completion scripts for etu.
 
+Keys are completed by querying etcd one path segment at a time, e.g.
+'etu get /app/<TAB>'. Lookups are limited to 2 seconds and cached per context
+for 30 seconds. --context and other context flags complete the names from the
+config file.
+
 To load completions:
 
 Bash:
@@ -51,7 +74,13 @@ PowerShell:
 func init() {
 	rootCmd.AddCommand(completionCmd)
 
-	if err := rootCmd.RegisterFlagCompletionFunc("context", completeContextNames); err != nil {
+	registerContextCompletion(rootCmd, "context")
+}
+
+// registerContextCompletion completes the value of flagName with the
+// context names from the config file.
+func registerContextCompletion(cmd *cobra.Command, flagName string) {
+	if err := cmd.RegisterFlagCompletionFunc(flagName, completeContextNames); err != nil {
 		_ = err // best-effort
 	}
 }
@@ -62,6 +91,157 @@ func registerFileCompletion(cmd *cobra.Command, flagName string) {
 	}
 }
 
+// registerKeyCompletion completes the first maxArgs arguments of cmd as
+// keys. A negative maxArgs completes every argument.
+func registerKeyCompletion(cmd *cobra.Command, maxArgs int) {
+	cmd.ValidArgsFunction = func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if maxArgs >= 0 && len(args) >= maxArgs {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeKeys(toComplete)
+	}
+}
+
+// registerPrefixCompletion completes the value of a prefix flag as a key.
+func registerPrefixCompletion(cmd *cobra.Command, flagName string) {
+	complete := func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeKeys(toComplete)
+	}
+	if err := cmd.RegisterFlagCompletionFunc(flagName, complete); err != nil {
+		_ = err // best-effort
+	}
+}
+
+func completeKeys(toComplete string) ([]string, cobra.ShellCompDirective) {
+	cache := loadCompletionCache(resolveContextName())
+	return keyCompletions(toComplete, cache, func() (client.EtcdReader, func(), error) {
+		cfg, err := config.GetEtcdConfigWithContext(contextName)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.DialTimeout = completionTimeout
+		return newEtcdClient(cfg)
+	})
+}
+
+// keyCompletions completes toComplete one path segment at a time: it lists
+// the children of the directory typed so far and returns those that extend
+// it, directories with a trailing '/'. Listings come from cache when fresh;
+// connect is only called on a miss.
+func keyCompletions(toComplete string, cache *completionCache, connect func() (client.EtcdReader, func(), error)) ([]string, cobra.ShellCompDirective) {
+	if toComplete == "" {
+		toComplete = "/"
+	}
+	if !strings.HasPrefix(toComplete, "/") || glob.IsPattern(toComplete) {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	dir := toComplete[:strings.LastIndex(toComplete, "/")+1]
+
+	paths, ok := cache.lookup(dir, time.Now())
+	if !ok {
+		reader, cleanup, err := connect()
+		if err != nil {
+			cobra.CompDebugln("key completion: "+err.Error(), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		defer cleanup()
+
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+		children, _, err := client.ReadChildren(ctx, reader, dir, &client.PageOptions{KeysOnly: true})
+		if err != nil {
+			cobra.CompDebugln("key completion: "+err.Error(), false)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		paths = make([]string, len(children))
+		for i, child := range children {
+			paths[i] = child.Path(dir)
+		}
+		cache.store(dir, paths, time.Now())
+	}
+
+	directive := cobra.ShellCompDirectiveNoFileComp
+	var matches []string
+	for _, p := range paths {
+		if !strings.HasPrefix(p, toComplete) {
+			continue
+		}
+		matches = append(matches, p)
+		if strings.HasSuffix(p, "/") {
+			// Let the user keep typing into the directory
+			directive |= cobra.ShellCompDirectiveNoSpace
+		}
+	}
+	return matches, directive
+}
+
+// completionCache holds recent directory listings of one context.
+type completionCache struct {
+	path string
+	Dirs map[string]completionEntry `json:"dirs"`
+}
+
+type completionEntry struct {
+	Listed time.Time `json:"listed"`
+	Paths  []string  `json:"paths"`
+}
+
+// loadCompletionCache reads the cache of contextName. A missing or
+// unreadable cache is empty, and with no cache directory nothing is saved.
+func loadCompletionCache(contextName string) *completionCache {
+	cache := &completionCache{Dirs: map[string]completionEntry{}}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return cache
+	}
+	if contextName == "" {
+		contextName = "default"
+	}
+	cache.path = filepath.Join(dir, "etu", "completion", url.PathEscape(contextName)+".json")
+
+	data, err := os.ReadFile(cache.path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, cache); err != nil || cache.Dirs == nil {
+		cache.Dirs = map[string]completionEntry{}
+	}
+	return cache
+}
+
+func (c *completionCache) lookup(dir string, now time.Time) ([]string, bool) {
+	entry, ok := c.Dirs[dir]
+	if !ok || now.Sub(entry.Listed) > completionCacheTTL {
+		return nil, false
+	}
+	return entry.Paths, true
+}
+
+// store records a listing and saves the cache, dropping expired entries.
+// Saving is best-effort: completion works without it.
+func (c *completionCache) store(dir string, paths []string, now time.Time) {
+	for d, entry := range c.Dirs {
+		if now.Sub(entry.Listed) > completionCacheTTL {
+			delete(c.Dirs, d)
+		}
+	}
+	c.Dirs[dir] = completionEntry{Listed: now, Paths: paths}
+
+	if c.path == "" {
+		return
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(c.path, data, 0o600)
+}
+
 func completeConfigFiles(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 	return []string{"txt", "yaml", "yml", "json"}, cobra.ShellCompDirectiveFilterFileExt
 }
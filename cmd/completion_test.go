@@ -1,13 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/kazuma-desu/etu/pkg/client"
 	"github.com/kazuma-desu/etu/pkg/config"
 	"github.com/kazuma-desu/etu/pkg/testutil"
 )
@@ -195,3 +199,146 @@ func TestCompleteContextNames_LoadError(t *testing.T) {
 	assert.Nil(t, contexts)
 	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
 }
+
+// countingConnect returns a connect func for keyCompletions that counts how
+// often it is called.
+func countingConnect(reader client.EtcdReader, calls *int) func() (client.EtcdReader, func(), error) {
+	return func() (client.EtcdReader, func(), error) {
+		*calls++
+		return reader, func() {}, nil
+	}
+}
+
+func TestKeyCompletions(t *testing.T) {
+	mock := treeMock("/app/config/db", "/app/config/port", "/app/name", "/apple", "/other/x")
+
+	tests := []struct {
+		name       string
+		toComplete string
+		want       []string
+		directive  cobra.ShellCompDirective
+	}{
+		{"empty lists the top level", "", []string{"/app/", "/apple", "/other/"}, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace},
+		{"partial segment", "/ap", []string{"/app/", "/apple"}, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace},
+		{"next segment", "/app/", []string{"/app/config/", "/app/name"}, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace},
+		{"only keys", "/app/config/p", []string{"/app/config/port"}, cobra.ShellCompDirectiveNoFileComp},
+		{"no match", "/zzz", nil, cobra.ShellCompDirectiveNoFileComp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			got, directive := keyCompletions(tt.toComplete, &completionCache{Dirs: map[string]completionEntry{}}, countingConnect(mock, &calls))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.directive, directive)
+			assert.Equal(t, 1, calls)
+		})
+	}
+}
+
+func TestKeyCompletions_SkipsInvalidInput(t *testing.T) {
+	calls := 0
+	connect := countingConnect(&client.MockClient{}, &calls)
+	cache := &completionCache{Dirs: map[string]completionEntry{}}
+
+	got, directive := keyCompletions("app", cache, connect)
+	assert.Nil(t, got)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	got, _ = keyCompletions("/app/*/d", cache, connect)
+	assert.Nil(t, got)
+	assert.Zero(t, calls, "no request is made for relative keys or globs")
+}
+
+func TestKeyCompletions_UsesCache(t *testing.T) {
+	mock := treeMock("/app/a", "/app/b")
+	cache := &completionCache{Dirs: map[string]completionEntry{}}
+	calls := 0
+
+	first, _ := keyCompletions("/app/", cache, countingConnect(mock, &calls))
+	second, _ := keyCompletions("/app/b", cache, countingConnect(mock, &calls))
+
+	assert.Equal(t, []string{"/app/a", "/app/b"}, first)
+	assert.Equal(t, []string{"/app/b"}, second)
+	assert.Equal(t, 1, calls, "the second completion in the same directory is served from cache")
+
+	// An expired listing is read again
+	entry := cache.Dirs["/app/"]
+	entry.Listed = entry.Listed.Add(-2 * completionCacheTTL)
+	cache.Dirs["/app/"] = entry
+	keyCompletions("/app/", cache, countingConnect(mock, &calls))
+	assert.Equal(t, 2, calls)
+}
+
+func TestKeyCompletions_Errors(t *testing.T) {
+	cache := &completionCache{Dirs: map[string]completionEntry{}}
+
+	got, directive := keyCompletions("/app/", cache, func() (client.EtcdReader, func(), error) {
+		return nil, nil, errors.New("no current context set")
+	})
+	assert.Nil(t, got)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	failing := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	calls := 0
+	got, _ = keyCompletions("/app/", cache, countingConnect(failing, &calls))
+	assert.Nil(t, got)
+	assert.Empty(t, cache.Dirs, "failed listings are not cached")
+}
+
+func TestCompletionCache_SavesPerContext(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	now := time.Now()
+
+	dev := loadCompletionCache("dev")
+	dev.store("/app/", []string{"/app/a"}, now)
+
+	reloaded := loadCompletionCache("dev")
+	paths, ok := reloaded.lookup("/app/", now)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"/app/a"}, paths)
+
+	_, ok = loadCompletionCache("prod").lookup("/app/", now)
+	assert.False(t, ok, "contexts do not share listings")
+
+	_, ok = reloaded.lookup("/app/", now.Add(completionCacheTTL+time.Second))
+	assert.False(t, ok)
+}
+
+func TestCompletionCache_DropsExpiredEntries(t *testing.T) {
+	now := time.Now()
+	cache := &completionCache{Dirs: map[string]completionEntry{
+		"/old/": {Listed: now.Add(-time.Hour), Paths: []string{"/old/a"}},
+	}}
+
+	cache.store("/new/", []string{"/new/a"}, now)
+
+	assert.NotContains(t, cache.Dirs, "/old/")
+	assert.Contains(t, cache.Dirs, "/new/")
+}
+
+func TestRegisterKeyCompletion(t *testing.T) {
+	testCmd := &cobra.Command{Use: "test"}
+	registerKeyCompletion(testCmd, 1)
+	require.NotNil(t, testCmd.ValidArgsFunction)
+
+	// Past the key arguments nothing is completed and etcd is not contacted
+	got, directive := testCmd.ValidArgsFunction(testCmd, []string{"/app/a"}, "")
+	assert.Nil(t, got)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
+
+func TestKeyCompletionsRegistered(t *testing.T) {
+	for _, c := range []*cobra.Command{getCmd, putCmd, deleteCmd, editCmd, watchCmd, lsCmd} {
+		assert.NotNil(t, c.ValidArgsFunction, c.Name())
+	}
+
+	_, ok := diffCmd.GetFlagCompletionFunc("prefix")
+	assert.True(t, ok)
+	_, ok = mirrorCmd.GetFlagCompletionFunc("from")
+	assert.True(t, ok)
+}
@@ -180,9 +180,9 @@ func runSetConfig(_ *cobra.Command, args []string) error {
 		cfg.LogLevel = value
 	case "default-format":
 		// Validate format
-		validFormats := []string{"auto", "etcdctl", "yaml", "json"}
+		validFormats := []string{"auto", "etcdctl", "yaml", "json", "toml", "dotenv", "properties"}
 		if !slices.Contains(validFormats, value) {
-			return fmt.Errorf("✗ invalid format %s, valid: auto, etcdctl, yaml, json", value)
+			return fmt.Errorf("✗ invalid format %s, valid: auto, etcdctl, yaml, json, toml, dotenv, properties", value)
 		}
 		cfg.DefaultFormat = value
 	case "strict":
@@ -2,8 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"io"
-	"os"
 
 	"github.com/spf13/cobra"
 
@@ -21,7 +19,7 @@ var (
 	convertCmd = &cobra.Command{
 		Use:   "convert",
 		Short: "Convert configuration files to YAML",
-		Long:  `Convert configuration files from various formats (etcdctl, JSON, YAML) to hierarchical YAML.`,
+		Long:  `Convert configuration files from various formats (etcdctl, JSON, YAML, TOML, .env, .properties) to hierarchical YAML.`,
 		Example: `  # Convert etcdctl dump to YAML
   etu convert -f dump.txt > config.yaml
 
@@ -29,7 +27,10 @@ var (
   cat dump.txt | etu convert -f -
   
   # Convert with explicit format
-  etu convert -f data.json --format json`,
+  etu convert -f data.json --format json
+
+  # Keep "8080" a string and 8080 a number
+  etu convert -f data.json --preserve-types`,
 		RunE: runConvert,
 	}
 )
@@ -40,7 +41,9 @@ func init() {
 	convertCmd.Flags().StringVarP(&convertOpts.FilePath, "file", "f", "",
 		"path to configuration file (supports stdin via '-')")
 	convertCmd.Flags().StringVar(&convertOpts.Format, "format", "",
-		"input format: auto, etcdctl, json, yaml")
+		"input format: auto, etcdctl, json, yaml, toml, dotenv, properties")
+	addFlattenFlags(convertCmd)
+	addTemplateFlags(convertCmd)
 }
 
 func runConvert(_ *cobra.Command, _ []string) error {
@@ -50,21 +53,8 @@ func runConvert(_ *cobra.Command, _ []string) error {
 	appCfg := loadAppConfig()
 
 	filePath := convertOpts.FilePath
-	if filePath == "-" || (filePath == "" && hasStdinData()) {
-		tmpFile, err := os.CreateTemp("", "etu-convert-*")
-		if err != nil {
-			return fmt.Errorf("failed to create temp file: %w", err)
-		}
-		defer os.Remove(tmpFile.Name())
-
-		if _, err := io.Copy(tmpFile, os.Stdin); err != nil {
-			tmpFile.Close()
-			return fmt.Errorf("failed to write stdin to temp file: %w", err)
-		}
-		if err := tmpFile.Close(); err != nil {
-			return fmt.Errorf("failed to close temp file: %w", err)
-		}
-		filePath = tmpFile.Name()
+	if filePath == "" && hasStdinData() {
+		filePath = "-"
 	}
 
 	if filePath == "" {
@@ -76,7 +66,24 @@ func runConvert(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	data, err := parsers.UnflattenMap(pairs)
+	// The output is a single tree, so documents may not target different
+	// contexts
+	groups, err := resolveContextGroups(pairs)
+	if err != nil {
+		return err
+	}
+	if len(groups) > 1 {
+		return fmt.Errorf("✗ input targets contexts %q and %q; convert one context at a time", groups[0].context, groups[1].context)
+	}
+
+	// Write arrays back the way they were read. The csv mode tracks types
+	// to tell lists from strings.
+	data, err := parsers.UnflattenMapWithOptions(pairs, &parsers.UnflattenOptions{
+		KeepEmpty: flattenOpts.KeepEmptyArrays || flattenOpts.KeepNulls,
+		Arrays:    flattenOpts.Arrays,
+		Types:     flattenOpts.PreserveTypes || flattenOpts.Arrays == parsers.ArrayCSV,
+		Layout:    flattenOpts.Layout,
+	})
 	if err != nil {
 		return err
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/kazuma-desu/etu/pkg/parsers"
 	"github.com/kazuma-desu/etu/pkg/testutil"
 )
 
@@ -41,6 +42,45 @@ value
 		assert.Contains(t, output, "key: value")
 	})
 
+	t.Run("TOML file to YAML", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "service.toml")
+		content := `[database]
+host = "db"
+port = 5432
+`
+		require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+		convertOpts.FilePath = testFile
+		convertOpts.Format = ""
+
+		output, err := testutil.CaptureStdout(func() error {
+			return runConvert(nil, nil)
+		})
+
+		require.NoError(t, err)
+		assert.Contains(t, output, "database:")
+		assert.Contains(t, output, "host: db")
+		assert.Contains(t, output, "port: 5432")
+	})
+
+	t.Run("Dotenv file to YAML", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), ".env")
+		content := "export APP_DB_HOST=db\nAPP_DB_PORT=5432\n"
+		require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+		convertOpts.FilePath = testFile
+		convertOpts.Format = ""
+
+		output, err := testutil.CaptureStdout(func() error {
+			return runConvert(nil, nil)
+		})
+
+		require.NoError(t, err)
+		assert.Contains(t, output, "app:")
+		assert.Contains(t, output, "host: db")
+		assert.Contains(t, output, "port: 5432")
+	})
+
 	t.Run("No input file or stdin", func(t *testing.T) {
 		convertOpts.FilePath = ""
 		convertOpts.Format = ""
@@ -79,3 +119,108 @@ value2
 		assert.Contains(t, err.Error(), "key collision")
 	})
 }
+
+func TestConvertCommand_PreserveTypes(t *testing.T) {
+	t.Cleanup(resetConvertOpts)
+	t.Cleanup(func() { flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON} })
+
+	testFile := filepath.Join(t.TempDir(), "app.json")
+	content := `{"app": {"port": 8080, "name": "8080", "debug": true, "label": "true"}}`
+	require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+	convertOpts.FilePath = testFile
+
+	t.Run("types are lost by default", func(t *testing.T) {
+		flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON}
+		output, err := testutil.CaptureStdout(func() error {
+			return runConvert(nil, nil)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, output, "name: 8080\n")
+		assert.Contains(t, output, "label: true\n")
+	})
+
+	t.Run("preserve types", func(t *testing.T) {
+		flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON, PreserveTypes: true}
+		output, err := testutil.CaptureStdout(func() error {
+			return runConvert(nil, nil)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, output, `name: "8080"`)
+		assert.Contains(t, output, "port: 8080\n")
+		assert.Contains(t, output, "debug: true\n")
+		assert.Contains(t, output, `label: "true"`)
+	})
+}
+
+func TestConvertCommand_Contexts(t *testing.T) {
+	t.Cleanup(resetConvertOpts)
+	oldContext := contextName
+	t.Cleanup(func() { contextName = oldContext })
+	contextName = "dev"
+
+	testFile := filepath.Join(t.TempDir(), "app.yaml")
+	convertOpts.FilePath = testFile
+
+	t.Run("one context", func(t *testing.T) {
+		content := "db:\n  port: 5432\n---\n$etu:\n  context: dev\ndb:\n  host: dev-db\n"
+		require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+		output, err := testutil.CaptureStdout(func() error {
+			return runConvert(nil, nil)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, output, "host: dev-db")
+		assert.Contains(t, output, "port: 5432")
+	})
+
+	t.Run("several contexts are rejected", func(t *testing.T) {
+		content := "$etu:\n  context: prod\ndb:\n  host: prod-db\n---\n$etu:\n  context: staging\ndb:\n  host: staging-db\n"
+		require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+		err := runConvert(nil, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `input targets contexts "prod" and "staging"`)
+	})
+}
+
+func TestConvertCommand_CSVRoundTrip(t *testing.T) {
+	t.Cleanup(resetConvertOpts)
+	t.Cleanup(func() { flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON} })
+
+	testFile := filepath.Join(t.TempDir(), "app.yaml")
+	content := "list: [only]\nname: \"a,b\"\nn: null\ne: []\ntags: [a, \"b,c\"]\n"
+	require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+	convertOpts.FilePath = testFile
+	flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayCSV, KeepNulls: true, KeepEmptyArrays: true}
+
+	output, err := testutil.CaptureStdout(func() error {
+		return runConvert(nil, nil)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output, "list:\n    - only\n")
+	assert.Contains(t, output, `name: a,b`)
+	assert.Contains(t, output, "n: null\n")
+	assert.Contains(t, output, "e: []\n")
+	assert.Contains(t, output, "tags:\n    - a\n    - b,c\n")
+}
+
+func TestConvertCommand_PreserveTypesRoundTrip(t *testing.T) {
+	t.Cleanup(resetConvertOpts)
+	t.Cleanup(func() { flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON} })
+
+	testFile := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(testFile, []byte("a: []\nc: [1, 2]\n"), 0644))
+	convertOpts.FilePath = testFile
+
+	for _, mode := range []parsers.ArrayMode{parsers.ArrayJSON, parsers.ArrayIndexed, parsers.ArrayCSV} {
+		flattenOpts = parsers.FlattenOptions{Arrays: mode, KeepEmptyArrays: true, PreserveTypes: true}
+		output, err := testutil.CaptureStdout(func() error {
+			return runConvert(nil, nil)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, output, "a: []\n", "mode %s", mode)
+		assert.Contains(t, output, "c:\n    - 1\n    - 2\n", "mode %s", mode)
+	}
+}
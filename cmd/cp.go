@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	cpOpts relocateOptions
+
+	cpCmd = &cobra.Command{
+		Use:   "cp <src> <dst>",
+		Short: "Copy a key or a prefix to another location",
+		Long: `Copy a key, or all keys under a prefix with --prefix, to another location.
+
+The copy runs in a single transaction when it fits in one (up to 64 keys
+within a cluster, 128 across contexts) and in batches of transactions otherwise.
+Every transaction is guarded by the revisions read beforehand, so a concurrent
+write to a source or destination key aborts it.
+
+Existing destination keys are not replaced unless --overwrite is given. Use
+--to-context to copy into another context.`,
+		Example: `  # Copy a single key
+  etu cp /app/config/host /app/config/host.bak
+
+  # Copy a subtree
+  etu cp /app/v1 /app/v2 --prefix
+
+  # Copy a subtree to another context
+  etu cp /app /app --prefix --context prod --to-context staging
+
+  # Preview the writes
+  etu cp /app/v1 /app/v2 --prefix --dry-run`,
+		Args: cobra.ExactArgs(2),
+		RunE: runCp,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(cpCmd)
+	addRelocateFlags(cpCmd, &cpOpts, "copy")
+	registerKeyCompletion(cpCmd, 2)
+}
+
+func runCp(_ *cobra.Command, args []string) error {
+	return runRelocate(args[0], args[1], &cpOpts, false)
+}
@@ -1,17 +1,17 @@
 package cmd
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kazuma-desu/etu/pkg/client"
 	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/glob"
 	"github.com/kazuma-desu/etu/pkg/output"
 )
 
@@ -25,13 +25,20 @@ var (
 	deleteCmd = &cobra.Command{
 		Use:   "delete <key>",
 		Short: "Delete keys from etcd",
-		Long:  `Delete a single key or all keys with a prefix from etcd.`,
+		Long: `Delete a single key or all keys with a prefix from etcd.
+
+The key may be a glob pattern such as '/app/*/cache' or '/tmp/**/lock'. The
+matched keys are listed before asking for confirmation, and each key is only
+deleted if it has not changed since it was listed.`,
 		Example: `  # Delete single key
   etu delete /app/config/host
 
   # Delete all keys with prefix (requires confirmation)
   etu delete /app/config/ --prefix
 
+  # Delete keys matching a glob (lists the matches, then confirms)
+  etu delete '/app/*/cache/**'
+
   # Skip confirmation
   etu delete /app/config/ --prefix --force
 
@@ -51,6 +58,8 @@ func init() {
 		"skip confirmation prompt for prefix deletion")
 	deleteCmd.Flags().BoolVar(&deleteOpts.dryRun, "dry-run", false,
 		"preview what would be deleted without actually deleting")
+
+	registerKeyCompletion(deleteCmd, 1)
 }
 
 func runDelete(_ *cobra.Command, args []string) error {
@@ -63,6 +72,17 @@ func runDelete(_ *cobra.Command, args []string) error {
 		return err
 	}
 
+	pattern, err := compileKeyPattern(key)
+	if err != nil {
+		return err
+	}
+	if pattern != nil {
+		if deleteOpts.prefix {
+			return fmt.Errorf("✗ a glob pattern cannot be combined with --prefix")
+		}
+		return runDeleteGlob(ctx, pattern)
+	}
+
 	if deleteOpts.prefix {
 		return runDeletePrefix(ctx, key)
 	}
@@ -144,6 +164,79 @@ func runDeletePrefix(ctx context.Context, prefix string) error {
 	return nil
 }
 
+func runDeleteGlob(ctx context.Context, pattern *glob.Pattern) error {
+	cfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+
+	etcdClient, cleanup, err := newEtcdClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	kvs, err := fetchKeysMatching(ctx, etcdClient, pattern)
+	if err != nil {
+		return err
+	}
+
+	if len(kvs) == 0 {
+		output.Warning(fmt.Sprintf("No keys match pattern: %s", pattern))
+		return nil
+	}
+
+	keys := make([]string, len(kvs))
+	for i, kv := range kvs {
+		keys[i] = kv.Key
+	}
+
+	if deleteOpts.dryRun {
+		output.Info(fmt.Sprintf("Would delete %d keys matching %q:", len(keys), pattern))
+		for _, k := range keys {
+			fmt.Printf("  %s\n", k)
+		}
+		return nil
+	}
+
+	if !deleteOpts.force {
+		question := fmt.Sprintf("Delete all keys matching %q?", pattern)
+		if !confirmKeys(keys, question, os.Stdin, os.Stdout) {
+			output.Info("Deletion canceled")
+			return nil
+		}
+	}
+
+	deleted, err := deleteFoundKeys(ctx, etcdClient, kvs)
+	if err != nil {
+		if deleted > 0 {
+			output.Warning(fmt.Sprintf("Partial failure: %d/%d keys deleted before error", deleted, len(kvs)))
+		}
+		if errors.Is(err, client.ErrTxnConflict) {
+			return fmt.Errorf("✗ a matched key was modified after it was listed; run the command again")
+		}
+		return wrapContextError(fmt.Errorf("failed to delete keys: %w", err))
+	}
+
+	output.Success(fmt.Sprintf("Deleted %d keys matching: %s", deleted, pattern))
+	return nil
+}
+
+// fetchKeysMatching reads the pattern's literal prefix page by page without
+// values and returns the keys that match, with the mod revisions used to
+// guard deletes.
+func fetchKeysMatching(ctx context.Context, etcdClient client.EtcdClient, pattern *glob.Pattern) ([]*client.KeyValue, error) {
+	var matched []*client.KeyValue
+	_, err := client.ForEachPage(ctx, etcdClient, pattern.Prefix(), &client.PageOptions{KeysOnly: true}, func(kvs []*client.KeyValue) error {
+		matched = append(matched, filterByPattern(kvs, pattern)...)
+		return nil
+	})
+	if err != nil {
+		return nil, wrapContextError(fmt.Errorf("failed to fetch keys: %w", err))
+	}
+	return matched, nil
+}
+
 func fetchKeysWithPrefix(ctx context.Context, etcdClient client.EtcdClient, prefix string) ([]string, error) {
 	resp, err := etcdClient.GetWithOptions(ctx, prefix, &client.GetOptions{
 		Prefix:   true,
@@ -168,16 +261,16 @@ func printKeysToDelete(keys []string, prefix string) {
 }
 
 func confirmDeletion(keys []string, prefix string, in io.Reader, out io.Writer) bool {
+	return confirmKeys(keys, fmt.Sprintf("Delete all keys with prefix %q?", prefix), in, out)
+}
+
+// confirmKeys lists the keys about to be deleted and asks question.
+func confirmKeys(keys []string, question string, in io.Reader, out io.Writer) bool {
 	fmt.Fprintf(out, "The following %d keys will be deleted:\n", len(keys))
 	for _, k := range keys {
 		fmt.Fprintf(out, "  %s\n", k)
 	}
-	fmt.Fprintf(out, "\nDelete all keys with prefix %q? [y/N]: ", prefix)
+	fmt.Fprintf(out, "\n%s [y/N]: ", question)
 
-	scanner := bufio.NewScanner(in)
-	if scanner.Scan() {
-		response := strings.ToLower(strings.TrimSpace(scanner.Text()))
-		return response == "y" || response == "yes"
-	}
-	return false
+	return readConfirmation(in)
 }
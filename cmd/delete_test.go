@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/kazuma-desu/etu/pkg/client"
 	"github.com/kazuma-desu/etu/pkg/testutil"
 )
 
@@ -183,3 +186,52 @@ func TestPrintKeysToDelete(t *testing.T) {
 		assert.Contains(t, output, "Would delete 0 keys")
 	})
 }
+
+func TestConfirmKeys(t *testing.T) {
+	in := strings.NewReader("y\n")
+	out := &bytes.Buffer{}
+
+	result := confirmKeys([]string{"/app/a/cache", "/app/b/cache"}, `Delete all keys matching "/app/*/cache"?`, in, out)
+	assert.True(t, result)
+	assert.Contains(t, out.String(), "The following 2 keys will be deleted:")
+	assert.Contains(t, out.String(), "  /app/b/cache")
+	assert.Contains(t, out.String(), `Delete all keys matching "/app/*/cache"? [y/N]: `)
+}
+
+func TestFetchKeysMatching(t *testing.T) {
+	pages := [][]*client.KeyValue{
+		{{Key: "/app/a/cache", ModRevision: 3}, {Key: "/app/a/config", ModRevision: 4}},
+		{{Key: "/app/b/cache", ModRevision: 5}},
+	}
+	mock := client.NewMockClient()
+	mock.GetWithOptionsFunc = func(_ context.Context, key string, opts *client.GetOptions) (*client.GetResponse, error) {
+		assert.True(t, opts.KeysOnly)
+		assert.Positive(t, opts.Limit, "keys are read in pages")
+		page := len(mock.GetWithOptionsCalls) - 1
+		if page == 0 {
+			assert.Equal(t, "/app/", key)
+		} else {
+			assert.Equal(t, "/app/a/config\x00", key)
+		}
+		return &client.GetResponse{Kvs: pages[page], More: page == 0, Revision: 9}, nil
+	}
+
+	pattern, err := compileKeyPattern("/app/*/cache")
+	require.NoError(t, err)
+
+	kvs, err := fetchKeysMatching(context.Background(), mock, pattern)
+	require.NoError(t, err)
+	require.Len(t, kvs, 2)
+	assert.Equal(t, "/app/a/cache", kvs[0].Key)
+	assert.Equal(t, int64(5), kvs[1].ModRevision)
+	assert.Len(t, mock.GetWithOptionsCalls, 2)
+}
+
+func TestRunDelete_GlobWithPrefixFlag(t *testing.T) {
+	deleteOpts.prefix = true
+	defer func() { deleteOpts.prefix = false }()
+
+	err := runDelete(nil, []string{"/app/*/cache"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be combined with --prefix")
+}
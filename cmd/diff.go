@@ -18,9 +18,13 @@ var (
 		Format           string
 		DeprecatedFormat string
 		Prefix           string
+		Mount            string
+		KeyMap           string
 		FilePath         string
+		IgnoreKeys       []string
 		ShowUnchanged    bool
 		Full             bool
+		Semantic         bool
 	}
 
 	diffCmd = &cobra.Command{
@@ -29,7 +33,13 @@ var (
 		Long: `Compare a local configuration file with the current state in etcd.
 
 By default, only compares keys that exist in the input file (file-scoped diff).
-Use --full with --prefix to compare all keys under a prefix (server-scoped diff).`,
+Use --full with --prefix to compare all keys under a prefix (server-scoped diff).
+--prefix also accepts a glob pattern such as '/app/*/db' or '/svc/**/timeout'.
+
+--mount places the file's keys under a prefix and --key-map rewrites them
+before they are compared; --mount is what apply, validate and parse call
+--prefix. Here --prefix filters the comparison, so it must overlap the
+--mount prefix.`,
 		Example: `  # Compare only keys in file against etcd (default)
   etu diff -f config.txt
 
@@ -39,9 +49,21 @@ Use --full with --prefix to compare all keys under a prefix (server-scoped diff)
   # Full comparison: all keys under prefix (shows keys in etcd but not in file)
   etu diff -f config.txt --prefix /app/config --full
 
+  # Full comparison of the keys matching a glob
+  etu diff -f config.txt --prefix '/app/*/db/**' --full
+
+  # Compare a file deployed with 'etu apply --prefix /services/api'
+  etu diff -f service.yaml --mount /services/api --prefix /services/api --full
+
   # Include unchanged keys in output
   etu diff -f config.txt --show-unchanged
 
+  # Compare JSON/YAML values structurally (ignore key order and whitespace)
+  etu diff -f config.yaml --semantic
+
+  # Skip volatile keys such as timestamps
+  etu diff -f config.yaml --ignore-keys updated_at --ignore-keys '/app/*/build_time'
+
   # JSON output for scripting
   etu diff -f config.txt -o json
 
@@ -55,7 +77,7 @@ func init() {
 	rootCmd.AddCommand(diffCmd)
 
 	diffCmd.Flags().StringVarP(&diffOpts.FilePath, "file", "f", "",
-		"path to configuration file (required)")
+		"path to configuration file or '-' for stdin (required)")
 	diffCmd.Flags().StringVarP(&diffOpts.Format, "output", "o", output.FormatSimple.String(),
 		"output format: simple, json, yaml, table")
 	diffCmd.Flags().StringVar(&diffOpts.DeprecatedFormat, "format", "",
@@ -64,15 +86,29 @@ func init() {
 	diffCmd.Flags().BoolVar(&diffOpts.ShowUnchanged, "show-unchanged", false,
 		"show keys that are unchanged")
 	diffCmd.Flags().StringVar(&diffOpts.Prefix, "prefix", "",
-		"only compare keys with this prefix")
+		"only compare keys with this prefix or glob pattern")
+	diffCmd.Flags().StringVar(&diffOpts.Mount, "mount", "",
+		"mount the file's keys under this prefix (the --prefix of apply)")
+	diffCmd.Flags().StringVar(&diffOpts.KeyMap, "key-map", "",
+		"YAML file of rules that rewrite keys before comparing")
 	diffCmd.Flags().BoolVar(&diffOpts.Full, "full", false,
 		"compare all keys under prefix (requires --prefix); shows keys in etcd but not in file as deleted")
+	diffCmd.Flags().BoolVar(&diffOpts.Semantic, "semantic", false,
+		"compare JSON/YAML values structurally, ignoring key order and whitespace")
+	diffCmd.Flags().StringSliceVar(&diffOpts.IgnoreKeys, "ignore-keys", nil,
+		"glob pattern of keys to skip (matched against the full key if it contains '/', else the last segment); repeatable")
+
+	addFlattenFlags(diffCmd)
+	addTemplateFlags(diffCmd)
 
 	if err := diffCmd.MarkFlagRequired("file"); err != nil {
 		panic(fmt.Sprintf("failed to mark flag as required: %v", err))
 	}
 
 	registerFileCompletion(diffCmd, "file")
+	registerPrefixCompletion(diffCmd, "prefix")
+	registerPrefixCompletion(diffCmd, "mount")
+	registerFileCompletion(diffCmd, "key-map")
 }
 
 func runDiff(cmd *cobra.Command, _ []string) error {
@@ -92,6 +128,30 @@ func runDiff(cmd *cobra.Command, _ []string) error {
 		}
 	}
 
+	if err := output.ValidateIgnorePatterns(diffOpts.IgnoreKeys); err != nil {
+		return fmt.Errorf("✗ %w", err)
+	}
+
+	pattern, err := compileKeyPattern(diffOpts.Prefix)
+	if err != nil {
+		return err
+	}
+	inScope := func(key string) bool {
+		return strings.HasPrefix(key, diffOpts.Prefix)
+	}
+	fetchPrefix := diffOpts.Prefix
+	if pattern != nil {
+		inScope = pattern.Match
+		fetchPrefix = pattern.Prefix()
+	}
+
+	// --prefix filters here but mounts on apply, so a filter that excludes
+	// every mounted key is most likely the apply meaning
+	if diffOpts.Mount != "" && diffOpts.Prefix != "" &&
+		!strings.HasPrefix(diffOpts.Mount, fetchPrefix) && !strings.HasPrefix(fetchPrefix, diffOpts.Mount) {
+		return fmt.Errorf("✗ --prefix %s excludes every key mounted under --mount %s; diff's --prefix filters the comparison", diffOpts.Prefix, diffOpts.Mount)
+	}
+
 	ctx, cancel := getOperationContext()
 	defer cancel()
 
@@ -104,15 +164,18 @@ func runDiff(cmd *cobra.Command, _ []string) error {
 	}
 	logVerboseInfo(fmt.Sprintf("Parsed %d configuration items from file", len(pairs)))
 
+	if err := rewriteKeys(pairs, diffOpts.KeyMap, diffOpts.Mount); err != nil {
+		return err
+	}
+
+	// The file is compared against a single cluster
+	if err := requireSingleContext(pairs); err != nil {
+		return err
+	}
+
 	// Filter by prefix if specified
 	if diffOpts.Prefix != "" {
-		filtered := make([]*models.ConfigPair, 0)
-		for _, p := range pairs {
-			if strings.HasPrefix(p.Key, diffOpts.Prefix) {
-				filtered = append(filtered, p)
-			}
-		}
-		pairs = filtered
+		pairs = filterPairs(pairs, inScope)
 		logVerboseInfo(fmt.Sprintf("Filtered to %d items with prefix %s", len(pairs), diffOpts.Prefix))
 	}
 
@@ -133,7 +196,10 @@ func runDiff(cmd *cobra.Command, _ []string) error {
 	// - Full mode: fetch all keys under prefix
 	var etcdPairs []*models.ConfigPair
 	if diffOpts.Full {
-		etcdPairs, err = fetchEtcdStateByPrefix(ctx, etcdClient, diffOpts.Prefix)
+		etcdPairs, err = fetchEtcdStateByPrefix(ctx, etcdClient, fetchPrefix)
+		if err == nil && pattern != nil {
+			etcdPairs = filterPairs(etcdPairs, inScope)
+		}
 	} else {
 		etcdPairs, err = fetchEtcdStateForExactKeys(ctx, etcdClient, pairs)
 	}
@@ -154,7 +220,13 @@ func runDiff(cmd *cobra.Command, _ []string) error {
 		etcdMap[p.Key] = models.FormatValue(p.Value)
 	}
 
-	result := output.DiffKeyValues(fileMap, etcdMap)
+	result, err := output.DiffKeyValuesWithOptions(fileMap, etcdMap, &output.DiffOptions{
+		IgnoreKeys: diffOpts.IgnoreKeys,
+		Semantic:   diffOpts.Semantic,
+	})
+	if err != nil {
+		return err
+	}
 
 	return output.PrintDiffResult(result, diffOpts.Format, diffOpts.ShowUnchanged)
 }
@@ -190,3 +262,14 @@ func fetchEtcdStateByPrefix(ctx context.Context, etcdClient client.EtcdClient, p
 	}
 	return result, nil
 }
+
+// filterPairs returns the pairs whose key satisfies keep.
+func filterPairs(pairs []*models.ConfigPair, keep func(string) bool) []*models.ConfigPair {
+	filtered := make([]*models.ConfigPair, 0, len(pairs))
+	for _, p := range pairs {
+		if keep(p.Key) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
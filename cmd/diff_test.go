@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/kazuma-desu/etu/pkg/models"
 )
@@ -84,3 +86,61 @@ func TestDiffDeprecatedFormatFlag(t *testing.T) {
 
 	assert.Contains(t, stderr.String(), "deprecated")
 }
+
+func TestDiffInvalidIgnorePattern(t *testing.T) {
+	originalOpts := diffOpts
+	defer func() { diffOpts = originalOpts }()
+
+	diffOpts.FilePath = "test.txt"
+	diffOpts.IgnoreKeys = []string{"[invalid"}
+
+	err := runDiff(diffCmd, nil)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ignore pattern")
+}
+
+func TestDiffMountMustOverlapPrefix(t *testing.T) {
+	originalOpts := diffOpts
+	defer func() { diffOpts = originalOpts }()
+
+	diffOpts.FilePath = filepath.Join(t.TempDir(), "missing.yaml")
+	diffOpts.Mount = "/services/api"
+
+	diffOpts.Prefix = "/services/worker"
+	err := runDiff(diffCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--prefix /services/worker excludes every key mounted under --mount /services/api")
+
+	for _, prefix := range []string{"/services/api", "/services", "/services/api/db", "/services/*/db"} {
+		diffOpts.Prefix = prefix
+		err := runDiff(diffCmd, nil)
+		require.Error(t, err, "the file is missing")
+		assert.NotContains(t, err.Error(), "excludes every key", "prefix %s", prefix)
+	}
+}
+
+func TestDiffCommand_SemanticFlags(t *testing.T) {
+	semanticFlag := diffCmd.Flags().Lookup("semantic")
+	assert.NotNil(t, semanticFlag)
+	assert.Equal(t, "false", semanticFlag.DefValue)
+
+	ignoreFlag := diffCmd.Flags().Lookup("ignore-keys")
+	assert.NotNil(t, ignoreFlag)
+}
+
+func TestFilterPairs(t *testing.T) {
+	pattern, err := compileKeyPattern("/app/*/db/**")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pairs := []*models.ConfigPair{
+		{Key: "/app/api/db/host", Value: "a"},
+		{Key: "/app/api/cache/host", Value: "b"},
+		{Key: "/app/web/db/pool/size", Value: "5"},
+	}
+	filtered := filterPairs(pairs, pattern.Match)
+	assert.Len(t, filtered, 2)
+	assert.Equal(t, "/app/web/db/pool/size", filtered[1].Key)
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+var (
+	duOpts struct {
+		depth int
+	}
+
+	duCmd = &cobra.Command{
+		Use:   "du <prefix>",
+		Short: "Show key counts and value sizes per subtree",
+		Long: `Summarize a prefix like 'du': for each subtree, show the number of keys,
+the total size of their values and the largest key.
+
+Subtrees are listed down to --depth levels below the prefix, largest first.
+Keys deeper than that are counted in their ancestor at the depth limit.
+Keys are read in pages, so values are never all held in memory at once.`,
+		Example: `  # Size of each top-level subtree
+  etu du /
+
+  # Two levels below /teams
+  etu du /teams --depth 2
+
+  # Flat table for sorting or export
+  etu du /teams --depth 2 -o table`,
+		Args: cobra.ExactArgs(1),
+		RunE: runDu,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(duCmd)
+
+	duCmd.Flags().IntVar(&duOpts.depth, "depth", 1,
+		"number of levels below the prefix to show (0 = unlimited)")
+
+	registerKeyCompletion(duCmd, 1)
+}
+
+func runDu(_ *cobra.Command, args []string) error {
+	allowedFormats := []string{
+		output.FormatSimple.String(),
+		output.FormatJSON.String(),
+		output.FormatYAML.String(),
+		output.FormatTable.String(),
+		output.FormatTree.String(),
+	}
+	if err := validateOutputFormat(allowedFormats); err != nil {
+		return err
+	}
+
+	prefix := args[0]
+	if err := validateKeyPrefix(prefix); err != nil {
+		return err
+	}
+	if duOpts.depth < 0 {
+		return fmt.Errorf("✗ invalid --depth: must be non-negative")
+	}
+
+	cfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+
+	etcdClient, cleanup, err := newEtcdClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := getOperationContext()
+	defer cancel()
+
+	root, err := collectUsage(ctx, etcdClient, prefix, duOpts.depth)
+	if err != nil {
+		return wrapContextError(err)
+	}
+	if root.Keys == 0 {
+		output.Info(fmt.Sprintf("No keys found with prefix %s", prefix))
+		return nil
+	}
+
+	return output.PrintUsage(root, outputFormat)
+}
+
+// collectUsage reads every key under prefix page by page and aggregates
+// counts and sizes into a tree at most depth levels deep (0 = unlimited).
+func collectUsage(ctx context.Context, reader client.EtcdReader, prefix string, depth int) (*output.UsageNode, error) {
+	root := &output.UsageNode{Name: prefix, Path: prefix}
+	index := map[string]*output.UsageNode{prefix: root}
+
+	_, err := client.ForEachPage(ctx, reader, prefix, nil, func(kvs []*client.KeyValue) error {
+		for _, kv := range kvs {
+			// etcd matches prefixes byte by byte, so /app also reads /apple/x
+			rel := kv.Key[len(prefix):]
+			if rel != "" && rel[0] != '/' && !strings.HasSuffix(prefix, "/") {
+				continue
+			}
+			addUsage(root, index, prefix, kv.Key, int64(len(kv.Value)), depth)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys under %s: %w", prefix, err)
+	}
+
+	sortUsage(root)
+	return root, nil
+}
+
+// addUsage counts one key in the root and in each ancestor subtree down to
+// the depth limit. index maps node paths to nodes already created.
+func addUsage(root *output.UsageNode, index map[string]*output.UsageNode, prefix, key string, size int64, depth int) {
+	countUsage(root, key, size)
+
+	rel := key[len(prefix):]
+	node := root
+	level := 0
+	for pos := 0; pos < len(rel); {
+		// Skip separators, then find the end of the next segment
+		for pos < len(rel) && rel[pos] == '/' {
+			pos++
+		}
+		if pos == len(rel) {
+			break
+		}
+		end := strings.IndexByte(rel[pos:], '/')
+		if end < 0 {
+			end = len(rel)
+		} else {
+			end += pos
+		}
+
+		if depth > 0 && level == depth {
+			node.Dir = true
+			return
+		}
+		node.Dir = true
+
+		path := key[:len(prefix)+end]
+		child, ok := index[path]
+		if !ok {
+			child = &output.UsageNode{Name: rel[pos:end], Path: path}
+			index[path] = child
+			node.Children = append(node.Children, child)
+		}
+		countUsage(child, key, size)
+
+		node = child
+		level++
+		pos = end
+	}
+}
+
+func countUsage(n *output.UsageNode, key string, size int64) {
+	n.Keys++
+	n.Bytes += size
+	if n.Largest == "" || size > n.LargestSize {
+		n.Largest = key
+		n.LargestSize = size
+	}
+}
+
+// sortUsage orders children largest first, then by name.
+func sortUsage(n *output.UsageNode) {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		a, b := n.Children[i], n.Children[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.Name < b.Name
+	})
+	for _, child := range n.Children {
+		sortUsage(child)
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+func usageMock(kvs ...*client.KeyValue) *client.MockClient {
+	return &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return &client.GetResponse{Kvs: kvs}, nil
+		},
+	}
+}
+
+func childNames(n *output.UsageNode) []string {
+	names := make([]string, len(n.Children))
+	for i, c := range n.Children {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func TestCollectUsage_Depth(t *testing.T) {
+	mock := usageMock(
+		&client.KeyValue{Key: "/teams/a/db/blob", Value: strings.Repeat("x", 900)},
+		&client.KeyValue{Key: "/teams/a/db/host", Value: "db1"},
+		&client.KeyValue{Key: "/teams/a/name", Value: "alpha"},
+		&client.KeyValue{Key: "/teams/b/cfg", Value: strings.Repeat("y", 100)},
+		&client.KeyValue{Key: "/teams/readme", Value: "hi"},
+	)
+
+	t.Run("depth one", func(t *testing.T) {
+		root, err := collectUsage(context.Background(), mock, "/teams", 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, "/teams", root.Path)
+		assert.Equal(t, 5, root.Keys)
+		assert.Equal(t, int64(900+3+5+100+2), root.Bytes)
+		assert.Equal(t, "/teams/a/db/blob", root.Largest)
+		assert.True(t, root.Dir)
+
+		// Sorted largest first
+		assert.Equal(t, []string{"a", "b", "readme"}, childNames(root))
+
+		a := root.Children[0]
+		assert.Equal(t, "/teams/a", a.Path)
+		assert.Equal(t, 3, a.Keys)
+		assert.Equal(t, int64(908), a.Bytes)
+		assert.True(t, a.Dir)
+		assert.Empty(t, a.Children, "deeper levels are folded into the depth-1 node")
+
+		readme := root.Children[2]
+		assert.False(t, readme.Dir)
+		assert.Equal(t, 1, readme.Keys)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		root, err := collectUsage(context.Background(), mock, "/teams/", 0)
+		require.NoError(t, err)
+
+		a := root.Children[0]
+		assert.Equal(t, "/teams/a", a.Path)
+		assert.Equal(t, []string{"db", "name"}, childNames(a))
+
+		db := a.Children[0]
+		assert.Equal(t, "/teams/a/db", db.Path)
+		assert.Equal(t, []string{"blob", "host"}, childNames(db))
+		assert.Equal(t, "/teams/a/db/blob", db.Children[0].Path)
+		assert.False(t, db.Children[0].Dir)
+	})
+}
+
+func TestCollectUsage_KeyWithChildren(t *testing.T) {
+	root, err := collectUsage(context.Background(), usageMock(
+		&client.KeyValue{Key: "/app", Value: "root"},
+		&client.KeyValue{Key: "/app/db", Value: "dir-value"},
+		&client.KeyValue{Key: "/app/db/host", Value: "h"},
+	), "/app", 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, root.Keys)
+	require.Len(t, root.Children, 1)
+	db := root.Children[0]
+	assert.True(t, db.Dir)
+	assert.Equal(t, 2, db.Keys)
+	assert.Equal(t, int64(len("dir-value")+1), db.Bytes)
+}
+
+func TestCollectUsage_SegmentBoundary(t *testing.T) {
+	root, err := collectUsage(context.Background(), usageMock(
+		&client.KeyValue{Key: "/app/db", Value: "h"},
+		&client.KeyValue{Key: "/apple/x", Value: "fruit"},
+		&client.KeyValue{Key: "/apps", Value: "v"},
+	), "/app", 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, root.Keys)
+	assert.Equal(t, []string{"db"}, childNames(root))
+}
+
+func TestCollectUsage_Empty(t *testing.T) {
+	root, err := collectUsage(context.Background(), usageMock(), "/none", 1)
+	require.NoError(t, err)
+	assert.Zero(t, root.Keys)
+	assert.Empty(t, root.Children)
+}
+
+func TestCollectUsage_ReadError(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	_, err := collectUsage(context.Background(), mock, "/app", 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read keys under /app")
+}
@@ -27,6 +27,7 @@ var (
 
 func init() {
 	rootCmd.AddCommand(editCmd)
+	registerKeyCompletion(editCmd, 1)
 }
 
 func runEdit(_ *cobra.Command, args []string) error {
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/output"
+	"github.com/kazuma-desu/etu/pkg/parsers"
+)
+
+var (
+	exportOpts struct {
+		prefix      string
+		file        string
+		format      string
+		reroot      string
+		arrays      string
+		types       bool
+		stripPrefix bool
+	}
+
+	exportCmd = &cobra.Command{
+		Use:   "export --prefix <prefix>",
+		Short: "Export keys under a prefix to a YAML, JSON or etcdctl file",
+		Long: `Export all keys under a prefix to a file that round-trips losslessly through 'etu apply'.
+
+Empty values are kept. A key that is both a value and a directory (e.g. /app/db and
+/app/db/host) is written with the reserved empty key "" holding its own value.
+Keys or values a format cannot represent exactly are reported as errors instead of
+being altered.
+
+Note: -o/--output names the output file for this command, not the output format.`,
+		Example: `  # Export a prefix to YAML
+  etu export --prefix /app -o app.yaml
+
+  # Export in etcdctl format to stdout
+  etu export --prefix /app --format etcdctl
+
+  # Strip the prefix so the file can be applied elsewhere
+  etu export --prefix /app/prod --strip-prefix -o prod.yaml
+
+  # Write /list/0, /list/1 back as a YAML list for 'etu apply --arrays indexed'
+  etu export --prefix /app --arrays indexed -o app.yaml
+
+  # Restore the value types recorded by 'etu apply --preserve-types'
+  etu export --prefix /app --preserve-types -o app.json
+
+  # Re-root keys under a new prefix
+  etu export --prefix /app/prod --reroot /app/staging -o staging.json
+
+  # Export keys applied with 'etu apply --separator .'
+  etu export --prefix app. --separator . -o app.yaml`,
+		Args: cobra.NoArgs,
+		RunE: runExport,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&exportOpts.prefix, "prefix", "",
+		"prefix of the keys to export (required)")
+	exportCmd.Flags().StringVarP(&exportOpts.file, "output", "o", "",
+		"file to write (default: stdout)")
+	exportCmd.Flags().StringVar(&exportOpts.format, "format", "",
+		"file format: yaml, json, etcdctl (default: from file extension, else yaml)")
+	exportCmd.Flags().BoolVar(&exportOpts.stripPrefix, "strip-prefix", false,
+		"remove the prefix from exported keys")
+	exportCmd.Flags().StringVar(&exportOpts.reroot, "reroot", "",
+		"replace the prefix with this one in exported keys")
+	exportCmd.Flags().StringVar(&exportOpts.arrays, "arrays", string(parsers.ArrayJSON),
+		"array mode the file will be applied with: json, indexed or csv; indexed and csv write arrays back")
+	exportCmd.Flags().BoolVar(&exportOpts.types, "preserve-types", false,
+		"write values with the types recorded by 'apply --preserve-types' (yaml and json)")
+
+	addLayoutFlags(exportCmd)
+
+	_ = exportCmd.MarkFlagRequired("prefix")
+	exportCmd.MarkFlagsMutuallyExclusive("strip-prefix", "reroot")
+
+	registerPrefixCompletion(exportCmd, "prefix")
+}
+
+func runExport(_ *cobra.Command, _ []string) error {
+	layout, err := resolveKeyLayout(loadAppConfig())
+	if err != nil {
+		return err
+	}
+
+	prefix := exportOpts.prefix
+	if err := validateLayoutKey(prefix, layout); err != nil {
+		return err
+	}
+	if exportOpts.reroot != "" {
+		if err := validateLayoutKey(exportOpts.reroot, layout); err != nil {
+			return err
+		}
+	}
+
+	format, err := resolveExportFormat(exportOpts.format, exportOpts.file)
+	if err != nil {
+		return err
+	}
+	arrays := parsers.ArrayMode(exportOpts.arrays)
+	if err := validateArrayMode(arrays); err != nil {
+		return err
+	}
+
+	cfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+
+	etcdClient, cleanup, err := newEtcdClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := getOperationContext()
+	defer cancel()
+
+	logVerbose("Exporting keys", "prefix", prefix, "format", format)
+	kvs, revision, err := client.GetAllWithPrefix(ctx, etcdClient, prefix, nil)
+	if err != nil {
+		return wrapContextError(fmt.Errorf("failed to read keys: %w", err))
+	}
+
+	if len(kvs) == 0 {
+		output.Warning(fmt.Sprintf("No keys found with prefix: %s", prefix))
+		return nil
+	}
+
+	pairs := make([]*models.ConfigPair, 0, len(kvs))
+	for _, kv := range kvs {
+		// Prefixes of layouts without a root may cover the type sidecars
+		if parsers.IsTypeKey(kv.Key) && !parsers.IsTypeKey(prefix) {
+			continue
+		}
+		pairs = append(pairs, &models.ConfigPair{Key: kv.Key, Value: kv.Value})
+	}
+
+	if exportOpts.types {
+		types, err := fetchTypes(ctx, etcdClient, prefix)
+		if err != nil {
+			return err
+		}
+		parsers.ApplyTypes(pairs, types)
+	}
+
+	newPrefix := exportOpts.reroot
+	if !exportOpts.stripPrefix && newPrefix == "" {
+		newPrefix = prefix
+	}
+	for _, pair := range pairs {
+		pair.Key = rewriteLayoutKeyPrefix(pair.Key, prefix, newPrefix, layout)
+	}
+
+	var buf bytes.Buffer
+	exportOptions := &output.ExportOptions{Arrays: arrays, Types: exportOpts.types, Layout: layout}
+	if err := output.ExportPairsWithOptions(&buf, pairs, format, exportOptions); err != nil {
+		return fmt.Errorf("✗ %w", err)
+	}
+
+	if exportOpts.file == "" || exportOpts.file == "-" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+
+	if err := os.WriteFile(exportOpts.file, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("✗ failed to write %s: %w", exportOpts.file, err)
+	}
+
+	if !isQuietOutput() {
+		output.Success(fmt.Sprintf("Exported %d keys from %s to %s (revision %d)",
+			len(pairs), prefix, exportOpts.file, revision))
+	}
+	return nil
+}
+
+// resolveExportFormat returns the explicit --format, or infers it from the
+// output file extension, defaulting to YAML.
+func resolveExportFormat(flagFormat, file string) (models.FormatType, error) {
+	if flagFormat != "" {
+		format := models.FormatType(flagFormat)
+		switch format {
+		case models.FormatYAML, models.FormatJSON, models.FormatEtcdctl:
+			return format, nil
+		default:
+			return "", fmt.Errorf("✗ invalid export format: %s (use yaml, json, or etcdctl)", flagFormat)
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return models.FormatJSON, nil
+	case ".txt":
+		return models.FormatEtcdctl, nil
+	default:
+		return models.FormatYAML, nil
+	}
+}
+
+// rewriteKeyPrefix replaces oldPrefix at the start of key with newPrefix.
+// The result always starts with "/" so it remains a valid key.
+func rewriteKeyPrefix(key, oldPrefix, newPrefix string) string {
+	rewritten := newPrefix + strings.TrimPrefix(key, oldPrefix)
+	if !strings.HasPrefix(rewritten, "/") {
+		rewritten = "/" + rewritten
+	}
+	return rewritten
+}
+
+// rewriteLayoutKeyPrefix is rewriteKeyPrefix for keys in layout. When the
+// prefix is stripped, the separator that followed it is dropped and the
+// root added back.
+func rewriteLayoutKeyPrefix(key, oldPrefix, newPrefix string, layout models.KeyLayout) string {
+	if layout.IsDefault() {
+		return rewriteKeyPrefix(key, oldPrefix, newPrefix)
+	}
+	r := layout.Resolved()
+	rest := strings.TrimPrefix(key, oldPrefix)
+	if newPrefix == "" || newPrefix == r.Root {
+		return r.Root + strings.TrimPrefix(rest, r.Separator)
+	}
+	return newPrefix + rest
+}
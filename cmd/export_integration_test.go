@@ -0,0 +1,123 @@
+//go:build integration
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCommand_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	tempDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	defer os.Setenv("HOME", oldHome)
+
+	fullEndpoint := setupEtcdContainerForCmd(t)
+	ctx := context.Background()
+
+	etcdClient, err := client.NewClient(&client.Config{Endpoints: []string{fullEndpoint}})
+	require.NoError(t, err)
+	defer etcdClient.Close()
+
+	appCfg := &config.Config{
+		Contexts: map[string]*config.ContextConfig{
+			"test": {Endpoints: []string{fullEndpoint}},
+		},
+		CurrentContext: "test",
+	}
+	require.NoError(t, config.SaveConfig(appCfg))
+
+	testData := map[string]string{
+		"/export/app/name":      "myapp",
+		"/export/app/empty":     "",
+		"/export/app/db":        "primary",
+		"/export/app/db/host":   "localhost",
+		"/export/app/multiline": "a\nb",
+	}
+	for k, v := range testData {
+		require.NoError(t, etcdClient.Put(ctx, k, v))
+	}
+
+	originalOpts := exportOpts
+	restoreOpts := func() { exportOpts = originalOpts }
+
+	for _, format := range []models.FormatType{models.FormatYAML, models.FormatJSON, models.FormatEtcdctl} {
+		t.Run("round trip "+string(format), func(t *testing.T) {
+			t.Cleanup(restoreOpts)
+
+			exportOpts.prefix = "/export/app"
+			exportOpts.file = filepath.Join(tempDir, "export."+string(format))
+			exportOpts.format = string(format)
+
+			_, err := testutil.CaptureStdout(func() error {
+				return runExport(exportCmd, nil)
+			})
+			require.NoError(t, err)
+
+			pairs, err := parseConfigFile(ctx, exportOpts.file, format, nil)
+			require.NoError(t, err)
+
+			got := make(map[string]string, len(pairs))
+			for _, p := range pairs {
+				got[p.Key] = p.Value
+			}
+			assert.Equal(t, testData, got)
+		})
+	}
+
+	t.Run("strip prefix", func(t *testing.T) {
+		t.Cleanup(restoreOpts)
+
+		exportOpts.prefix = "/export/app"
+		exportOpts.stripPrefix = true
+		exportOpts.format = "etcdctl"
+
+		output, err := testutil.CaptureStdout(func() error {
+			return runExport(exportCmd, nil)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, output, "/db/host\nlocalhost\n")
+		assert.NotContains(t, output, "/export/app")
+	})
+
+	t.Run("custom separator", func(t *testing.T) {
+		t.Cleanup(restoreOpts)
+		t.Cleanup(func() { layoutOpts = models.KeyLayout{} })
+
+		require.NoError(t, etcdClient.Put(ctx, "dotted.db.host", "localhost"))
+		require.NoError(t, etcdClient.Put(ctx, "dotted.db.port", "5432"))
+
+		exportOpts.prefix = "dotted."
+		exportOpts.format = "yaml"
+		layoutOpts.Separator = "."
+
+		output, err := testutil.CaptureStdout(func() error {
+			return runExport(exportCmd, nil)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, output, "dotted:\n    db:\n        host: localhost\n")
+
+		exportOpts.stripPrefix = true
+		output, err = testutil.CaptureStdout(func() error {
+			return runExport(exportCmd, nil)
+		})
+		require.NoError(t, err)
+		assert.Contains(t, output, "db:\n    host: localhost\n")
+		assert.NotContains(t, output, "dotted")
+	})
+}
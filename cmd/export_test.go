@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+func TestResolveExportFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		file    string
+		want    models.FormatType
+		wantErr bool
+	}{
+		{name: "explicit yaml", flag: "yaml", file: "out.json", want: models.FormatYAML},
+		{name: "explicit etcdctl", flag: "etcdctl", want: models.FormatEtcdctl},
+		{name: "json extension", file: "out.JSON", want: models.FormatJSON},
+		{name: "txt extension", file: "dump.txt", want: models.FormatEtcdctl},
+		{name: "yml extension", file: "out.yml", want: models.FormatYAML},
+		{name: "stdout defaults to yaml", want: models.FormatYAML},
+		{name: "invalid format", flag: "toml", wantErr: true},
+		{name: "auto is not an export format", flag: "auto", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveExportFormat(tt.flag, tt.file)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid export format")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRewriteKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key, oldPrefix, newPrefix, want string
+	}{
+		{"/app/prod/db/host", "/app/prod", "/app/prod", "/app/prod/db/host"},
+		{"/app/prod/db/host", "/app/prod", "", "/db/host"},
+		{"/app/prod/db/host", "/app/prod/", "", "/db/host"},
+		{"/app/prod", "/app/prod", "", "/"},
+		{"/app/prod/db/host", "/app/prod", "/app/staging", "/app/staging/db/host"},
+		{"/app/prod/db/host", "/app/prod/", "/app/staging/", "/app/staging/db/host"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, rewriteKeyPrefix(tt.key, tt.oldPrefix, tt.newPrefix),
+			"rewrite %s from %s to %s", tt.key, tt.oldPrefix, tt.newPrefix)
+	}
+}
+
+func TestRewriteLayoutKeyPrefix(t *testing.T) {
+	dots := models.KeyLayout{Separator: "."}
+	colons := models.KeyLayout{Separator: ":", Root: "config:"}
+
+	tests := []struct {
+		key, oldPrefix, newPrefix string
+		layout                    models.KeyLayout
+		want                      string
+	}{
+		{"/app/prod/db", "/app/prod", "", models.KeyLayout{}, "/db"},
+		{"app.prod.db.host", "app.prod", "app.prod", dots, "app.prod.db.host"},
+		{"app.prod.db.host", "app.prod", "", dots, "db.host"},
+		{"app.prod.db.host", "app.prod.", "", dots, "db.host"},
+		{"app.prod.db.host", "app.prod", "app.staging", dots, "app.staging.db.host"},
+		{"config:app:db", "config:app", "", colons, "config:db"},
+		{"config:app:db", "config:app:", "config:", colons, "config:db"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, rewriteLayoutKeyPrefix(tt.key, tt.oldPrefix, tt.newPrefix, tt.layout),
+			"rewrite %s from %s to %s", tt.key, tt.oldPrefix, tt.newPrefix)
+	}
+}
+
+func TestRunExport_LayoutPrefix(t *testing.T) {
+	t.Cleanup(func() {
+		layoutOpts = models.KeyLayout{}
+		exportOpts.prefix, exportOpts.reroot = "", ""
+	})
+
+	layoutOpts = models.KeyLayout{Separator: ":", Root: "config:"}
+	exportOpts.prefix = "/app"
+	err := runExport(exportCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "✗ key must start with 'config:'")
+
+	exportOpts.prefix = "config:app"
+	exportOpts.reroot = "other:app"
+	err = runExport(exportCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "✗ key must start with 'config:': other:app")
+}
+
+func TestExportCommand_OutputFlagIsFile(t *testing.T) {
+	flag := exportCmd.Flags().Lookup("output")
+	require.NotNil(t, flag)
+	assert.Equal(t, "o", flag.Shorthand)
+	assert.Equal(t, "", flag.DefValue)
+
+	require.NotNil(t, exportCmd.Flags().Lookup("prefix"))
+	require.NotNil(t, exportCmd.Flags().Lookup("strip-prefix"))
+	require.NotNil(t, exportCmd.Flags().Lookup("reroot"))
+}
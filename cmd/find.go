@@ -0,0 +1,383 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+var (
+	findOpts struct {
+		name             string
+		size             string
+		version          string
+		modifiedAfterRev int64
+		hasLease         bool
+		empty            bool
+		execDelete       bool
+		print0           bool
+		force            bool
+		dryRun           bool
+	}
+
+	findCmd = &cobra.Command{
+		Use:   "find <prefix>",
+		Short: "Find keys under a prefix by name and metadata",
+		Long: `Find keys under a prefix that match every given predicate.
+
+Predicates:
+  --name <glob>              last path segment matches a shell glob (*, ?, [...])
+  --size <cmp>               value size, e.g. >10k, <=512, +1M (more than), -1k (less than)
+  --version <cmp>            number of writes since creation, e.g. >100
+  --modified-after-rev <n>   last modified after revision n
+  --has-lease                attached to a lease
+  --empty                    empty value
+
+Sizes accept the suffixes k, m and g (powers of 1024). A comparison without an
+operator means "equal to".
+
+Actions:
+  --print0                   print matching keys separated by NUL, for xargs -0
+  --exec-delete              list the matching keys, confirm, then delete them
+
+Deletes are guarded by each key's mod revision, so a key modified after it was
+listed is not deleted.`,
+		Example: `  # Find oversized values
+  etu find /app --size '>100k' -o table
+
+  # Find keys named "*.bak" anywhere under /app
+  etu find /app --name '*.bak'
+
+  # Find keys attached to leases that were never updated
+  etu find /sessions --has-lease --version 1
+
+  # Delete empty keys after confirmation
+  etu find /app --empty --exec-delete
+
+  # Pipe matches into another tool
+  etu find /app --empty --print0 | xargs -0 -n1 echo`,
+		Args: cobra.ExactArgs(1),
+		RunE: runFind,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(findCmd)
+
+	findCmd.Flags().StringVar(&findOpts.name, "name", "",
+		"match the last path segment against a glob")
+	findCmd.Flags().StringVar(&findOpts.size, "size", "",
+		"match the value size, e.g. >10k")
+	findCmd.Flags().StringVar(&findOpts.version, "version", "",
+		"match the key version, e.g. >5")
+	findCmd.Flags().Int64Var(&findOpts.modifiedAfterRev, "modified-after-rev", 0,
+		"match keys modified after this revision")
+	findCmd.Flags().BoolVar(&findOpts.hasLease, "has-lease", false,
+		"match keys attached to a lease")
+	findCmd.Flags().BoolVar(&findOpts.empty, "empty", false,
+		"match keys with an empty value")
+	findCmd.Flags().BoolVar(&findOpts.execDelete, "exec-delete", false,
+		"delete the matching keys after confirmation")
+	findCmd.Flags().BoolVar(&findOpts.print0, "print0", false,
+		"print matching keys separated by NUL characters")
+	findCmd.Flags().BoolVar(&findOpts.force, "force", false,
+		"skip confirmation prompt for --exec-delete")
+	findCmd.Flags().BoolVar(&findOpts.dryRun, "dry-run", false,
+		"with --exec-delete, list the keys that would be deleted without deleting")
+
+	registerKeyCompletion(findCmd, 1)
+}
+
+// comparison is a parsed numeric predicate such as ">10k".
+type comparison struct {
+	op    string
+	value int64
+}
+
+func (c comparison) matches(n int64) bool {
+	switch c.op {
+	case ">":
+		return n > c.value
+	case ">=":
+		return n >= c.value
+	case "<":
+		return n < c.value
+	case "<=":
+		return n <= c.value
+	default:
+		return n == c.value
+	}
+}
+
+// findFilter holds the parsed predicates. Nil comparisons are not applied.
+type findFilter struct {
+	size             *comparison
+	version          *comparison
+	name             string
+	modifiedAfterRev int64
+	hasLease         bool
+	empty            bool
+}
+
+func runFind(_ *cobra.Command, args []string) error {
+	allowedFormats := []string{
+		output.FormatSimple.String(),
+		output.FormatJSON.String(),
+		output.FormatYAML.String(),
+		output.FormatTable.String(),
+	}
+	if err := validateOutputFormat(allowedFormats); err != nil {
+		return err
+	}
+
+	prefix := args[0]
+	if err := validateKeyPrefix(prefix); err != nil {
+		return err
+	}
+	if findOpts.print0 && outputFormat != output.FormatSimple.String() {
+		return fmt.Errorf("✗ --print0 cannot be combined with -o %s", outputFormat)
+	}
+	if (findOpts.force || findOpts.dryRun) && !findOpts.execDelete {
+		return fmt.Errorf("✗ --force and --dry-run only apply to --exec-delete")
+	}
+
+	filter, err := buildFindFilter()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+
+	etcdClient, cleanup, err := newEtcdClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := getOperationContext()
+	defer cancel()
+
+	matches, err := findKeys(ctx, etcdClient, prefix, filter)
+	if err != nil {
+		return wrapContextError(err)
+	}
+
+	if findOpts.print0 {
+		for _, kv := range matches {
+			fmt.Printf("%s\x00", kv.Key)
+		}
+	} else if err := output.PrintKeyInfos(keyInfos(matches), outputFormat); err != nil {
+		return err
+	}
+
+	if !findOpts.execDelete {
+		return nil
+	}
+	if len(matches) == 0 {
+		output.Info("No keys matched; nothing to delete")
+		return nil
+	}
+	if findOpts.dryRun {
+		output.Info(fmt.Sprintf("Would delete %d keys", len(matches)))
+		return nil
+	}
+	if !findOpts.force {
+		// The prompt goes to stderr so structured output on stdout stays parseable
+		fmt.Fprintf(os.Stderr, "\nDelete %d matched keys? [y/N]: ", len(matches))
+		if !readConfirmation(os.Stdin) {
+			output.Info("Deletion canceled")
+			return nil
+		}
+	}
+
+	deleted, err := deleteFoundKeys(ctx, etcdClient, matches)
+	if err != nil {
+		if deleted > 0 {
+			output.Warning(fmt.Sprintf("Partial failure: %d/%d keys deleted before error", deleted, len(matches)))
+		}
+		if errors.Is(err, client.ErrTxnConflict) {
+			return fmt.Errorf("✗ a matched key was modified after it was listed; run the command again")
+		}
+		return wrapContextError(err)
+	}
+
+	output.Success(fmt.Sprintf("Deleted %d keys", deleted))
+	return nil
+}
+
+func buildFindFilter() (*findFilter, error) {
+	filter := &findFilter{
+		name:             findOpts.name,
+		modifiedAfterRev: findOpts.modifiedAfterRev,
+		hasLease:         findOpts.hasLease,
+		empty:            findOpts.empty,
+	}
+
+	if filter.name != "" {
+		if _, err := path.Match(filter.name, ""); err != nil {
+			return nil, fmt.Errorf("✗ invalid --name pattern %q: %w", filter.name, err)
+		}
+	}
+	if findOpts.size != "" {
+		c, err := parseComparison(findOpts.size, parseByteSize)
+		if err != nil {
+			return nil, fmt.Errorf("✗ invalid --size %q: %w", findOpts.size, err)
+		}
+		filter.size = &c
+	}
+	if findOpts.version != "" {
+		c, err := parseComparison(findOpts.version, func(s string) (int64, error) {
+			return strconv.ParseInt(s, 10, 64)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("✗ invalid --version %q: %w", findOpts.version, err)
+		}
+		filter.version = &c
+	}
+	if filter.modifiedAfterRev < 0 {
+		return nil, fmt.Errorf("✗ invalid --modified-after-rev: must be non-negative")
+	}
+	return filter, nil
+}
+
+// parseComparison parses an optional operator (>, >=, <, <=, =, or the
+// find-style + and -) followed by a value.
+func parseComparison(s string, parseValue func(string) (int64, error)) (comparison, error) {
+	s = strings.TrimSpace(s)
+	var op string
+	for _, candidate := range []string{">=", "<=", ">", "<", "=", "+", "-"} {
+		if strings.HasPrefix(s, candidate) {
+			op = candidate
+			s = strings.TrimSpace(s[len(candidate):])
+			break
+		}
+	}
+	switch op {
+	case "+":
+		op = ">"
+	case "-":
+		op = "<"
+	case "":
+		op = "="
+	}
+
+	value, err := parseValue(s)
+	if err != nil {
+		return comparison{}, err
+	}
+	if value < 0 {
+		return comparison{}, fmt.Errorf("value must be non-negative")
+	}
+	return comparison{op: op, value: value}, nil
+}
+
+// parseByteSize parses sizes such as 512, 10k, 1.5M or 2GiB.
+func parseByteSize(s string) (int64, error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	lower = strings.TrimSuffix(strings.TrimSuffix(lower, "ib"), "b")
+
+	multiplier := int64(1)
+	if lower != "" {
+		switch lower[len(lower)-1] {
+		case 'k':
+			multiplier = 1 << 10
+		case 'm':
+			multiplier = 1 << 20
+		case 'g':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			lower = lower[:len(lower)-1]
+		}
+	}
+
+	n, err := strconv.ParseFloat(lower, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a size such as 512, 10k or 1M")
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+func (f *findFilter) matches(kv *client.KeyValue) bool {
+	if f.name != "" {
+		if ok, _ := path.Match(f.name, path.Base(kv.Key)); !ok {
+			return false
+		}
+	}
+	if f.size != nil && !f.size.matches(int64(len(kv.Value))) {
+		return false
+	}
+	if f.version != nil && !f.version.matches(kv.Version) {
+		return false
+	}
+	if f.modifiedAfterRev > 0 && kv.ModRevision <= f.modifiedAfterRev {
+		return false
+	}
+	if f.hasLease && kv.Lease == 0 {
+		return false
+	}
+	if f.empty && kv.Value != "" {
+		return false
+	}
+	return true
+}
+
+// findKeys reads every key under prefix page by page and returns those that
+// match filter, in key order.
+func findKeys(ctx context.Context, reader client.EtcdReader, prefix string, filter *findFilter) ([]*client.KeyValue, error) {
+	var matches []*client.KeyValue
+	_, err := client.ForEachPage(ctx, reader, prefix, nil, func(kvs []*client.KeyValue) error {
+		for _, kv := range kvs {
+			if filter.matches(kv) {
+				matches = append(matches, kv)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys under %s: %w", prefix, err)
+	}
+	return matches, nil
+}
+
+func keyInfos(kvs []*client.KeyValue) []output.KeyInfo {
+	infos := make([]output.KeyInfo, len(kvs))
+	for i, kv := range kvs {
+		infos[i] = output.KeyInfo{
+			Key:            kv.Key,
+			Size:           int64(len(kv.Value)),
+			CreateRevision: kv.CreateRevision,
+			ModRevision:    kv.ModRevision,
+			Version:        kv.Version,
+			Lease:          kv.Lease,
+		}
+	}
+	return infos
+}
+
+// deleteFoundKeys deletes kvs in guarded transactions so that a key modified
+// since it was read is left alone. It returns the number of keys deleted.
+func deleteFoundKeys(ctx context.Context, writer client.EtcdWriter, kvs []*client.KeyValue) (int, error) {
+	units := make([]txnUnit, len(kvs))
+	for i, kv := range kvs {
+		units[i] = txnUnit{
+			guards: []client.TxnGuard{{Key: kv.Key, ModRevision: kv.ModRevision}},
+			ops:    []client.TxnOp{{Type: client.TxnOpDelete, Key: kv.Key}},
+		}
+	}
+	deleted, _, err := commitUnits(ctx, writer, units)
+	return deleted, err
+}
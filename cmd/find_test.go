@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "512", want: 512},
+		{input: "10k", want: 10 * 1024},
+		{input: "10K", want: 10 * 1024},
+		{input: "10kb", want: 10 * 1024},
+		{input: "1.5M", want: 1536 * 1024},
+		{input: "2GiB", want: 2 << 30},
+		{input: "", wantErr: true},
+		{input: "ten", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseByteSize(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseComparison(t *testing.T) {
+	parseInt := func(s string) (int64, error) { return strconv.ParseInt(s, 10, 64) }
+
+	tests := []struct {
+		input string
+		want  comparison
+	}{
+		{input: ">5", want: comparison{op: ">", value: 5}},
+		{input: ">=5", want: comparison{op: ">=", value: 5}},
+		{input: "<5", want: comparison{op: "<", value: 5}},
+		{input: "<= 5", want: comparison{op: "<=", value: 5}},
+		{input: "=5", want: comparison{op: "=", value: 5}},
+		{input: "5", want: comparison{op: "=", value: 5}},
+		{input: "+5", want: comparison{op: ">", value: 5}},
+		{input: "-5", want: comparison{op: "<", value: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseComparison(tt.input, parseInt)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := parseComparison(">x", parseInt)
+	assert.Error(t, err)
+}
+
+func TestComparisonMatches(t *testing.T) {
+	assert.True(t, comparison{op: ">", value: 5}.matches(6))
+	assert.False(t, comparison{op: ">", value: 5}.matches(5))
+	assert.True(t, comparison{op: ">=", value: 5}.matches(5))
+	assert.True(t, comparison{op: "<", value: 5}.matches(4))
+	assert.True(t, comparison{op: "<=", value: 5}.matches(5))
+	assert.True(t, comparison{op: "=", value: 5}.matches(5))
+	assert.False(t, comparison{op: "=", value: 5}.matches(4))
+}
+
+func TestBuildFindFilter(t *testing.T) {
+	originalOpts := findOpts
+	defer func() { findOpts = originalOpts }()
+
+	findOpts.name = "*.bak"
+	findOpts.size = ">10k"
+	findOpts.version = "1"
+	filter, err := buildFindFilter()
+	require.NoError(t, err)
+	assert.Equal(t, "*.bak", filter.name)
+	assert.Equal(t, &comparison{op: ">", value: 10240}, filter.size)
+	assert.Equal(t, &comparison{op: "=", value: 1}, filter.version)
+
+	tests := []struct {
+		name, size, version string
+		expected            string
+	}{
+		{name: "[bad", expected: "invalid --name pattern"},
+		{size: ">lots", expected: "invalid --size"},
+		{version: ">1.5", expected: "invalid --version"},
+	}
+	for _, tt := range tests {
+		findOpts = originalOpts
+		findOpts.name, findOpts.size, findOpts.version = tt.name, tt.size, tt.version
+		_, err := buildFindFilter()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tt.expected)
+	}
+}
+
+func TestFindFilterMatches(t *testing.T) {
+	kv := &client.KeyValue{Key: "/app/db/config.bak", Value: "0123456789", ModRevision: 20, Version: 3, Lease: 7}
+
+	tests := []struct {
+		filter findFilter
+		name   string
+		want   bool
+	}{
+		{name: "no predicates", filter: findFilter{}, want: true},
+		{name: "name matches last segment", filter: findFilter{name: "*.bak"}, want: true},
+		{name: "name does not cross segments", filter: findFilter{name: "db*"}, want: false},
+		{name: "size greater", filter: findFilter{size: &comparison{op: ">", value: 5}}, want: true},
+		{name: "size smaller", filter: findFilter{size: &comparison{op: "<", value: 5}}, want: false},
+		{name: "version", filter: findFilter{version: &comparison{op: "=", value: 3}}, want: true},
+		{name: "modified after", filter: findFilter{modifiedAfterRev: 19}, want: true},
+		{name: "not modified after", filter: findFilter{modifiedAfterRev: 20}, want: false},
+		{name: "has lease", filter: findFilter{hasLease: true}, want: true},
+		{name: "empty", filter: findFilter{empty: true}, want: false},
+		{name: "all must match", filter: findFilter{name: "*.bak", empty: true}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.matches(kv))
+		})
+	}
+
+	assert.False(t, (&findFilter{hasLease: true}).matches(&client.KeyValue{Key: "/a"}))
+	assert.True(t, (&findFilter{empty: true}).matches(&client.KeyValue{Key: "/a"}))
+}
+
+func TestFindKeys(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return &client.GetResponse{Kvs: []*client.KeyValue{
+				{Key: "/app/a", Value: ""},
+				{Key: "/app/b", Value: "x"},
+				{Key: "/app/c", Value: ""},
+			}}, nil
+		},
+	}
+
+	matches, err := findKeys(context.Background(), mock, "/app", &findFilter{empty: true})
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "/app/a", matches[0].Key)
+	assert.Equal(t, "/app/c", matches[1].Key)
+	assert.NotEmpty(t, mock.GetWithOptionsCalls[0].Opts.RangeEnd)
+}
+
+func TestFindKeys_ReadError(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	_, err := findKeys(context.Background(), mock, "/app", &findFilter{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read keys under /app")
+}
+
+func TestKeyInfos(t *testing.T) {
+	infos := keyInfos([]*client.KeyValue{
+		{Key: "/a", Value: "hello", CreateRevision: 1, ModRevision: 2, Version: 3, Lease: 4},
+	})
+	require.Len(t, infos, 1)
+	assert.Equal(t, "/a", infos[0].Key)
+	assert.Equal(t, int64(5), infos[0].Size)
+	assert.Equal(t, int64(4), infos[0].Lease)
+}
+
+func TestDeleteFoundKeys(t *testing.T) {
+	mock := client.NewMockClient()
+	kvs := []*client.KeyValue{
+		{Key: "/app/a", ModRevision: 4},
+		{Key: "/app/b", ModRevision: 9},
+	}
+
+	deleted, err := deleteFoundKeys(context.Background(), mock, kvs)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	require.Len(t, mock.TxnCalls, 1)
+	assert.Equal(t, []client.TxnGuard{
+		{Key: "/app/a", ModRevision: 4},
+		{Key: "/app/b", ModRevision: 9},
+	}, mock.TxnCalls[0].Guards)
+	assert.Equal(t, []client.TxnOp{
+		{Type: client.TxnOpDelete, Key: "/app/a"},
+		{Type: client.TxnOpDelete, Key: "/app/b"},
+	}, mock.TxnCalls[0].Ops)
+}
+
+func TestDeleteFoundKeys_Conflict(t *testing.T) {
+	mock := client.NewMockClient()
+	mock.TxnFunc = func(_ context.Context, _ []client.TxnGuard, _ []client.TxnOp) (int64, error) {
+		return 0, client.ErrTxnConflict
+	}
+
+	deleted, err := deleteFoundKeys(context.Background(), mock, []*client.KeyValue{{Key: "/a", ModRevision: 1}})
+	assert.ErrorIs(t, err, client.ErrTxnConflict)
+	assert.Zero(t, deleted)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/kazuma-desu/etu/pkg/client"
 	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/glob"
 	"github.com/kazuma-desu/etu/pkg/logger"
 	"github.com/kazuma-desu/etu/pkg/models"
 	"github.com/kazuma-desu/etu/pkg/output"
@@ -34,18 +35,29 @@ var (
 		countOnly    bool
 		printValue   bool
 		showMetadata bool
+		types        bool
 	}
 
 	getCmd = &cobra.Command{
 		Use:   "get <key> [range_end]",
 		Short: "Get keys from etcd",
-		Long:  `Retrieve keys and values from etcd. Supports prefix queries, filtering, and multiple output formats.`,
+		Long: `Retrieve keys and values from etcd. Supports prefix queries, filtering, and multiple output formats.
+
+The key may be a glob pattern: '*' and '?' match within one path segment and
+'**' matches any number of segments. The longest literal prefix of the pattern
+is read from etcd and the keys are filtered locally.`,
 		Example: `  # Get a single key
   etu get /config/app/host
 
   # Get all keys with a prefix
   etu get /config/app/ --prefix
 
+  # Get the db host of every app
+  etu get '/app/*/db/host'
+
+  # Get every timeout at any depth under /svc
+  etu get '/svc/**/timeout'
+
   # Get only keys (no values)
   etu get /config/ --prefix --keys-only
 
@@ -89,6 +101,11 @@ func init() {
 		"maximum create revision")
 	getCmd.Flags().BoolVar(&getOpts.showMetadata, "show-metadata", false,
 		"show metadata (revisions, version, lease) in output")
+	getCmd.Flags().BoolVar(&getOpts.types, "preserve-types", false,
+		"with -o yaml, write values with the types recorded by 'apply --preserve-types'")
+	addLayoutFlags(getCmd)
+
+	registerKeyCompletion(getCmd, 2)
 }
 
 func runGet(_ *cobra.Command, args []string) error {
@@ -107,7 +124,12 @@ func runGet(_ *cobra.Command, args []string) error {
 	defer cancel()
 	key := args[0]
 
-	if err := validateKeyPrefix(key); err != nil {
+	// The layout splits keys for -o yaml and -o tree
+	layout, err := resolveKeyLayout(loadAppConfig())
+	if err != nil {
+		return err
+	}
+	if err := validateLayoutKey(key, layout); err != nil {
 		return err
 	}
 
@@ -116,6 +138,14 @@ func runGet(_ *cobra.Command, args []string) error {
 		getOpts.rangeEnd = args[1]
 	}
 
+	pattern, err := compileKeyPattern(key)
+	if err != nil {
+		return err
+	}
+	if pattern != nil && (getOpts.prefix || getOpts.fromKey || getOpts.rangeEnd != "") {
+		return fmt.Errorf("✗ a glob pattern cannot be combined with --prefix, --from-key or a range end")
+	}
+
 	// Connect to etcd
 	cfg, err := config.GetEtcdConfigWithContext(contextName)
 	if err != nil {
@@ -145,6 +175,16 @@ func runGet(_ *cobra.Command, args []string) error {
 		MaxCreateRev: getOpts.maxCreateRev,
 	}
 
+	// A glob reads its literal prefix; the limit and count apply to the
+	// filtered keys, so they are handled locally
+	if pattern != nil {
+		key = pattern.Prefix()
+		opts.Prefix = true
+		opts.Limit = 0
+		opts.CountOnly = false
+		opts.KeysOnly = getOpts.keysOnly || getOpts.countOnly
+	}
+
 	// Execute get
 	logger.Log.Debug("Fetching keys", "key", key, "options", opts)
 	resp, err := etcdClient.GetWithOptions(ctx, key, opts)
@@ -152,6 +192,10 @@ func runGet(_ *cobra.Command, args []string) error {
 		return err
 	}
 
+	if pattern != nil {
+		resp = matchGetResponse(resp, pattern, getOpts.limit)
+	}
+
 	// Handle count-only
 	if getOpts.countOnly {
 		fmt.Println(resp.Count)
@@ -160,7 +204,7 @@ func runGet(_ *cobra.Command, args []string) error {
 
 	// Check if no keys found
 	if len(resp.Kvs) == 0 {
-		if getOpts.prefix || getOpts.fromKey || getOpts.rangeEnd != "" {
+		if getOpts.prefix || getOpts.fromKey || getOpts.rangeEnd != "" || pattern != nil {
 			// For range queries, empty result is not an error
 			logger.Log.Debug("No keys found")
 			return nil
@@ -176,17 +220,43 @@ func runGet(_ *cobra.Command, args []string) error {
 	case output.FormatJSON.String():
 		return printJSON(resp)
 	case output.FormatYAML.String():
-		return printYAML(resp)
+		var types map[string]string
+		if getOpts.types {
+			// Range queries may cover any key, so read every recorded type
+			scope := key
+			if getOpts.fromKey || getOpts.rangeEnd != "" {
+				scope = "/"
+			}
+			if types, err = fetchTypes(ctx, etcdClient, scope); err != nil {
+				return err
+			}
+		}
+		return printYAML(resp, types, layout)
 	case output.FormatTable.String():
 		return printTable(resp)
 	case output.FormatTree.String():
-		return printTree(resp)
+		if pattern != nil {
+			return output.PrintTreeWithLayout(configPairs(resp), layout)
+		}
+		return printTree(resp, layout)
 	default:
 		// Safety net: should never reach here due to validateOutputFormat check above
 		return fmt.Errorf("invalid output format: %s (use simple, json, yaml, table, or tree)", outputFormat)
 	}
 }
 
+// matchGetResponse keeps the keys that match pattern, up to limit (0 = no
+// limit). Count is the number of matches before the limit, as in etcd.
+func matchGetResponse(resp *client.GetResponse, pattern *glob.Pattern, limit int64) *client.GetResponse {
+	kvs := filterByPattern(resp.Kvs, pattern)
+	matched := &client.GetResponse{Kvs: kvs, Count: int64(len(kvs)), Revision: resp.Revision}
+	if limit > 0 && matched.Count > limit {
+		matched.Kvs = kvs[:limit]
+		matched.More = true
+	}
+	return matched
+}
+
 func printSimple(resp *client.GetResponse) {
 	for _, kv := range resp.Kvs {
 		switch {
@@ -249,7 +319,10 @@ func printJSON(resp *client.GetResponse) error {
 	return nil
 }
 
-func printYAML(resp *client.GetResponse) error {
+// printYAML prints resp as nested YAML, splitting keys by layout. Pairs with
+// an entry in types, a map of type sidecar keys to types, are written as
+// that type.
+func printYAML(resp *client.GetResponse, types map[string]string, layout models.KeyLayout) error {
 	pairs := make([]*models.ConfigPair, 0, len(resp.Kvs))
 	var emptyValueKeys []string
 
@@ -269,7 +342,11 @@ func printYAML(resp *client.GetResponse) error {
 			len(emptyValueKeys), emptyValueKeys)
 	}
 
-	nested, err := parsers.UnflattenMap(pairs)
+	parsers.ApplyTypes(pairs, types)
+	nested, err := parsers.UnflattenMapWithOptions(pairs, &parsers.UnflattenOptions{
+		Types:  types != nil,
+		Layout: layout,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to unflatten keys: %w", err)
 	}
@@ -326,14 +403,18 @@ func printTable(resp *client.GetResponse) error {
 	return nil
 }
 
-func printTree(resp *client.GetResponse) error {
+func printTree(resp *client.GetResponse, layout models.KeyLayout) error {
 	// Tree format only makes sense with multiple keys
 	if !getOpts.prefix && !getOpts.fromKey {
 		fmt.Fprintf(os.Stderr, "Warning: 'tree' format requires --prefix or --from-key, using 'table' instead\n")
 		return printTable(resp)
 	}
 
-	// Convert GetResponse to ConfigPairs for tree rendering
+	return output.PrintTreeWithLayout(configPairs(resp), layout)
+}
+
+// configPairs converts a GetResponse to ConfigPairs for tree rendering.
+func configPairs(resp *client.GetResponse) []*models.ConfigPair {
 	pairs := make([]*models.ConfigPair, len(resp.Kvs))
 	for i, kv := range resp.Kvs {
 		pairs[i] = &models.ConfigPair{
@@ -341,6 +422,5 @@ func printTree(resp *client.GetResponse) error {
 			Value: kv.Value,
 		}
 	}
-
-	return output.PrintTree(pairs)
+	return pairs
 }
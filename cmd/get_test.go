@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/models"
 	"github.com/kazuma-desu/etu/pkg/testutil"
 )
 
@@ -207,7 +208,7 @@ func TestPrintYAML(t *testing.T) {
 	}
 
 	output, err := testutil.CaptureStdout(func() error {
-		return printYAML(resp)
+		return printYAML(resp, nil, models.KeyLayout{})
 	})
 	require.NoError(t, err)
 	assert.Contains(t, output, "app:")
@@ -217,6 +218,24 @@ func TestPrintYAML(t *testing.T) {
 	assert.NotContains(t, output, "empty:", "empty-value key should be excluded from YAML output")
 }
 
+func TestPrintYAML_Layout(t *testing.T) {
+	t.Cleanup(resetGetOpts)
+	resetGetOpts()
+	resp := &client.GetResponse{
+		Kvs: []*client.KeyValue{
+			{Key: "app.db.host", Value: "localhost"},
+			{Key: "app.name", Value: "myapp"},
+		},
+		Count: 2,
+	}
+
+	output, err := testutil.CaptureStdout(func() error {
+		return printYAML(resp, nil, models.KeyLayout{Separator: "."})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "app:\n    db:\n        host: localhost\n    name: myapp\n", output)
+}
+
 func TestPrintYAML_Error(t *testing.T) {
 	t.Cleanup(resetGetOpts)
 	resetGetOpts()
@@ -228,7 +247,7 @@ func TestPrintYAML_Error(t *testing.T) {
 		Count: 2,
 	}
 
-	err := printYAML(resp)
+	err := printYAML(resp, nil, models.KeyLayout{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to unflatten keys")
 }
@@ -248,7 +267,7 @@ func TestPrintTree(t *testing.T) {
 		getOpts.prefix = true
 		getOpts.fromKey = false
 		output, err := testutil.CaptureStdout(func() error {
-			return printTree(resp)
+			return printTree(resp, models.KeyLayout{})
 		})
 		require.NoError(t, err)
 		assert.Contains(t, output, "app")
@@ -261,10 +280,44 @@ func TestPrintTree(t *testing.T) {
 		getOpts.prefix = false
 		getOpts.fromKey = false
 		output, err := testutil.CaptureStdout(func() error {
-			return printTree(resp)
+			return printTree(resp, models.KeyLayout{})
 		})
 		require.NoError(t, err)
 		assert.Contains(t, output, "KEY")
 		assert.Contains(t, output, "VALUE")
 	})
 }
+
+func TestMatchGetResponse(t *testing.T) {
+	pattern, err := compileKeyPattern("/app/*/db/host")
+	require.NoError(t, err)
+
+	resp := &client.GetResponse{Revision: 42, Count: 4, Kvs: []*client.KeyValue{
+		{Key: "/app/api/db/host", Value: "a"},
+		{Key: "/app/api/db/port", Value: "5432"},
+		{Key: "/app/web/db/host", Value: "b"},
+		{Key: "/app/worker/db/host", Value: "c"},
+	}}
+
+	matched := matchGetResponse(resp, pattern, 0)
+	assert.Len(t, matched.Kvs, 3)
+	assert.Equal(t, int64(3), matched.Count)
+	assert.Equal(t, int64(42), matched.Revision)
+	assert.False(t, matched.More)
+
+	limited := matchGetResponse(resp, pattern, 2)
+	require.Len(t, limited.Kvs, 2)
+	assert.Equal(t, "/app/web/db/host", limited.Kvs[1].Key)
+	assert.Equal(t, int64(3), limited.Count)
+	assert.True(t, limited.More)
+}
+
+func TestRunGet_GlobWithPrefixFlag(t *testing.T) {
+	resetGetOpts()
+	defer resetGetOpts()
+	getOpts.prefix = true
+
+	err := runGet(nil, []string{"/app/*/db"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot be combined with --prefix")
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+var (
+	grepOpts struct {
+		prefix     string
+		keys       bool
+		values     bool
+		ignoreCase bool
+		regex      bool
+	}
+
+	grepCmd = &cobra.Command{
+		Use:   "grep <pattern>",
+		Short: "Search keys and values under a prefix",
+		Long: `Search the keys and values under a prefix for a pattern.
+
+The pattern is a literal string unless --regex is given. Keys and values are
+both searched by default; use --keys or --values to search only one of them.
+Values are matched line by line, so multi-line values report each matching
+line with its line number. Values that are not valid UTF-8 are skipped.
+
+Keys are read in pages, so large prefixes are searched without loading every
+value at once.`,
+		Example: `  # Find which services still point at the old database
+  etu grep old-db.internal --prefix /services
+
+  # Case-insensitive search in values only
+  etu grep -i "password" --prefix /app --values
+
+  # Regular expression search in key names
+  etu grep --regex '/v[0-9]+/' --prefix /api --keys
+
+  # Structured matches for scripting
+  etu grep old-db.internal --prefix /services -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGrep,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(grepCmd)
+
+	grepCmd.Flags().StringVar(&grepOpts.prefix, "prefix", "/",
+		"prefix of the keys to search")
+	grepCmd.Flags().BoolVar(&grepOpts.keys, "keys", false,
+		"search key names")
+	grepCmd.Flags().BoolVar(&grepOpts.values, "values", false,
+		"search values")
+	grepCmd.Flags().BoolVarP(&grepOpts.ignoreCase, "ignore-case", "i", false,
+		"ignore case when matching")
+	grepCmd.Flags().BoolVar(&grepOpts.regex, "regex", false,
+		"treat the pattern as a regular expression (Go RE2 syntax)")
+
+	registerPrefixCompletion(grepCmd, "prefix")
+}
+
+func runGrep(_ *cobra.Command, args []string) error {
+	allowedFormats := []string{
+		output.FormatSimple.String(),
+		output.FormatJSON.String(),
+		output.FormatYAML.String(),
+	}
+	if err := validateOutputFormat(allowedFormats); err != nil {
+		return err
+	}
+	if err := validateKeyPrefix(grepOpts.prefix); err != nil {
+		return err
+	}
+
+	re, err := compileGrepPattern(args[0], grepOpts.regex, grepOpts.ignoreCase)
+	if err != nil {
+		return err
+	}
+
+	// Without --keys or --values, search both
+	searchKeys, searchValues := grepOpts.keys, grepOpts.values
+	if !searchKeys && !searchValues {
+		searchKeys, searchValues = true, true
+	}
+
+	cfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+
+	etcdClient, cleanup, err := newEtcdClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := getOperationContext()
+	defer cancel()
+
+	matches, err := grepPrefix(ctx, etcdClient, grepOpts.prefix, re, searchKeys, searchValues)
+	if err != nil {
+		return wrapContextError(err)
+	}
+
+	return output.PrintGrepMatches(matches, outputFormat)
+}
+
+// compileGrepPattern builds the matcher for a grep pattern. Literal patterns
+// are quoted so regular expression metacharacters match themselves.
+func compileGrepPattern(pattern string, regex, ignoreCase bool) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("✗ pattern must not be empty")
+	}
+	if !regex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("✗ invalid regular expression: %w", err)
+	}
+	return re, nil
+}
+
+// grepPrefix reads every key under prefix page by page and returns the keys
+// whose name or value matches re, in key order.
+func grepPrefix(ctx context.Context, reader client.EtcdReader, prefix string, re *regexp.Regexp, searchKeys, searchValues bool) ([]output.GrepMatch, error) {
+	matches := []output.GrepMatch{}
+	_, err := client.ForEachPage(ctx, reader, prefix, &client.PageOptions{KeysOnly: !searchValues}, func(kvs []*client.KeyValue) error {
+		for _, kv := range kvs {
+			if m, ok := grepKeyValue(kv, re, searchKeys, searchValues); ok {
+				matches = append(matches, m)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys under %s: %w", prefix, err)
+	}
+	return matches, nil
+}
+
+// grepKeyValue matches re against the key name and each line of the value.
+func grepKeyValue(kv *client.KeyValue, re *regexp.Regexp, searchKeys, searchValues bool) (output.GrepMatch, bool) {
+	match := output.GrepMatch{Key: kv.Key}
+
+	if searchKeys {
+		if ranges, ok := grepRanges(re, kv.Key); ok {
+			match.Lines = append(match.Lines, output.GrepLine{Field: "key", Text: kv.Key, Line: 1, Ranges: ranges})
+		}
+	}
+
+	if searchValues && utf8.ValidString(kv.Value) {
+		for i, line := range strings.Split(kv.Value, "\n") {
+			if ranges, ok := grepRanges(re, line); ok {
+				match.Lines = append(match.Lines, output.GrepLine{Field: "value", Text: line, Line: i + 1, Ranges: ranges})
+			}
+		}
+	}
+
+	return match, len(match.Lines) > 0
+}
+
+// grepRanges reports whether re matches s and returns the non-empty matches
+// as byte ranges. A pattern such as ^$ matches without any range to highlight.
+func grepRanges(re *regexp.Regexp, s string) ([][2]int, bool) {
+	locs := re.FindAllStringIndex(s, -1)
+	if locs == nil {
+		return nil, false
+	}
+	var ranges [][2]int
+	for _, loc := range locs {
+		if loc[1] > loc[0] {
+			ranges = append(ranges, [2]int{loc[0], loc[1]})
+		}
+	}
+	return ranges, true
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+func TestCompileGrepPattern(t *testing.T) {
+	re, err := compileGrepPattern("old-db.internal", false, false)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("host=old-db.internal"))
+	assert.False(t, re.MatchString("host=old-dbXinternal"), "literal dot must not match any character")
+	assert.False(t, re.MatchString("OLD-DB.INTERNAL"))
+
+	re, err = compileGrepPattern("old-db.internal", false, true)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("OLD-DB.INTERNAL"))
+
+	re, err = compileGrepPattern(`v[0-9]+`, true, false)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("/api/v12/"))
+
+	_, err = compileGrepPattern("(", true, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid regular expression")
+
+	_, err = compileGrepPattern("", false, false)
+	require.Error(t, err)
+}
+
+func TestGrepKeyValue(t *testing.T) {
+	re, err := compileGrepPattern("db", false, false)
+	require.NoError(t, err)
+
+	kv := &client.KeyValue{Key: "/app/db/url", Value: "scheme: pg\nhost: db1 db2\nport: 5432"}
+
+	match, ok := grepKeyValue(kv, re, true, true)
+	require.True(t, ok)
+	assert.Equal(t, "/app/db/url", match.Key)
+	assert.Equal(t, []output.GrepLine{
+		{Field: "key", Text: "/app/db/url", Line: 1, Ranges: [][2]int{{5, 7}}},
+		{Field: "value", Text: "host: db1 db2", Line: 2, Ranges: [][2]int{{6, 8}, {10, 12}}},
+	}, match.Lines)
+
+	match, ok = grepKeyValue(kv, re, false, true)
+	require.True(t, ok)
+	require.Len(t, match.Lines, 1)
+	assert.Equal(t, "value", match.Lines[0].Field)
+
+	match, ok = grepKeyValue(kv, re, true, false)
+	require.True(t, ok)
+	require.Len(t, match.Lines, 1)
+	assert.Equal(t, "key", match.Lines[0].Field)
+
+	_, ok = grepKeyValue(&client.KeyValue{Key: "/app/name", Value: "web"}, re, true, true)
+	assert.False(t, ok)
+}
+
+func TestGrepKeyValue_SkipsBinaryValues(t *testing.T) {
+	re, err := compileGrepPattern("db", false, false)
+	require.NoError(t, err)
+
+	_, ok := grepKeyValue(&client.KeyValue{Key: "/bin", Value: "db\xff\xfe"}, re, true, true)
+	assert.False(t, ok)
+}
+
+func TestGrepKeyValue_ZeroWidthMatch(t *testing.T) {
+	re, err := compileGrepPattern("^$", true, false)
+	require.NoError(t, err)
+
+	match, ok := grepKeyValue(&client.KeyValue{Key: "/app/empty", Value: ""}, re, false, true)
+	require.True(t, ok)
+	assert.Equal(t, []output.GrepLine{{Field: "value", Text: "", Line: 1}}, match.Lines)
+}
+
+func TestGrepPrefix(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return &client.GetResponse{Kvs: []*client.KeyValue{
+				{Key: "/services/api/db", Value: "old-db.internal:5432"},
+				{Key: "/services/web/db", Value: "new-db.internal:5432"},
+				{Key: "/services/worker/db", Value: "OLD-DB.INTERNAL"},
+			}}, nil
+		},
+	}
+	re, err := compileGrepPattern("old-db.internal", false, true)
+	require.NoError(t, err)
+
+	matches, err := grepPrefix(context.Background(), mock, "/services", re, true, true)
+	require.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, "/services/api/db", matches[0].Key)
+	assert.Equal(t, "/services/worker/db", matches[1].Key)
+
+	opts := mock.GetWithOptionsCalls[0].Opts
+	assert.NotEmpty(t, opts.RangeEnd, "grep should read the prefix in pages")
+	assert.False(t, opts.KeysOnly)
+}
+
+func TestGrepPrefix_KeysOnlySkipsValues(t *testing.T) {
+	mock := client.NewMockClient()
+	mock.GetWithOptionsFunc = func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+		return &client.GetResponse{}, nil
+	}
+	re, err := compileGrepPattern("x", false, false)
+	require.NoError(t, err)
+
+	matches, err := grepPrefix(context.Background(), mock, "/", re, true, false)
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+	assert.NotNil(t, matches)
+	assert.True(t, mock.GetWithOptionsCalls[0].Opts.KeysOnly)
+}
+
+func TestGrepPrefix_ReadError(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	re, err := compileGrepPattern("x", false, false)
+	require.NoError(t, err)
+
+	_, err = grepPrefix(context.Background(), mock, "/app", re, true, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read keys under /app")
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -11,14 +12,181 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/spf13/cobra"
+
 	"github.com/kazuma-desu/etu/pkg/client"
 	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/glob"
 	"github.com/kazuma-desu/etu/pkg/logger"
 	"github.com/kazuma-desu/etu/pkg/models"
 	"github.com/kazuma-desu/etu/pkg/output"
 	"github.com/kazuma-desu/etu/pkg/parsers"
 )
 
+// flattenOpts holds the array and null handling flags of the commands that
+// parse configuration files. Its Layout is set by parseConfigReader.
+var flattenOpts parsers.FlattenOptions
+
+// layoutOpts holds the --separator and --root flags
+var layoutOpts models.KeyLayout
+
+// envKeyOpts holds the flags that map dotenv variable names to keys
+var envKeyOpts = parsers.DefaultDotenvKeyMapping
+
+// propertiesKeyOpts holds the flags that map .properties keys to keys
+var propertiesKeyOpts = parsers.DefaultPropertiesKeyMapping
+
+// addFlattenFlags registers the flags that control how YAML, JSON and TOML
+// arrays and nulls, and dotenv and .properties keys, become keys
+func addFlattenFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar((*string)(&flattenOpts.Arrays), "arrays", string(parsers.ArrayJSON),
+		"how to store arrays: json (one JSON value), indexed (/list/0, /list/1) or csv (scalar lists)")
+	cmd.Flags().BoolVar(&flattenOpts.KeepEmptyArrays, "keep-empty-arrays", false,
+		"store empty arrays as empty values instead of skipping them")
+	cmd.Flags().BoolVar(&flattenOpts.KeepNulls, "keep-nulls", false,
+		"store null values as empty values instead of skipping them")
+	cmd.Flags().BoolVar(&flattenOpts.PreserveTypes, "preserve-types", false,
+		"track value types and keep JSON numbers exact; apply records them under "+parsers.TypesPrefix)
+	addLayoutFlags(cmd)
+	addEnvKeyFlags(cmd)
+	addPropertiesKeyFlags(cmd)
+}
+
+// addEnvKeyFlags registers the flags that map dotenv variables such as
+// APP_DB_HOST to keys
+func addEnvKeyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&envKeyOpts.StripPrefix, "env-prefix", parsers.DefaultDotenvKeyMapping.StripPrefix,
+		`prefix removed from dotenv variable names, e.g. "APP_" to map APP_DB_HOST to /db/host`)
+	cmd.Flags().StringVar(&envKeyOpts.Separator, "env-separator", parsers.DefaultDotenvKeyMapping.Separator,
+		"separator that splits dotenv variable names into key segments")
+	cmd.Flags().BoolVar(&envKeyOpts.Lowercase, "env-lowercase", parsers.DefaultDotenvKeyMapping.Lowercase,
+		"lower-case dotenv key segments (--env-lowercase=false keeps APP_DB_HOST as /APP/DB/HOST)")
+}
+
+// addPropertiesKeyFlags registers the flags that map .properties keys such
+// as app.db.host to keys
+func addPropertiesKeyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&propertiesKeyOpts.StripPrefix, "properties-prefix", parsers.DefaultPropertiesKeyMapping.StripPrefix,
+		`prefix removed from .properties keys, e.g. "spring." to map spring.db.host to /db/host`)
+	cmd.Flags().StringVar(&propertiesKeyOpts.Separator, "properties-separator", parsers.DefaultPropertiesKeyMapping.Separator,
+		"separator that splits .properties keys into key segments")
+	cmd.Flags().BoolVar(&propertiesKeyOpts.Lowercase, "properties-lowercase", parsers.DefaultPropertiesKeyMapping.Lowercase,
+		"lower-case .properties key segments")
+}
+
+// templateOpts holds the variable expansion flags of the commands that parse
+// configuration files
+var templateOpts struct {
+	enabled bool
+	strict  bool
+	set     []string
+	values  []string
+}
+
+// addTemplateFlags registers the flags that expand ${VAR} references in
+// configuration files before they are parsed
+func addTemplateFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&templateOpts.enabled, "template", false,
+		"expand ${VAR} and ${VAR:-default} references from the environment before parsing")
+	cmd.Flags().StringArrayVar(&templateOpts.set, "set", nil,
+		"set a template variable as name=value (repeatable, implies --template)")
+	cmd.Flags().StringArrayVar(&templateOpts.values, "values", nil,
+		"YAML file of template variables; later files win (repeatable, implies --template)")
+	cmd.Flags().BoolVar(&templateOpts.strict, "strict-vars", false,
+		"fail on undefined template variables that have no default (implies --template)")
+}
+
+// loadTemplate builds the template set by the template flags, or returns nil
+// when templating is off. --set overrides values files, which override the
+// environment.
+func loadTemplate() (*parsers.Template, error) {
+	if !templateOpts.enabled && !templateOpts.strict && len(templateOpts.set) == 0 && len(templateOpts.values) == 0 {
+		return nil, nil
+	}
+
+	vars := make(map[string]string)
+	for _, path := range templateOpts.values {
+		values, err := parsers.LoadTemplateValues(path)
+		if err != nil {
+			return nil, fmt.Errorf("✗ failed to load values: %w", err)
+		}
+		for name, value := range values {
+			vars[name] = value
+		}
+	}
+	for _, assignment := range templateOpts.set {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok || !parsers.IsValidVariableName(name) {
+			return nil, fmt.Errorf("✗ invalid --set %q (use name=value)", assignment)
+		}
+		vars[name] = value
+	}
+
+	return &parsers.Template{Vars: vars, Strict: templateOpts.strict}, nil
+}
+
+// addLayoutFlags registers the flags that set how nested keys are joined
+func addLayoutFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&layoutOpts.Separator, "separator", "",
+		`key separator, e.g. "." for app.db.host (default "/" or the context's key-separator)`)
+	cmd.Flags().StringVar(&layoutOpts.Root, "root", "",
+		`string every key starts with (default "/" with the "/" separator, otherwise none, or the context's key-root)`)
+}
+
+// resolveKeyLayout returns the key layout set by --separator and --root,
+// falling back to the key-separator and key-root of the selected context.
+func resolveKeyLayout(appCfg *config.Config) (models.KeyLayout, error) {
+	layout := layoutOpts
+	if appCfg != nil {
+		name := contextName
+		if name == "" {
+			name = appCfg.CurrentContext
+		}
+		if ctxCfg := appCfg.Contexts[name]; ctxCfg != nil {
+			if layout.Separator == "" {
+				layout.Separator = ctxCfg.KeySeparator
+			}
+			if layout.Root == "" {
+				layout.Root = ctxCfg.KeyRoot
+			}
+		}
+	}
+	if err := layout.Validate(); err != nil {
+		return models.KeyLayout{}, fmt.Errorf("✗ %w", err)
+	}
+	return layout, nil
+}
+
+// validateLayoutKey checks that key starts with the root of layout
+func validateLayoutKey(key string, layout models.KeyLayout) error {
+	if !layout.HasRoot(key) {
+		return fmt.Errorf("✗ key must start with '%s': %s", layout.Resolved().Root, key)
+	}
+	return nil
+}
+
+// fetchTypes reads the type sidecars of the keys under prefix, keyed by
+// sidecar key
+func fetchTypes(ctx context.Context, reader client.EtcdReader, prefix string) (map[string]string, error) {
+	kvs, _, err := client.GetAllWithPrefix(ctx, reader, parsers.TypeKey(prefix), nil)
+	if err != nil {
+		return nil, wrapContextError(fmt.Errorf("failed to read value types: %w", err))
+	}
+	types := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		types[kv.Key] = kv.Value
+	}
+	return types, nil
+}
+
+// validateArrayMode checks an --arrays flag value
+func validateArrayMode(mode parsers.ArrayMode) error {
+	if !mode.IsValid() {
+		return fmt.Errorf("✗ invalid array mode %q (use json, indexed or csv)", mode)
+	}
+	return nil
+}
+
 func loadAppConfig() *config.Config {
 	appCfg, err := config.LoadConfig()
 	if err != nil {
@@ -38,16 +206,19 @@ func resolveFormat(flagFormat models.FormatType, appCfg *config.Config) models.F
 	return models.FormatAuto
 }
 
-func getParserForFile(filePath string, format models.FormatType) (parsers.Parser, models.FormatType, error) {
-	registry := parsers.NewRegistry()
+// getParserForInput picks the parser for an input stream named name (empty
+// for stdin). Auto-detection may consume the start of r, so callers must
+// parse the returned reader instead.
+func getParserForInput(name string, r io.Reader, format models.FormatType) (parsers.Parser, models.FormatType, io.Reader, error) {
+	registry := parsers.NewRegistryWithFlatten(&flattenOpts)
 
 	userExplicitFormat := format != models.FormatAuto
 
 	if format == models.FormatAuto {
 		var err error
-		format, err = registry.DetectFormat(filePath)
+		format, r, err = registry.DetectFormatReader(name, r)
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to detect format: %w", err)
+			return nil, "", nil, fmt.Errorf("failed to detect format: %w", err)
 		}
 		logger.Log.Debug("Auto-detected format", "format", format)
 	}
@@ -60,21 +231,74 @@ func getParserForFile(filePath string, format models.FormatType) (parsers.Parser
 		parser, err = registry.GetParser(format)
 	}
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, err
 	}
 
-	return parser, format, nil
+	return parser, format, r, nil
 }
 
+// parseConfigFile parses filePath, reading stdin when filePath is "-".
 func parseConfigFile(ctx context.Context, filePath string, flagFormat models.FormatType, appCfg *config.Config) ([]*models.ConfigPair, error) {
+	if filePath == "-" {
+		return parseConfigReader(ctx, "", os.Stdin, flagFormat, appCfg)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+	defer file.Close()
+
+	return parseConfigReader(ctx, filePath, file, flagFormat, appCfg)
+}
+
+// parseConfigReader parses configuration from r. name is the file name used
+// for format detection and logging, or empty for stdin.
+func parseConfigReader(ctx context.Context, name string, r io.Reader, flagFormat models.FormatType, appCfg *config.Config) ([]*models.ConfigPair, error) {
+	if err := validateArrayMode(flattenOpts.Arrays); err != nil {
+		return nil, err
+	}
+	layout, err := resolveKeyLayout(appCfg)
+	if err != nil {
+		return nil, err
+	}
+	flattenOpts.Layout = layout
+	envKeys, propertiesKeys := envKeyOpts, propertiesKeyOpts
+	flattenOpts.DotenvKeys = &envKeys
+	flattenOpts.PropertiesKeys = &propertiesKeys
+
+	source := name
+	if source == "" {
+		source = "<stdin>"
+	}
+
+	tmpl, err := loadTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if tmpl != nil {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		expanded, err := tmpl.Expand(data)
+		if err != nil {
+			parsers.SetSource(source, nil, err)
+			return nil, fmt.Errorf("failed to expand variables: %w", err)
+		}
+		r = bytes.NewReader(expanded)
+	}
+
 	format := resolveFormat(flagFormat, appCfg)
-	parser, format, err := getParserForFile(filePath, format)
+	parser, format, r, err := getParserForInput(name, r, format)
 	if err != nil {
 		return nil, err
 	}
 
-	logVerbose("Parsing configuration", "file", filePath, "format", format)
-	pairs, err := parser.Parse(ctx, filePath)
+	logVerbose("Parsing configuration", "file", source, "format", format)
+	pairs, err := parser.ParseReader(ctx, r)
+	// Positions in pairs and parse errors refer to the input by name
+	parsers.SetSource(source, pairs, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse file: %w", err)
 	}
@@ -124,6 +348,10 @@ func newEtcdClient(cfg *client.Config) (client.EtcdClient, func(), error) {
 	if overrideErr := applyGlobalOverrides(cfg); overrideErr != nil {
 		return nil, nil, overrideErr
 	}
+	if shared := activeShell.clientFor(cfg); shared != nil {
+		// The shell owns the connection and closes it on exit
+		return shared, func() {}, nil
+	}
 
 	etcdClient, err := client.NewClient(cfg)
 	if err != nil {
@@ -261,21 +489,159 @@ func validateKeyPrefix(key string) error {
 	return nil
 }
 
-func stdinToTempFile() (string, error) {
-	tmpFile, err := os.CreateTemp("", "etu-stdin-*")
+// compileKeyPattern compiles key when it contains glob metacharacters and
+// returns nil for a plain key.
+func compileKeyPattern(key string) (*glob.Pattern, error) {
+	if !glob.IsPattern(key) {
+		return nil, nil
+	}
+	pattern, err := glob.Compile(key)
+	if err != nil {
+		return nil, fmt.Errorf("✗ %w", err)
+	}
+	return pattern, nil
+}
+
+// filterByPattern returns the key-values whose key matches pattern.
+func filterByPattern(kvs []*client.KeyValue, pattern *glob.Pattern) []*client.KeyValue {
+	matched := make([]*client.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		if pattern.Match(kv.Key) {
+			matched = append(matched, kv)
+		}
+	}
+	return matched
+}
+
+// resolveContextName returns the name of the context used by this invocation:
+// the --context flag or the current context. Returns "" if neither is set.
+func resolveContextName() string {
+	if contextName != "" {
+		return contextName
+	}
+	_, name, err := config.GetCurrentContext()
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// rewriteKeys applies the rules of the --key-map file to pairs and then
+// mounts them under prefix. Either may be empty. Keys are in the layout the
+// pairs were parsed with.
+func rewriteKeys(pairs []*models.ConfigPair, keyMapFile, prefix string) error {
+	layout := flattenOpts.Layout
+	if keyMapFile != "" {
+		if !layout.IsDefault() {
+			return errors.New("✗ --key-map rules only apply to keys with the default '/' separator")
+		}
+		rules, err := parsers.LoadKeyRules(keyMapFile)
+		if err != nil {
+			return fmt.Errorf("✗ failed to load key map: %w", err)
+		}
+		if err := rules.Apply(pairs); err != nil {
+			return fmt.Errorf("✗ %w", err)
+		}
+	}
+
+	if prefix != "" {
+		if err := validateLayoutKey(prefix, layout); err != nil {
+			return err
+		}
+		parsers.MountPrefix(pairs, prefix, layout)
+	}
+	return nil
+}
+
+// contextGroup is a run of pairs that target the same etcd context
+type contextGroup struct {
+	context string
+	pairs   []*models.ConfigPair
+}
+
+// groupPairsByContext splits pairs by target context in order of first
+// appearance. Pairs without a context target defaultContext. A key set both
+// by a document without a context and by one naming defaultContext is an
+// error; keys repeated under the same context are left to the validator.
+func groupPairsByContext(pairs []*models.ConfigPair, defaultContext string) ([]contextGroup, error) {
+	var groups []contextGroup
+	index := make(map[string]int)
+	seen := make(map[[2]string]*models.ConfigPair)
+	for _, pair := range pairs {
+		name := pair.Context
+		if name == "" {
+			name = defaultContext
+		}
+		if first, ok := seen[[2]string{name, pair.Key}]; !ok {
+			seen[[2]string{name, pair.Key}] = pair
+		} else if first.Context != pair.Context {
+			return nil, duplicateKeyError(pair, first)
+		}
+
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, contextGroup{context: name})
+		}
+		groups[i].pairs = append(groups[i].pairs, pair)
+	}
+	return groups, nil
+}
+
+// duplicateKeyError reports pair as redefining the key of first
+func duplicateKeyError(pair, first *models.ConfigPair) error {
+	message := "duplicate key " + pair.Key
+	if pair.Pos.IsValid() {
+		message = pair.Pos.String() + ": " + message
+	}
+	if first.Pos.IsValid() {
+		message += " (first defined at " + first.Pos.String() + ")"
+	}
+	return errors.New("✗ " + message)
+}
+
+// resolveContextGroups groups pairs by the context they are written to,
+// with pairs that name no context going to the selected one
+func resolveContextGroups(pairs []*models.ConfigPair) ([]contextGroup, error) {
+	return groupPairsByContext(pairs, resolveContextName())
+}
+
+// requireSingleContext returns an error if any pair targets a context other
+// than the one selected for the command.
+func requireSingleContext(pairs []*models.ConfigPair) error {
+	current := resolveContextName()
+	groups, err := groupPairsByContext(pairs, current)
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
+		return err
 	}
+	for _, group := range groups {
+		if group.context != current {
+			return fmt.Errorf("✗ %s targets context %q; select it with --context to use this command", group.pairs[0].Key, group.context)
+		}
+	}
+	return nil
+}
 
-	if _, err := io.Copy(tmpFile, os.Stdin); err != nil {
-		tmpFile.Close()
-		os.Remove(tmpFile.Name())
-		return "", fmt.Errorf("failed to write stdin to temp file: %w", err)
+// dryRunViewOps converts recorded client operations to their display form.
+func dryRunViewOps(ops []client.Operation) []output.DryRunOperation {
+	viewOps := make([]output.DryRunOperation, len(ops))
+	for i, op := range ops {
+		viewOps[i] = output.DryRunOperation{
+			Type:  op.Type,
+			Key:   op.Key,
+			Value: op.Value,
+		}
 	}
+	return viewOps
+}
 
-	if err := tmpFile.Close(); err != nil {
-		os.Remove(tmpFile.Name())
-		return "", fmt.Errorf("failed to close temp file: %w", err)
+// readConfirmation reads a single line answer and reports whether it was "y" or "yes".
+func readConfirmation(in io.Reader) bool {
+	scanner := bufio.NewScanner(in)
+	if scanner.Scan() {
+		response := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		return response == "y" || response == "yes"
 	}
-	return tmpFile.Name(), nil
+	return false
 }
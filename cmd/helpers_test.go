@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -488,7 +489,7 @@ func TestLoadAppConfig_WithValidConfig(t *testing.T) {
 	}
 }
 
-func TestGetParserForFile(t *testing.T) {
+func TestGetParserForInput(t *testing.T) {
 	tests := []struct {
 		name       string
 		filePath   string
@@ -524,27 +525,64 @@ func TestGetParserForFile(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			parser, format, err := getParserForFile(tt.filePath, tt.format)
+			parser, format, _, err := getParserForInput(tt.filePath, strings.NewReader(""), tt.format)
 			if tt.wantErr {
 				if err == nil {
-					t.Errorf("getParserForFile() error = nil, want error")
+					t.Errorf("getParserForInput() error = nil, want error")
 				}
 				return
 			}
 			if err != nil {
-				t.Errorf("getParserForFile() error = %v, want nil", err)
+				t.Errorf("getParserForInput() error = %v, want nil", err)
 				return
 			}
 			if parser == nil {
-				t.Error("getParserForFile() parser = nil, want non-nil")
+				t.Error("getParserForInput() parser = nil, want non-nil")
 			}
 			if format != tt.wantFormat {
-				t.Errorf("getParserForFile() format = %v, want %v", format, tt.wantFormat)
+				t.Errorf("getParserForInput() format = %v, want %v", format, tt.wantFormat)
 			}
 		})
 	}
 }
 
+func TestParseConfigFile_Stdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	go func() {
+		defer w.Close()
+		w.WriteString(`{"app": {"name": "myapp"}}`)
+	}()
+
+	oldStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = oldStdin }()
+
+	pairs, err := parseConfigFile(context.Background(), "-", models.FormatAuto, nil)
+	require.NoError(t, err)
+	require.Len(t, pairs, 1)
+	assert.Equal(t, "/app/name", pairs[0].Key)
+	assert.Equal(t, "myapp", pairs[0].Value)
+	assert.Equal(t, "<stdin>:1:10", pairs[0].Pos.String())
+}
+
+func TestParseConfigFile_ErrorPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  name: x\n  bad: : y\n"), 0o600))
+
+	_, err := parseConfigFile(context.Background(), path, models.FormatAuto, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path+":3: failed to parse YAML: mapping values are not allowed")
+}
+
+func TestParseConfigFile_NotFound(t *testing.T) {
+	_, err := parseConfigFile(context.Background(), "/nonexistent/config.yaml", models.FormatAuto, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse file")
+}
+
 func TestLogVerbose(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -682,32 +720,271 @@ func TestValidateKeyPrefix(t *testing.T) {
 	}
 }
 
-func TestStdinToTempFile(t *testing.T) {
-	t.Run("success", func(t *testing.T) {
-		// Create a pipe to simulate stdin
-		r, w, err := os.Pipe()
-		require.NoError(t, err)
-		defer r.Close()
+func TestCompileKeyPattern(t *testing.T) {
+	pattern, err := compileKeyPattern("/app/db/host")
+	require.NoError(t, err)
+	assert.Nil(t, pattern)
+
+	pattern, err = compileKeyPattern("/app/*/db/host")
+	require.NoError(t, err)
+	require.NotNil(t, pattern)
+	assert.Equal(t, "/app/", pattern.Prefix())
+
+	_, err = compileKeyPattern("/app/[a-")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "✗ invalid glob pattern")
+}
+
+func TestFilterByPattern(t *testing.T) {
+	pattern, err := compileKeyPattern("/svc/**/timeout")
+	require.NoError(t, err)
+
+	kvs := []*client.KeyValue{
+		{Key: "/svc/timeout"},
+		{Key: "/svc/api/retries"},
+		{Key: "/svc/api/http/timeout"},
+	}
+	matched := filterByPattern(kvs, pattern)
+	require.Len(t, matched, 2)
+	assert.Equal(t, "/svc/timeout", matched[0].Key)
+	assert.Equal(t, "/svc/api/http/timeout", matched[1].Key)
+}
+
+func TestGroupPairsByContext(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/a", Value: "1"},
+		{Key: "/b", Value: "2", Context: "prod"},
+		{Key: "/c", Value: "3", Context: "dev"},
+		{Key: "/d", Value: "4", Context: "prod"},
+	}
+
+	groups, err := groupPairsByContext(pairs, "dev")
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "dev", groups[0].context)
+	assert.Equal(t, []*models.ConfigPair{pairs[0], pairs[2]}, groups[0].pairs)
+	assert.Equal(t, "prod", groups[1].context)
+	assert.Equal(t, []*models.ConfigPair{pairs[1], pairs[3]}, groups[1].pairs)
+
+	groups, err = groupPairsByContext(nil, "")
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+}
+
+func TestGroupPairsByContext_Duplicates(t *testing.T) {
+	untagged := &models.ConfigPair{Key: "/db/host", Value: "a", Pos: models.Position{File: "app.yaml", Line: 2, Column: 9}}
+	tagged := &models.ConfigPair{Key: "/db/host", Value: "b", Context: "default", Pos: models.Position{File: "app.yaml", Line: 7, Column: 9}}
+
+	// The untagged document targets the selected context
+	_, err := groupPairsByContext([]*models.ConfigPair{untagged, tagged}, "default")
+	assert.EqualError(t, err, "✗ app.yaml:7:9: duplicate key /db/host (first defined at app.yaml:2:9)")
+
+	groups, err := groupPairsByContext([]*models.ConfigPair{untagged, tagged}, "dev")
+	require.NoError(t, err)
+	assert.Len(t, groups, 2)
+
+	_, err = groupPairsByContext([]*models.ConfigPair{{Key: "/a"}, {Key: "/a"}, {Key: "/a", Context: "dev"}}, "dev")
+	assert.EqualError(t, err, "✗ duplicate key /a")
+
+	// Repeats within one context are reported by the validator
+	groups, err = groupPairsByContext([]*models.ConfigPair{{Key: "/a"}, {Key: "/a"}}, "")
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	assert.Len(t, groups[0].pairs, 2)
+}
 
-		// Write test data
-		go func() {
-			defer w.Close()
-			w.WriteString("test content")
-		}()
+func TestRequireSingleContext(t *testing.T) {
+	oldContext := contextName
+	defer func() { contextName = oldContext }()
+	contextName = "prod"
 
-		// Redirect stdin
-		oldStdin := os.Stdin
-		os.Stdin = r
-		defer func() { os.Stdin = oldStdin }()
+	assert.NoError(t, requireSingleContext([]*models.ConfigPair{
+		{Key: "/a", Value: "1"},
+		{Key: "/b", Value: "2", Context: "prod"},
+	}))
 
-		// Call function
-		path, err := stdinToTempFile()
-		require.NoError(t, err)
-		defer os.Remove(path)
+	err := requireSingleContext([]*models.ConfigPair{{Key: "/c", Value: "3", Context: "dev"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `/c targets context "dev"`)
 
-		// Verify file exists and has content
-		content, err := os.ReadFile(path)
-		require.NoError(t, err)
-		assert.Equal(t, "test content", string(content))
+	err = requireSingleContext([]*models.ConfigPair{
+		{Key: "/a", Value: "1"},
+		{Key: "/a", Value: "2", Context: "prod"},
 	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "duplicate key /a")
+}
+
+func TestRewriteKeys(t *testing.T) {
+	keyMap := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(keyMap, []byte("rules:\n  - strip: /dev\n"), 0644))
+
+	pairs := []*models.ConfigPair{
+		{Key: "/dev/db/host", Value: "localhost"},
+		{Key: "/name", Value: "api"},
+	}
+	require.NoError(t, rewriteKeys(pairs, keyMap, "/services/api"))
+	assert.Equal(t, "/services/api/db/host", pairs[0].Key)
+	assert.Equal(t, "/services/api/name", pairs[1].Key)
+
+	err := rewriteKeys(pairs, "", "services")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "✗ key must start with '/'")
+
+	err = rewriteKeys(pairs, filepath.Join(t.TempDir(), "missing.yaml"), "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "✗ failed to load key map")
+}
+
+func TestResolveKeyLayout(t *testing.T) {
+	t.Cleanup(func() {
+		layoutOpts = models.KeyLayout{}
+		contextName = ""
+	})
+
+	appCfg := &config.Config{
+		CurrentContext: "dev",
+		Contexts: map[string]*config.ContextConfig{
+			"dev":  {KeySeparator: ".", KeyRoot: "cfg."},
+			"prod": {KeySeparator: ":"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		flags    models.KeyLayout
+		context  string
+		appCfg   *config.Config
+		expected models.KeyLayout
+	}{
+		{"default", models.KeyLayout{}, "", nil, models.KeyLayout{}},
+		{"current context", models.KeyLayout{}, "", appCfg, models.KeyLayout{Separator: ".", Root: "cfg."}},
+		{"--context", models.KeyLayout{}, "prod", appCfg, models.KeyLayout{Separator: ":"}},
+		{"flags override the context", models.KeyLayout{Separator: "_"}, "", appCfg, models.KeyLayout{Separator: "_", Root: "cfg."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layoutOpts = tt.flags
+			contextName = tt.context
+			layout, err := resolveKeyLayout(tt.appCfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, layout)
+		})
+	}
+
+	layoutOpts = models.KeyLayout{Separator: " "}
+	_, err := resolveKeyLayout(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "✗ key separator must not contain whitespace")
+}
+
+func TestRewriteKeys_Layout(t *testing.T) {
+	t.Cleanup(func() { flattenOpts.Layout = models.KeyLayout{} })
+	flattenOpts.Layout = models.KeyLayout{Separator: ":", Root: "config:"}
+
+	pairs := []*models.ConfigPair{{Key: "config:db:host", Value: "localhost"}}
+	require.NoError(t, rewriteKeys(pairs, "", "config:services:api"))
+	assert.Equal(t, "config:services:api:db:host", pairs[0].Key)
+
+	err := rewriteKeys(pairs, "", "/services")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "✗ key must start with 'config:'")
+
+	keyMap := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(keyMap, []byte("rules:\n  - strip: /dev\n"), 0644))
+	err = rewriteKeys(pairs, keyMap, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "default '/' separator")
+}
+
+func TestFetchTypes(t *testing.T) {
+	var requested string
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, key string, _ *client.GetOptions) (*client.GetResponse, error) {
+			requested = key
+			return &client.GetResponse{Kvs: []*client.KeyValue{
+				{Key: ".etu/types/app/port", Value: "int:0123456789abcdef"},
+			}}, nil
+		},
+	}
+
+	types, err := fetchTypes(context.Background(), mock, "/app")
+	require.NoError(t, err)
+	assert.Equal(t, ".etu/types/app", requested)
+	assert.Equal(t, map[string]string{".etu/types/app/port": "int:0123456789abcdef"}, types)
+
+	mock.GetWithOptionsFunc = func(context.Context, string, *client.GetOptions) (*client.GetResponse, error) {
+		return nil, errors.New("unavailable")
+	}
+	_, err = fetchTypes(context.Background(), mock, "/app")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read value types")
+}
+
+func TestParseConfigFile_Template(t *testing.T) {
+	t.Cleanup(func() {
+		templateOpts.enabled, templateOpts.strict, templateOpts.set, templateOpts.values = false, false, nil, nil
+	})
+	t.Setenv("ETU_TEST_ENV", "prod")
+	t.Setenv("ETU_TEST_PORT", "5432")
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(
+		"app:\n  env: ${ETU_TEST_ENV}\n  port: ${ETU_TEST_PORT}\n  host: ${db.host}\n  level: ${LEVEL:-info}\n  raw: $${HOME}\n"), 0o600))
+	values := filepath.Join(dir, "values.yaml")
+	require.NoError(t, os.WriteFile(values, []byte("db:\n  host: db.internal\nETU_TEST_PORT: \"6543\"\n"), 0o600))
+
+	// Without templating, references are kept as written
+	pairs, err := parseConfigFile(context.Background(), path, models.FormatAuto, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "${ETU_TEST_ENV}", pairs[0].Value)
+
+	templateOpts.values = []string{values}
+	templateOpts.set = []string{"LEVEL=debug"}
+	pairs, err = parseConfigFile(context.Background(), path, models.FormatAuto, nil)
+	require.NoError(t, err)
+
+	got := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		got[pair.Key] = pair.Value
+	}
+	assert.Equal(t, map[string]string{
+		"/app/env":   "prod",
+		"/app/port":  "6543",
+		"/app/host":  "db.internal",
+		"/app/level": "debug",
+		"/app/raw":   "${HOME}",
+	}, got)
+}
+
+func TestParseConfigFile_TemplateErrors(t *testing.T) {
+	t.Cleanup(func() {
+		templateOpts.enabled, templateOpts.strict, templateOpts.set, templateOpts.values = false, false, nil, nil
+	})
+
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  host: \"${ETU_TEST_UNDEFINED}\"\n"), 0o600))
+
+	templateOpts.enabled = true
+	pairs, err := parseConfigFile(context.Background(), path, models.FormatAuto, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "", pairs[0].Value)
+
+	// --strict-vars turns templating on by itself
+	templateOpts.enabled, templateOpts.strict = false, true
+	_, err = parseConfigFile(context.Background(), path, models.FormatAuto, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to expand variables: "+path+":2:10: undefined variable ETU_TEST_UNDEFINED")
+
+	templateOpts.set = []string{"no-equals"}
+	_, err = parseConfigFile(context.Background(), path, models.FormatAuto, nil)
+	assert.EqualError(t, err, `✗ invalid --set "no-equals" (use name=value)`)
+
+	templateOpts.set = nil
+	templateOpts.values = []string{filepath.Join(t.TempDir(), "missing.yaml")}
+	_, err = parseConfigFile(context.Background(), path, models.FormatAuto, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "✗ failed to load values")
 }
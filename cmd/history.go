@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+var (
+	historyOpts struct {
+		limit int
+	}
+
+	historyCmd = &cobra.Command{
+		Use:   "history <key>",
+		Short: "Show previous values of a key",
+		Long: `Walk backwards through the revisions of a key and show each version with a diff
+against the version before it.
+
+The walk stops at the key's creation, at --limit versions, or at the cluster's
+compaction boundary, since etcd cannot serve reads from compacted revisions.`,
+		Example: `  # Show the last 10 versions of a key
+  etu history /config/app/database/host
+
+  # Show the full history still retained by etcd
+  etu history /config/app/database/host --limit 0
+
+  # JSON output for scripting
+  etu history /config/app/database/host -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runHistory,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().IntVar(&historyOpts.limit, "limit", 10,
+		"maximum number of versions to show (0 for no limit)")
+
+	registerKeyCompletion(historyCmd, 1)
+}
+
+func runHistory(_ *cobra.Command, args []string) error {
+	allowedFormats := []string{
+		output.FormatSimple.String(),
+		output.FormatJSON.String(),
+		output.FormatYAML.String(),
+		output.FormatTable.String(),
+	}
+	if err := validateOutputFormat(allowedFormats); err != nil {
+		return err
+	}
+
+	key := args[0]
+	if err := validateKeyPrefix(key); err != nil {
+		return err
+	}
+	if historyOpts.limit < 0 {
+		return fmt.Errorf("✗ --limit must be 0 or greater")
+	}
+
+	cfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+
+	etcdClient, cleanup, err := newEtcdClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := getOperationContext()
+	defer cancel()
+
+	logVerbose("Fetching key history", "key", key, "limit", historyOpts.limit)
+	view, err := fetchKeyHistory(ctx, etcdClient, key, historyOpts.limit)
+	if err != nil {
+		return wrapContextError(err)
+	}
+
+	return output.PrintHistory(view, outputFormat)
+}
+
+// fetchKeyHistory reads the current version of a key and then each previous
+// version by reading at the revision just before it was last modified.
+// A limit of 0 walks back until the key's creation or the compaction boundary.
+func fetchKeyHistory(ctx context.Context, reader client.EtcdReader, key string, limit int) (*output.HistoryView, error) {
+	resp, err := reader.GetWithOptions(ctx, key, &client.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, fmt.Errorf("✗ key not found: %s\n\nHint: Check the key path or use 'etu ls' to list available keys", key)
+	}
+
+	view := &output.HistoryView{Key: key}
+	kv := resp.Kvs[0]
+	for {
+		view.Entries = append(view.Entries, output.HistoryEntry{
+			Value:          kv.Value,
+			Revision:       kv.ModRevision,
+			CreateRevision: kv.CreateRevision,
+			Version:        kv.Version,
+		})
+
+		if kv.Version <= 1 {
+			return view, nil
+		}
+		if limit > 0 && len(view.Entries) >= limit {
+			view.Truncated = true
+			return view, nil
+		}
+
+		resp, err = reader.GetWithOptions(ctx, key, &client.GetOptions{Revision: kv.ModRevision - 1})
+		if errors.Is(err, client.ErrCompacted) {
+			view.Compacted = true
+			return view, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Kvs) == 0 {
+			return view, nil
+		}
+		kv = resp.Kvs[0]
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+)
+
+// historyMock serves versions of a single key; versions[i] has ModRevision revs[i].
+func historyMock(values []string, revs []int64, compactRev int64) *client.MockClient {
+	return &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, key string, opts *client.GetOptions) (*client.GetResponse, error) {
+			rev := opts.Revision
+			if rev == 0 {
+				rev = revs[len(revs)-1]
+			}
+			if rev < compactRev {
+				return nil, fmt.Errorf("failed to get key %s: %w", key, client.ErrCompacted)
+			}
+			for i := len(revs) - 1; i >= 0; i-- {
+				if revs[i] <= rev {
+					return &client.GetResponse{Kvs: []*client.KeyValue{{
+						Key:            key,
+						Value:          values[i],
+						CreateRevision: revs[0],
+						ModRevision:    revs[i],
+						Version:        int64(i + 1),
+					}}, Count: 1}, nil
+				}
+			}
+			return &client.GetResponse{Kvs: []*client.KeyValue{}}, nil
+		},
+	}
+}
+
+func TestFetchKeyHistory_FullHistory(t *testing.T) {
+	mock := historyMock([]string{"v1", "v2", "v3"}, []int64{5, 9, 20}, 0)
+
+	view, err := fetchKeyHistory(context.Background(), mock, "/app/key", 0)
+	require.NoError(t, err)
+
+	require.Len(t, view.Entries, 3)
+	assert.Equal(t, "v3", view.Entries[0].Value)
+	assert.Equal(t, int64(20), view.Entries[0].Revision)
+	assert.Equal(t, "v1", view.Entries[2].Value)
+	assert.Equal(t, int64(1), view.Entries[2].Version)
+	assert.False(t, view.Compacted)
+	assert.False(t, view.Truncated)
+
+	// Each previous version is read just before the newer one was written
+	require.Len(t, mock.GetWithOptionsCalls, 3)
+	assert.Equal(t, int64(19), mock.GetWithOptionsCalls[1].Opts.Revision)
+	assert.Equal(t, int64(8), mock.GetWithOptionsCalls[2].Opts.Revision)
+}
+
+func TestFetchKeyHistory_Limit(t *testing.T) {
+	mock := historyMock([]string{"v1", "v2", "v3"}, []int64{5, 9, 20}, 0)
+
+	view, err := fetchKeyHistory(context.Background(), mock, "/app/key", 2)
+	require.NoError(t, err)
+
+	require.Len(t, view.Entries, 2)
+	assert.True(t, view.Truncated)
+	assert.Len(t, mock.GetWithOptionsCalls, 2)
+}
+
+func TestFetchKeyHistory_StopsAtCompaction(t *testing.T) {
+	mock := historyMock([]string{"v1", "v2", "v3"}, []int64{5, 9, 20}, 10)
+
+	view, err := fetchKeyHistory(context.Background(), mock, "/app/key", 0)
+	require.NoError(t, err)
+
+	require.Len(t, view.Entries, 2)
+	assert.Equal(t, "v2", view.Entries[1].Value)
+	assert.True(t, view.Compacted)
+}
+
+func TestFetchKeyHistory_KeyNotFound(t *testing.T) {
+	mock := client.NewMockClient()
+
+	_, err := fetchKeyHistory(context.Background(), mock, "/missing", 10)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "key not found")
+}
+
+func TestFetchKeyHistory_ReadError(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, opts *client.GetOptions) (*client.GetResponse, error) {
+			if opts.Revision != 0 {
+				return nil, errors.New("connection lost")
+			}
+			return &client.GetResponse{Kvs: []*client.KeyValue{{
+				Key: "/app/key", Value: "v2", ModRevision: 9, Version: 2,
+			}}}, nil
+		},
+	}
+
+	_, err := fetchKeyHistory(context.Background(), mock, "/app/key", 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection lost")
+}
+
+func TestHistoryCommand_Flags(t *testing.T) {
+	flag := historyCmd.Flags().Lookup("limit")
+	require.NotNil(t, flag)
+	assert.Equal(t, "10", flag.DefValue)
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -15,23 +16,39 @@ import (
 
 var (
 	lsOpts struct {
-		prefix bool
+		prefix    bool
+		recursive bool
+		long      bool
 	}
 
 	lsCmd = &cobra.Command{
 		Use:   "ls <prefix>",
 		Short: "List keys from etcd",
-		Long: `List keys from etcd with prefix matching.
+		Long: `List the keys under a prefix like a directory.
 
-This is a simplified command for listing keys, equivalent to 'get --prefix --keys-only'.`,
-		Example: `  # List all keys
+Only the immediate children of the prefix are shown. Deeper keys are collapsed
+into one entry per subtree, shown as 'name/' with the number of keys below it.
+The prefix is treated as a directory, so 'etu ls /app' lists '/app/...'.
+Subtrees are skipped rather than read, so listing '/' stays fast on large
+clusters.
+
+Use -R to list every key under the prefix instead, and -l for a long format
+with size, version, mod revision and lease. The prefix may also be a glob
+pattern such as '/app/*/db' or '/svc/**/timeout', which lists the matching keys.`,
+		Example: `  # List the top level
   etu ls /
 
-  # List keys under /app
+  # List the children of /app
   etu ls /app
 
-  # List keys under /app/config (same as above, prefix is default)
-  etu ls /app/config/
+  # Long format with size, version, mod revision and lease
+  etu ls -l /app
+
+  # Every key under /app, like 'get --prefix --keys-only'
+  etu ls -R /app
+
+  # List keys matching a glob
+  etu ls '/app/*/db/*'
 
   # JSON output for scripting
   etu ls /app -o json
@@ -47,7 +64,13 @@ func init() {
 	rootCmd.AddCommand(lsCmd)
 
 	lsCmd.Flags().BoolVar(&lsOpts.prefix, "prefix", true,
-		"list keys with matching prefix (default true)")
+		"list keys under the prefix; --prefix=false lists only the exact key")
+	lsCmd.Flags().BoolVarP(&lsOpts.recursive, "recursive", "R", false,
+		"list every key under the prefix instead of immediate children")
+	lsCmd.Flags().BoolVarP(&lsOpts.long, "long", "l", false,
+		"show size, version, mod revision and lease")
+
+	registerKeyCompletion(lsCmd, 1)
 }
 
 func runLs(_ *cobra.Command, args []string) error {
@@ -69,6 +92,11 @@ func runLs(_ *cobra.Command, args []string) error {
 		return err
 	}
 
+	pattern, err := compileKeyPattern(prefix)
+	if err != nil {
+		return err
+	}
+
 	cfg, err := config.GetEtcdConfigWithContext(contextName)
 	if err != nil {
 		return wrapNotConnectedError(err)
@@ -80,17 +108,83 @@ func runLs(_ *cobra.Command, args []string) error {
 	}
 	defer cleanup()
 
-	opts := &client.GetOptions{
-		Prefix:   lsOpts.prefix,
-		KeysOnly: true,
+	// Sizes need values; everything else is available from a keys-only read
+	pageOpts := &client.PageOptions{KeysOnly: !lsOpts.long}
+	dir := lsDir(prefix)
+
+	switch {
+	case pattern != nil:
+		logger.Log.Debug("Listing keys", "pattern", pattern.String())
+		kvs, _, err := client.GetAllWithPrefix(ctx, etcdClient, pattern.Prefix(), pageOpts)
+		if err != nil {
+			return wrapContextError(err)
+		}
+		return printLsKeys(filterByPattern(kvs, pattern))
+
+	case !lsOpts.prefix:
+		logger.Log.Debug("Listing key", "key", prefix)
+		resp, err := etcdClient.GetWithOptions(ctx, prefix, &client.GetOptions{KeysOnly: !lsOpts.long})
+		if err != nil {
+			return wrapContextError(err)
+		}
+		return printLsKeys(resp.Kvs)
+
+	case lsOpts.recursive:
+		logger.Log.Debug("Listing keys", "prefix", dir, "recursive", true)
+		kvs, _, err := client.GetAllWithPrefix(ctx, etcdClient, dir, pageOpts)
+		if err != nil {
+			return wrapContextError(err)
+		}
+		return printLsKeys(kvs)
 	}
 
-	logger.Log.Debug("Listing keys", "prefix", prefix, "options", opts)
-	resp, err := etcdClient.GetWithOptions(ctx, prefix, opts)
+	logger.Log.Debug("Listing children", "prefix", dir)
+	children, err := client.ListChildren(ctx, etcdClient, dir, pageOpts)
 	if err != nil {
-		return err
+		return wrapContextError(err)
+	}
+	return output.PrintListing(listEntries(dir, children), outputFormat, lsOpts.long)
+}
+
+// lsDir returns prefix as a directory, with a trailing '/'.
+func lsDir(prefix string) string {
+	if strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
+
+// listEntries converts the children of dir to listing entries.
+func listEntries(dir string, children []client.Child) []output.ListEntry {
+	entries := make([]output.ListEntry, len(children))
+	for i, c := range children {
+		entry := output.ListEntry{Name: c.Name, Keys: c.Keys, Dir: c.Dir}
+		if entry.Name == "" && !c.Dir {
+			// A key stored at the directory path itself
+			entry.Name = "."
+		}
+		if c.Dir {
+			entry.Info.Key = c.Path(dir)
+		} else {
+			entry.Info = keyInfos([]*client.KeyValue{c.KV})[0]
+		}
+		entries[i] = entry
+	}
+	return entries
+}
+
+// printLsKeys prints a flat list of full keys, either in the classic
+// keys-only formats or, with -l, as a long listing.
+func printLsKeys(kvs []*client.KeyValue) error {
+	if lsOpts.long {
+		entries := make([]output.ListEntry, len(kvs))
+		for i, info := range keyInfos(kvs) {
+			entries[i] = output.ListEntry{Name: info.Key, Info: info}
+		}
+		return output.PrintListing(entries, outputFormat, true)
 	}
 
+	resp := &client.GetResponse{Kvs: kvs, Count: int64(len(kvs))}
 	switch outputFormat {
 	case output.FormatSimple.String():
 		printLsSimple(resp)
@@ -14,6 +14,8 @@ import (
 
 func resetLsOpts() {
 	lsOpts.prefix = true
+	lsOpts.recursive = false
+	lsOpts.long = false
 }
 
 func TestPrintLsSimple(t *testing.T) {
@@ -236,3 +238,51 @@ func TestPrintLsYAML_Empty(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, output, "keys: []")
 }
+
+func TestLsDir(t *testing.T) {
+	assert.Equal(t, "/", lsDir("/"))
+	assert.Equal(t, "/app/", lsDir("/app"))
+	assert.Equal(t, "/app/", lsDir("/app/"))
+}
+
+func TestListEntries(t *testing.T) {
+	children := []client.Child{
+		{KV: &client.KeyValue{Key: "/app/"}, Name: ""},
+		{Name: "config", Keys: 2, Dir: true},
+		{KV: &client.KeyValue{Key: "/app/name", Value: "demo", ModRevision: 4, Version: 2}, Name: "name"},
+	}
+
+	entries := listEntries("/app/", children)
+	require.Len(t, entries, 3)
+	assert.Equal(t, ".", entries[0].Name)
+	assert.Equal(t, "/app/config/", entries[1].Info.Key)
+	assert.Equal(t, int64(2), entries[1].Keys)
+	assert.True(t, entries[1].Dir)
+	assert.Equal(t, "/app/name", entries[2].Info.Key)
+	assert.Equal(t, int64(4), entries[2].Info.Size)
+	assert.Equal(t, int64(4), entries[2].Info.ModRevision)
+}
+
+func TestPrintLsKeys(t *testing.T) {
+	t.Cleanup(resetLsOpts)
+	resetLsOpts()
+	kvs := []*client.KeyValue{
+		{Key: "/app/a/b", Value: "xy", ModRevision: 3, Version: 1},
+		{Key: "/app/c", ModRevision: 5, Version: 2},
+	}
+
+	out, err := testutil.CaptureStdout(func() error {
+		return printLsKeys(kvs)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/app/a/b\n/app/c\n", out)
+
+	lsOpts.long = true
+	out, err = testutil.CaptureStdout(func() error {
+		return printLsKeys(kvs)
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "MOD REV")
+	assert.Contains(t, out, "2 B")
+	assert.Contains(t, out, "/app/a/b")
+}
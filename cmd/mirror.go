@@ -0,0 +1,529 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+var (
+	mirrorOpts struct {
+		from           string
+		to             string
+		prefix         string
+		destPrefix     string
+		checkpoint     string
+		resync         bool
+		reportInterval time.Duration
+	}
+
+	mirrorCmd = &cobra.Command{
+		Use:   "mirror --from <context> --to <context> --prefix <prefix>",
+		Short: "Continuously replicate a prefix from one context to another",
+		Long: `Continuously replicate all keys under a prefix from one context to another.
+
+The mirror starts with a consistent copy of the prefix read at a single
+revision, then watches the source from the next revision and replays every
+put and delete on the destination. Keys are written under --dest-prefix when
+given, otherwise under the same prefix.
+
+The last applied revision is saved to a checkpoint file so a restarted mirror
+resumes where it stopped instead of copying everything again. If the source
+has compacted past the checkpoint, the prefix is copied again and destination
+keys that no longer exist in the source are removed.
+
+Lag metrics are reported periodically: the last applied revision, the source
+revision, and the number of source keys changed since the last applied revision.
+Press Ctrl+C to stop.`,
+		Example: `  # Mirror /shared from the primary cluster to a regional one
+  etu mirror --from primary --to eu-west --prefix /shared
+
+  # Write the keys under a different prefix on the destination
+  etu mirror --from primary --to eu-west --prefix /shared --dest-prefix /replica/shared
+
+  # Ignore the checkpoint and start with a fresh copy
+  etu mirror --from primary --to eu-west --prefix /shared --resync
+
+  # Emit lag metrics as JSON lines every 30 seconds
+  etu mirror --from primary --to eu-west --prefix /shared --report-interval 30s -o json`,
+		Args: cobra.NoArgs,
+		RunE: runMirror,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(mirrorCmd)
+
+	mirrorCmd.Flags().StringVar(&mirrorOpts.from, "from", "",
+		"context to replicate from (required)")
+	mirrorCmd.Flags().StringVar(&mirrorOpts.to, "to", "",
+		"context to replicate to (required)")
+	mirrorCmd.Flags().StringVar(&mirrorOpts.prefix, "prefix", "",
+		"prefix of the keys to replicate (required)")
+	mirrorCmd.Flags().StringVar(&mirrorOpts.destPrefix, "dest-prefix", "",
+		"write keys under this prefix on the destination instead of --prefix")
+	mirrorCmd.Flags().StringVar(&mirrorOpts.checkpoint, "checkpoint", "",
+		"checkpoint file (default: a file in the user cache directory)")
+	mirrorCmd.Flags().BoolVar(&mirrorOpts.resync, "resync", false,
+		"ignore the checkpoint and start with a fresh copy")
+	mirrorCmd.Flags().DurationVar(&mirrorOpts.reportInterval, "report-interval", 10*time.Second,
+		"how often to report lag metrics (0 = never)")
+
+	_ = mirrorCmd.MarkFlagRequired("from")
+	_ = mirrorCmd.MarkFlagRequired("to")
+	_ = mirrorCmd.MarkFlagRequired("prefix")
+
+	registerContextCompletion(mirrorCmd, "from")
+	registerContextCompletion(mirrorCmd, "to")
+}
+
+// mirrorCheckpoint is the on-disk record of how far a mirror has progressed.
+type mirrorCheckpoint struct {
+	UpdatedAt  time.Time `json:"updated_at"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Prefix     string    `json:"prefix"`
+	DestPrefix string    `json:"dest_prefix"`
+	Revision   int64     `json:"revision"`
+}
+
+// mirrorLag is a snapshot of replication progress.
+type mirrorLag struct {
+	LastEventAt     *time.Time `json:"last_event_at,omitempty"`
+	AppliedRevision int64      `json:"applied_revision"`
+	SourceRevision  int64      `json:"source_revision"`
+	RevisionLag     int64      `json:"revision_lag"`
+	PendingKeys     int64      `json:"pending_keys"`
+	Puts            int        `json:"puts"`
+	Deletes         int        `json:"deletes"`
+}
+
+// mirrorSession replicates one prefix from a source to a destination.
+type mirrorSession struct {
+	source         client.EtcdReader
+	dest           client.EtcdClient
+	checkpoint     *mirrorCheckpoint
+	lastEventAt    time.Time
+	prefix         string
+	destPrefix     string
+	checkpointPath string
+	puts           int
+	deletes        int
+}
+
+func runMirror(_ *cobra.Command, _ []string) error {
+	allowedFormats := []string{
+		output.FormatSimple.String(),
+		output.FormatJSON.String(),
+	}
+	if err := validateOutputFormat(allowedFormats); err != nil {
+		return err
+	}
+	if err := validateMirrorOptions(); err != nil {
+		return err
+	}
+	if globalPasswordStdin {
+		return fmt.Errorf("✗ --password-stdin is not supported by mirror: set credentials in each context")
+	}
+
+	destPrefix := mirrorOpts.destPrefix
+	if destPrefix == "" {
+		destPrefix = mirrorOpts.prefix
+	}
+
+	checkpointPath := mirrorOpts.checkpoint
+	if checkpointPath == "" {
+		var err error
+		checkpointPath, err = defaultMirrorCheckpointPath(mirrorOpts.from, mirrorOpts.to, mirrorOpts.prefix, destPrefix)
+		if err != nil {
+			return fmt.Errorf("✗ %w (use --checkpoint to choose a file)", err)
+		}
+	}
+
+	checkpoint := &mirrorCheckpoint{
+		From:       mirrorOpts.from,
+		To:         mirrorOpts.to,
+		Prefix:     mirrorOpts.prefix,
+		DestPrefix: destPrefix,
+	}
+	if !mirrorOpts.resync {
+		saved, err := loadMirrorCheckpoint(checkpointPath)
+		if err != nil {
+			return fmt.Errorf("✗ %w", err)
+		}
+		if saved != nil {
+			if !saved.matches(checkpoint) {
+				return fmt.Errorf("✗ checkpoint %s belongs to a different mirror (%s %s → %s %s); use --checkpoint or --resync",
+					checkpointPath, saved.From, saved.Prefix, saved.To, saved.DestPrefix)
+			}
+			checkpoint = saved
+		}
+	}
+
+	sourceCfg, err := config.GetEtcdConfigWithContext(mirrorOpts.from)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+	destCfg, err := config.GetEtcdConfigWithContext(mirrorOpts.to)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+
+	source, cleanupSource, err := newEtcdClient(sourceCfg)
+	if err != nil {
+		return err
+	}
+	defer cleanupSource()
+
+	dest, cleanupDest, err := newEtcdClient(destCfg)
+	if err != nil {
+		return err
+	}
+	defer cleanupDest()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(sigChan)
+		select {
+		case <-sigChan:
+			output.Info("Stopping mirror...")
+			cancel()
+		case <-ctx.Done():
+			// Context canceled elsewhere, exit cleanly
+		}
+	}()
+
+	session := &mirrorSession{
+		source:         source,
+		dest:           dest,
+		prefix:         mirrorOpts.prefix,
+		destPrefix:     destPrefix,
+		checkpoint:     checkpoint,
+		checkpointPath: checkpointPath,
+	}
+
+	if checkpoint.Revision > 0 {
+		output.Info(fmt.Sprintf("Resuming mirror of %s from revision %d (checkpoint %s)",
+			session.prefix, checkpoint.Revision+1, checkpointPath))
+	} else {
+		count, err := session.syncSnapshot(ctx, false)
+		if err != nil {
+			return wrapContextError(err)
+		}
+		output.Success(fmt.Sprintf("Copied %d keys from %s to %s at revision %d",
+			count, session.prefix, session.destPrefix, checkpoint.Revision))
+	}
+
+	output.Info(fmt.Sprintf("Mirroring %s (%s) → %s (%s)", session.prefix, mirrorOpts.from, session.destPrefix, mirrorOpts.to))
+	if outputFormat != output.FormatJSON.String() {
+		fmt.Println("Press Ctrl+C to stop")
+		fmt.Println()
+	}
+
+	if err := session.follow(ctx, mirrorOpts.reportInterval, printMirrorLag); err != nil {
+		return wrapContextError(err)
+	}
+	return nil
+}
+
+func validateMirrorOptions() error {
+	if err := validateKeyPrefix(mirrorOpts.prefix); err != nil {
+		return err
+	}
+	if mirrorOpts.destPrefix != "" {
+		if err := validateKeyPrefix(mirrorOpts.destPrefix); err != nil {
+			return err
+		}
+	}
+	if mirrorOpts.reportInterval < 0 {
+		return fmt.Errorf("✗ invalid --report-interval: must be non-negative")
+	}
+
+	destPrefix := mirrorOpts.destPrefix
+	if destPrefix == "" {
+		destPrefix = mirrorOpts.prefix
+	}
+	if mirrorOpts.from == mirrorOpts.to &&
+		(strings.HasPrefix(destPrefix, mirrorOpts.prefix) || strings.HasPrefix(mirrorOpts.prefix, destPrefix)) {
+		return fmt.Errorf("✗ --prefix %s and --dest-prefix %s overlap in the same context; the mirror would replay its own writes",
+			mirrorOpts.prefix, destPrefix)
+	}
+	return nil
+}
+
+// destKey maps a source key to its key on the destination.
+func (s *mirrorSession) destKey(key string) string {
+	if s.destPrefix == s.prefix {
+		return key
+	}
+	return rewriteKeyPrefix(key, s.prefix, s.destPrefix)
+}
+
+// syncSnapshot copies every key under the prefix, read at a single revision,
+// to the destination and checkpoints that revision. With prune set, keys under
+// the destination prefix that are not in the snapshot are deleted. It returns
+// the number of keys copied.
+func (s *mirrorSession) syncSnapshot(ctx context.Context, prune bool) (int, error) {
+	copied := make(map[string]bool)
+	revision, err := client.ForEachPage(ctx, s.source, s.prefix, nil, func(kvs []*client.KeyValue) error {
+		pairs := make([]*models.ConfigPair, len(kvs))
+		for i, kv := range kvs {
+			key := s.destKey(kv.Key)
+			pairs[i] = &models.ConfigPair{Key: key, Value: kv.Value}
+			copied[key] = true
+		}
+		if _, err := s.dest.PutAllWithOptions(ctx, pairs, nil, client.DefaultBatchOptions()); err != nil {
+			return fmt.Errorf("failed to copy keys: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", s.prefix, err)
+	}
+
+	if prune {
+		var stale []string
+		_, err := client.ForEachPage(ctx, s.dest, s.destPrefix, &client.PageOptions{KeysOnly: true}, func(kvs []*client.KeyValue) error {
+			for _, kv := range kvs {
+				if !copied[kv.Key] {
+					stale = append(stale, kv.Key)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to read destination keys: %w", err)
+		}
+		for _, key := range stale {
+			if _, err := s.dest.Delete(ctx, key); err != nil {
+				return 0, fmt.Errorf("failed to delete %s: %w", key, err)
+			}
+		}
+		if len(stale) > 0 {
+			output.Info(fmt.Sprintf("Removed %d destination keys no longer in the source", len(stale)))
+		}
+	}
+
+	if err := s.saveCheckpoint(revision); err != nil {
+		return 0, err
+	}
+	return len(copied), nil
+}
+
+// applyEvents replays watch events on the destination in order and then
+// checkpoints the revision of the last event.
+func (s *mirrorSession) applyEvents(ctx context.Context, events []client.WatchEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	for _, ev := range events {
+		key := s.destKey(ev.Key)
+		switch ev.Type {
+		case client.WatchEventPut:
+			if err := s.dest.Put(ctx, key, ev.Value); err != nil {
+				return fmt.Errorf("failed to put %s: %w", key, err)
+			}
+			s.puts++
+		case client.WatchEventDelete:
+			if _, err := s.dest.Delete(ctx, key); err != nil {
+				return fmt.Errorf("failed to delete %s: %w", key, err)
+			}
+			s.deletes++
+		}
+	}
+	s.lastEventAt = time.Now()
+	return s.saveCheckpoint(events[len(events)-1].Revision)
+}
+
+// follow watches the source from the revision after the checkpoint and
+// replays events until ctx is canceled. If the source has compacted past the
+// checkpoint, the prefix is copied again and the watch restarts. Every
+// interval, report is called with the current lag.
+func (s *mirrorSession) follow(ctx context.Context, interval time.Duration, report func(mirrorLag)) error {
+	var ticks <-chan time.Time
+	if interval > 0 && report != nil {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for {
+		watchCtx, cancelWatch := context.WithCancel(ctx)
+		watchChan := s.source.Watch(watchCtx, s.prefix, &client.WatchOptions{
+			Prefix:   true,
+			Revision: s.checkpoint.Revision + 1,
+		})
+
+		compacted, err := s.consume(ctx, watchChan, ticks, report)
+		cancelWatch()
+		if err != nil || !compacted {
+			return err
+		}
+
+		output.Warning(fmt.Sprintf("Source compacted past revision %d; copying %s again", s.checkpoint.Revision, s.prefix))
+		if _, err := s.syncSnapshot(ctx, true); err != nil {
+			return err
+		}
+	}
+}
+
+// consume applies responses from watchChan until the channel closes or ctx is
+// canceled. It reports whether the watch ended because of compaction.
+func (s *mirrorSession) consume(ctx context.Context, watchChan client.WatchChan, ticks <-chan time.Time, report func(mirrorLag)) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-ticks:
+			lag, err := s.lag(ctx)
+			if err != nil {
+				logVerbose("Could not read source revision", "error", err)
+				continue
+			}
+			report(lag)
+		case resp, ok := <-watchChan:
+			if !ok {
+				if ctx.Err() != nil {
+					return false, nil
+				}
+				return false, fmt.Errorf("watch on %s closed unexpectedly", s.prefix)
+			}
+			if resp.CompactRevision > 0 || errors.Is(resp.Err, client.ErrCompacted) {
+				return true, nil
+			}
+			if resp.Err != nil {
+				return false, fmt.Errorf("watch error: %w", resp.Err)
+			}
+			if err := s.applyEvents(ctx, resp.Events); err != nil {
+				return false, err
+			}
+		}
+	}
+}
+
+// lag compares the checkpoint with the source. RevisionLag counts revisions
+// across the whole source keyspace, so it is an upper bound; PendingKeys
+// counts keys under the prefix modified after the last applied revision.
+func (s *mirrorSession) lag(ctx context.Context) (mirrorLag, error) {
+	applied := s.checkpoint.Revision
+	resp, err := s.source.GetWithOptions(ctx, s.prefix, &client.GetOptions{
+		Prefix:    true,
+		CountOnly: true,
+		MinModRev: applied + 1,
+	})
+	if err != nil {
+		return mirrorLag{}, err
+	}
+
+	lag := mirrorLag{
+		AppliedRevision: applied,
+		SourceRevision:  resp.Revision,
+		RevisionLag:     max(resp.Revision-applied, 0),
+		PendingKeys:     resp.Count,
+		Puts:            s.puts,
+		Deletes:         s.deletes,
+	}
+	if !s.lastEventAt.IsZero() {
+		at := s.lastEventAt.UTC()
+		lag.LastEventAt = &at
+	}
+	return lag, nil
+}
+
+func (s *mirrorSession) saveCheckpoint(revision int64) error {
+	s.checkpoint.Revision = revision
+	s.checkpoint.UpdatedAt = time.Now().UTC()
+	if err := saveMirrorCheckpoint(s.checkpointPath, s.checkpoint); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+func printMirrorLag(lag mirrorLag) {
+	if outputFormat == output.FormatJSON.String() {
+		data, err := json.Marshal(lag)
+		if err != nil {
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	msg := fmt.Sprintf("Applied revision %d, source revision %d (%d pending keys, %d puts, %d deletes)",
+		lag.AppliedRevision, lag.SourceRevision, lag.PendingKeys, lag.Puts, lag.Deletes)
+	if lag.LastEventAt != nil {
+		msg += fmt.Sprintf(", last event %s ago", time.Since(*lag.LastEventAt).Round(time.Second))
+	}
+	output.Info(msg)
+}
+
+func (c *mirrorCheckpoint) matches(other *mirrorCheckpoint) bool {
+	return c.From == other.From && c.To == other.To &&
+		c.Prefix == other.Prefix && c.DestPrefix == other.DestPrefix
+}
+
+// defaultMirrorCheckpointPath returns a checkpoint file in the user cache
+// directory, named after a hash of the mirror's source and destination.
+func defaultMirrorCheckpointPath(from, to, prefix, destPrefix string) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{from, to, prefix, destPrefix}, "\x00")))
+	return filepath.Join(cacheDir, "etu", "mirror", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// loadMirrorCheckpoint reads a checkpoint file. It returns nil if the file
+// does not exist.
+func loadMirrorCheckpoint(path string) (*mirrorCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var checkpoint mirrorCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	return &checkpoint, nil
+}
+
+// saveMirrorCheckpoint writes the checkpoint through a temporary file so a
+// crash never leaves a partially written checkpoint behind.
+func saveMirrorCheckpoint(path string, checkpoint *mirrorCheckpoint) error {
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/testutil"
+)
+
+func newTestMirrorSession(t *testing.T, source client.EtcdReader, dest client.EtcdClient, destPrefix string) *mirrorSession {
+	t.Helper()
+	return &mirrorSession{
+		source:         source,
+		dest:           dest,
+		prefix:         "/shared",
+		destPrefix:     destPrefix,
+		checkpoint:     &mirrorCheckpoint{From: "a", To: "b", Prefix: "/shared", DestPrefix: destPrefix},
+		checkpointPath: filepath.Join(t.TempDir(), "mirror.json"),
+	}
+}
+
+// watchOnce returns a WatchFunc that sends the given responses and then keeps
+// the channel open until the watch context is canceled.
+func watchOnce(responses ...client.WatchResponse) func(context.Context, string, *client.WatchOptions) client.WatchChan {
+	return func(ctx context.Context, _ string, _ *client.WatchOptions) client.WatchChan {
+		ch := make(chan client.WatchResponse)
+		go func() {
+			defer close(ch)
+			for _, resp := range responses {
+				select {
+				case ch <- resp:
+				case <-ctx.Done():
+					return
+				}
+			}
+			<-ctx.Done()
+		}()
+		return ch
+	}
+}
+
+func TestMirrorSession_SyncSnapshot(t *testing.T) {
+	source := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return &client.GetResponse{
+				Revision: 50,
+				Kvs: []*client.KeyValue{
+					{Key: "/shared/a", Value: "1"},
+					{Key: "/shared/b/c", Value: ""},
+				},
+			}, nil
+		},
+	}
+	dest := client.NewMockClient()
+	session := newTestMirrorSession(t, source, dest, "/replica")
+
+	count, err := session.syncSnapshot(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	require.Len(t, dest.PutAllWithProgressCalls, 1)
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "/replica/a", Value: "1"},
+		{Key: "/replica/b/c", Value: ""},
+	}, dest.PutAllWithProgressCalls[0].Pairs)
+	assert.Empty(t, dest.DeleteCalls)
+
+	saved, err := loadMirrorCheckpoint(session.checkpointPath)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, int64(50), saved.Revision)
+	assert.Equal(t, "/replica", saved.DestPrefix)
+}
+
+func TestMirrorSession_SyncSnapshotPrunesStaleKeys(t *testing.T) {
+	source := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return &client.GetResponse{Revision: 80, Kvs: []*client.KeyValue{{Key: "/shared/a", Value: "1"}}}, nil
+		},
+	}
+	dest := client.NewMockClient()
+	dest.GetWithOptionsFunc = func(_ context.Context, _ string, opts *client.GetOptions) (*client.GetResponse, error) {
+		assert.True(t, opts.KeysOnly)
+		return &client.GetResponse{Kvs: []*client.KeyValue{{Key: "/shared/a"}, {Key: "/shared/gone"}}}, nil
+	}
+	session := newTestMirrorSession(t, source, dest, "/shared")
+
+	_, err := session.syncSnapshot(context.Background(), true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/shared/gone"}, dest.DeleteCalls)
+	assert.Equal(t, int64(80), session.checkpoint.Revision)
+}
+
+func TestMirrorSession_SyncSnapshotWriteError(t *testing.T) {
+	source := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return &client.GetResponse{Revision: 5, Kvs: []*client.KeyValue{{Key: "/shared/a", Value: "1"}}}, nil
+		},
+	}
+	dest := client.NewMockClient()
+	dest.PutAllWithOptionsFunc = func(_ context.Context, _ []*models.ConfigPair, _ client.ProgressFunc, _ *client.BatchOptions) (*client.PutAllResult, error) {
+		return nil, errors.New("permission denied")
+	}
+	session := newTestMirrorSession(t, source, dest, "/shared")
+
+	_, err := session.syncSnapshot(context.Background(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+	assert.Zero(t, session.checkpoint.Revision, "checkpoint must not advance on failure")
+}
+
+func TestMirrorSession_ApplyEvents(t *testing.T) {
+	dest := client.NewMockClient()
+	session := newTestMirrorSession(t, client.NewMockClient(), dest, "/replica")
+
+	err := session.applyEvents(context.Background(), []client.WatchEvent{
+		{Type: client.WatchEventPut, Key: "/shared/a", Value: "2", Revision: 61},
+		{Type: client.WatchEventDelete, Key: "/shared/b", Revision: 62},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []client.PutCall{{Key: "/replica/a", Value: "2"}}, dest.PutCalls)
+	assert.Equal(t, []string{"/replica/b"}, dest.DeleteCalls)
+	assert.Equal(t, 1, session.puts)
+	assert.Equal(t, 1, session.deletes)
+
+	saved, err := loadMirrorCheckpoint(session.checkpointPath)
+	require.NoError(t, err)
+	assert.Equal(t, int64(62), saved.Revision)
+}
+
+func TestMirrorSession_ApplyEventsError(t *testing.T) {
+	dest := client.NewMockClient()
+	dest.PutFunc = func(_ context.Context, _, _ string) error {
+		return errors.New("no leader")
+	}
+	session := newTestMirrorSession(t, client.NewMockClient(), dest, "/shared")
+	session.checkpoint.Revision = 60
+
+	err := session.applyEvents(context.Background(), []client.WatchEvent{
+		{Type: client.WatchEventPut, Key: "/shared/a", Value: "2", Revision: 61},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to put /shared/a")
+	assert.Equal(t, int64(60), session.checkpoint.Revision)
+}
+
+func TestMirrorSession_FollowReplaysFromCheckpoint(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source := client.NewMockClient()
+	source.WatchFunc = watchOnce(client.WatchResponse{Events: []client.WatchEvent{
+		{Type: client.WatchEventPut, Key: "/shared/a", Value: "new", Revision: 43},
+	}})
+	dest := client.NewMockClient()
+	dest.PutFunc = func(_ context.Context, _, _ string) error {
+		cancel()
+		return nil
+	}
+	session := newTestMirrorSession(t, source, dest, "/shared")
+	session.checkpoint.Revision = 42
+
+	require.NoError(t, session.follow(ctx, 0, nil))
+
+	require.Len(t, source.WatchCalls, 1)
+	assert.Equal(t, "/shared", source.WatchCalls[0].Key)
+	assert.True(t, source.WatchCalls[0].Opts.Prefix)
+	assert.Equal(t, int64(43), source.WatchCalls[0].Opts.Revision)
+	assert.Equal(t, []client.PutCall{{Key: "/shared/a", Value: "new"}}, dest.PutCalls)
+	assert.Equal(t, int64(43), session.checkpoint.Revision)
+}
+
+func TestMirrorSession_FollowRecopiesAfterCompaction(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source := client.NewMockClient()
+	source.GetWithOptionsFunc = func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+		return &client.GetResponse{Revision: 200, Kvs: []*client.KeyValue{{Key: "/shared/a", Value: "1"}}}, nil
+	}
+	compacted := watchOnce(client.WatchResponse{CompactRevision: 150})
+	source.WatchFunc = func(watchCtx context.Context, key string, opts *client.WatchOptions) client.WatchChan {
+		if len(source.WatchCalls) == 1 {
+			return compacted(watchCtx, key, opts)
+		}
+		cancel()
+		return watchOnce()(watchCtx, key, opts)
+	}
+	dest := client.NewMockClient()
+	session := newTestMirrorSession(t, source, dest, "/shared")
+	session.checkpoint.Revision = 10
+
+	require.NoError(t, session.follow(ctx, 0, nil))
+
+	require.Len(t, source.WatchCalls, 2)
+	assert.Equal(t, int64(11), source.WatchCalls[0].Opts.Revision)
+	assert.Equal(t, int64(201), source.WatchCalls[1].Opts.Revision)
+	require.Len(t, dest.PutAllWithProgressCalls, 1)
+	assert.Equal(t, int64(200), session.checkpoint.Revision)
+}
+
+func TestMirrorSession_FollowWatchError(t *testing.T) {
+	source := client.NewMockClient()
+	source.WatchFunc = watchOnce(client.WatchResponse{Err: errors.New("permission denied")})
+	session := newTestMirrorSession(t, source, client.NewMockClient(), "/shared")
+
+	err := session.follow(context.Background(), 0, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "watch error: permission denied")
+}
+
+func TestMirrorSession_FollowWatchClosed(t *testing.T) {
+	session := newTestMirrorSession(t, client.NewMockClient(), client.NewMockClient(), "/shared")
+
+	err := session.follow(context.Background(), 0, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "closed unexpectedly")
+}
+
+func TestMirrorSession_Lag(t *testing.T) {
+	source := client.NewMockClient()
+	source.GetWithOptionsFunc = func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+		return &client.GetResponse{Revision: 120, Count: 3}, nil
+	}
+	session := newTestMirrorSession(t, source, client.NewMockClient(), "/shared")
+	session.checkpoint.Revision = 100
+	session.puts = 4
+	session.deletes = 1
+
+	lag, err := session.lag(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, source.GetWithOptionsCalls, 1)
+	opts := source.GetWithOptionsCalls[0].Opts
+	assert.True(t, opts.Prefix)
+	assert.True(t, opts.CountOnly)
+	assert.Equal(t, int64(101), opts.MinModRev)
+
+	assert.Equal(t, mirrorLag{
+		AppliedRevision: 100,
+		SourceRevision:  120,
+		RevisionLag:     20,
+		PendingKeys:     3,
+		Puts:            4,
+		Deletes:         1,
+	}, lag)
+}
+
+func TestMirrorSession_FollowReportsLag(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	source := client.NewMockClient()
+	source.WatchFunc = watchOnce()
+	source.GetWithOptionsFunc = func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+		return &client.GetResponse{Revision: 7}, nil
+	}
+	session := newTestMirrorSession(t, source, client.NewMockClient(), "/shared")
+	session.checkpoint.Revision = 7
+
+	var reports []mirrorLag
+	err := session.follow(ctx, time.Millisecond, func(lag mirrorLag) {
+		reports = append(reports, lag)
+		cancel()
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, reports)
+	assert.Equal(t, int64(0), reports[0].RevisionLag)
+}
+
+func TestPrintMirrorLag_JSON(t *testing.T) {
+	oldFormat := outputFormat
+	outputFormat = "json"
+	t.Cleanup(func() { outputFormat = oldFormat })
+
+	out, err := testutil.CaptureStdout(func() error {
+		printMirrorLag(mirrorLag{AppliedRevision: 5, SourceRevision: 9, RevisionLag: 4, PendingKeys: 1})
+		return nil
+	})
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+	assert.Equal(t, float64(5), decoded["applied_revision"])
+	assert.Equal(t, float64(4), decoded["revision_lag"])
+	assert.NotContains(t, decoded, "last_event_at")
+}
+
+func TestMirrorCheckpoint_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "cp.json")
+
+	missing, err := loadMirrorCheckpoint(path)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	checkpoint := &mirrorCheckpoint{From: "a", To: "b", Prefix: "/x", DestPrefix: "/y", Revision: 9}
+	require.NoError(t, saveMirrorCheckpoint(path, checkpoint))
+
+	loaded, err := loadMirrorCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, checkpoint, loaded)
+	assert.True(t, loaded.matches(&mirrorCheckpoint{From: "a", To: "b", Prefix: "/x", DestPrefix: "/y"}))
+	assert.False(t, loaded.matches(&mirrorCheckpoint{From: "a", To: "c", Prefix: "/x", DestPrefix: "/y"}))
+}
+
+func TestDefaultMirrorCheckpointPath(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	first, err := defaultMirrorCheckpointPath("a", "b", "/x", "/x")
+	require.NoError(t, err)
+	again, err := defaultMirrorCheckpointPath("a", "b", "/x", "/x")
+	require.NoError(t, err)
+	other, err := defaultMirrorCheckpointPath("a", "c", "/x", "/x")
+	require.NoError(t, err)
+
+	assert.Equal(t, first, again)
+	assert.NotEqual(t, first, other)
+	assert.Contains(t, first, filepath.Join("etu", "mirror"))
+}
+
+func TestValidateMirrorOptions(t *testing.T) {
+	originalOpts := mirrorOpts
+	defer func() { mirrorOpts = originalOpts }()
+
+	tests := []struct {
+		name       string
+		from       string
+		to         string
+		prefix     string
+		destPrefix string
+		wantErr    string
+	}{
+		{name: "different contexts", from: "a", to: "b", prefix: "/shared"},
+		{name: "same context, disjoint prefixes", from: "a", to: "a", prefix: "/shared", destPrefix: "/copy"},
+		{name: "same context, same prefix", from: "a", to: "a", prefix: "/shared", wantErr: "overlap"},
+		{name: "same context, nested dest", from: "a", to: "a", prefix: "/shared", destPrefix: "/shared/copy", wantErr: "overlap"},
+		{name: "relative prefix", from: "a", to: "b", prefix: "shared", wantErr: "must start with '/'"},
+		{name: "relative dest prefix", from: "a", to: "b", prefix: "/shared", destPrefix: "copy", wantErr: "must start with '/'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mirrorOpts = originalOpts
+			mirrorOpts.from = tt.from
+			mirrorOpts.to = tt.to
+			mirrorOpts.prefix = tt.prefix
+			mirrorOpts.destPrefix = tt.destPrefix
+
+			err := validateMirrorOptions()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
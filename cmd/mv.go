@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	mvOpts relocateOptions
+
+	mvCmd = &cobra.Command{
+		Use:   "mv <src> <dst>",
+		Short: "Move or rename a key or a prefix",
+		Long: `Move a key, or all keys under a prefix with --prefix, to another location.
+
+Within a cluster, each destination write and source delete happen in the same
+transaction. The move is atomic when it fits in one transaction (up to 64 keys)
+and runs in batches of transactions otherwise. Every transaction is guarded by
+the revisions read beforehand, so a concurrent write to a source or destination
+key aborts it and leaves both keys untouched.
+
+With --to-context the keys are first copied to the other context and then
+deleted from the source; source keys changed in between are kept.
+
+Existing destination keys are not replaced unless --overwrite is given.`,
+		Example: `  # Rename a key
+  etu mv /app/config/host /app/config/hostname
+
+  # Move a subtree
+  etu mv /app/old /app/new --prefix
+
+  # Move a subtree to another context
+  etu mv /app /app --prefix --context old-cluster --to-context new-cluster
+
+  # Preview the writes
+  etu mv /app/old /app/new --prefix --dry-run`,
+		Args: cobra.ExactArgs(2),
+		RunE: runMv,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(mvCmd)
+	addRelocateFlags(mvCmd, &mvOpts, "move")
+	registerKeyCompletion(mvCmd, 2)
+}
+
+func runMv(_ *cobra.Command, args []string) error {
+	return runRelocate(args[0], args[1], &mvOpts, true)
+}
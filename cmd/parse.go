@@ -22,6 +22,12 @@ var (
   # Tree view
   etu parse -f config.txt -o tree
 
+  # Preview keys mounted under a prefix
+  etu parse -f service.yaml --prefix /services/api -o tree
+
+  # Join keys with dots: app.db.host
+  etu parse -f service.yaml --separator . -o tree
+
   # JSON output for scripting
   etu parse -f config.txt -o json`,
 		RunE: runParse,
@@ -32,15 +38,24 @@ func init() {
 	rootCmd.AddCommand(parseCmd)
 
 	parseCmd.Flags().StringVarP(&parseOpts.FilePath, "file", "f", "",
-		"path to configuration file (required)")
+		"path to configuration file or '-' for stdin (required)")
 	parseCmd.Flags().StringVar((*string)(&parseOpts.Format), "format", "",
-		"file format: auto, etcdctl (overrides config)")
+		"file format: auto, etcdctl, yaml, json, toml, dotenv, properties (overrides config)")
+	parseCmd.Flags().StringVar(&parseOpts.Prefix, "prefix", "",
+		"mount the file's keys under this prefix")
+	parseCmd.Flags().StringVar(&parseOpts.KeyMap, "key-map", "",
+		"YAML file of rules that rewrite keys")
+
+	addFlattenFlags(parseCmd)
+	addTemplateFlags(parseCmd)
 
 	if err := parseCmd.MarkFlagRequired("file"); err != nil {
 		panic(fmt.Sprintf("failed to mark flag as required: %v", err))
 	}
 
 	registerFileCompletion(parseCmd, "file")
+	registerFileCompletion(parseCmd, "key-map")
+	registerPrefixCompletion(parseCmd, "prefix")
 }
 
 func runParse(_ *cobra.Command, _ []string) error {
@@ -64,6 +79,12 @@ func runParse(_ *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
+	if err := rewriteKeys(pairs, parseOpts.KeyMap, parseOpts.Prefix); err != nil {
+		return err
+	}
+	if _, err := resolveContextGroups(pairs); err != nil {
+		return err
+	}
 
-	return output.PrintConfigPairsWithFormat(pairs, outputFormat)
+	return output.PrintConfigPairsWithLayout(pairs, outputFormat, flattenOpts.Layout)
 }
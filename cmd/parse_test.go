@@ -7,6 +7,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/parsers"
+	"github.com/kazuma-desu/etu/pkg/testutil"
 )
 
 func TestParseCommand(t *testing.T) {
@@ -144,4 +148,169 @@ true
 		err = runParse(parseCmd, []string{})
 		assert.NoError(t, err)
 	})
+
+	t.Run("Parse with prefix and key map", func(t *testing.T) {
+		tempDir := t.TempDir()
+		configFile := filepath.Join(tempDir, "service.yaml")
+		keyMap := filepath.Join(tempDir, "keys.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte("legacy:\n  db:\n    host: localhost\n"), 0644))
+		require.NoError(t, os.WriteFile(keyMap, []byte("rules:\n  - strip: /legacy\n"), 0644))
+
+		parseOpts.FilePath = configFile
+		parseOpts.Format = "yaml"
+		parseOpts.Prefix = "/services/api"
+		parseOpts.KeyMap = keyMap
+		outputFormat = "simple"
+		defer func() {
+			parseOpts.Prefix = ""
+			parseOpts.KeyMap = ""
+		}()
+
+		out, err := testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "/services/api/db/host")
+		assert.NotContains(t, out, "legacy")
+	})
+
+	t.Run("Parse rejects a key set for the selected context twice", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		content := "db:\n  host: a\n---\n$etu:\n  context: default\ndb:\n  host: b\n"
+		require.NoError(t, os.WriteFile(configFile, []byte(content), 0644))
+
+		parseOpts.FilePath = configFile
+		parseOpts.Format = "yaml"
+		outputFormat = "simple"
+		oldContext := contextName
+		defer func() { contextName = oldContext }()
+
+		contextName = "default"
+		err := runParse(parseCmd, []string{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), configFile+":7:3: duplicate key /db/host (first defined at "+configFile+":2:3)")
+
+		contextName = "dev"
+		_, err = testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Parse with array modes", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(configFile, []byte(`{"hosts": ["a", "b"]}`), 0644))
+
+		parseOpts.FilePath = configFile
+		parseOpts.Format = "json"
+		outputFormat = "simple"
+		defer func() { flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON} }()
+
+		flattenOpts.Arrays = parsers.ArrayIndexed
+		out, err := testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "/hosts/1")
+
+		flattenOpts.Arrays = "yaml"
+		err = runParse(parseCmd, []string{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid array mode "yaml"`)
+	})
+
+	t.Run("Parse with a custom separator", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte("app:\n  db:\n    host: localhost\n"), 0644))
+
+		parseOpts.FilePath = configFile
+		parseOpts.Format = "yaml"
+		outputFormat = "tree"
+		layoutOpts.Separator = "."
+		defer func() {
+			layoutOpts = models.KeyLayout{}
+			flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON}
+			outputFormat = "simple"
+		}()
+
+		out, err := testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "app.")
+		assert.Contains(t, out, "db.")
+		assert.Contains(t, out, "host localhost")
+		assert.NotContains(t, out, "app/")
+
+		outputFormat = "simple"
+		out, err = testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "app.db.host")
+
+		outputFormat = "yaml"
+		out, err = testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "app:\n    db:\n        host: localhost\n")
+	})
+
+	t.Run("Parse dotenv with a key mapping", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), ".env")
+		require.NoError(t, os.WriteFile(configFile, []byte("APP_DB__MAX_CONNS=10\n"), 0644))
+
+		parseOpts.FilePath = configFile
+		parseOpts.Format = "dotenv"
+		outputFormat = "simple"
+		defer func() {
+			envKeyOpts = parsers.DefaultDotenvKeyMapping
+			flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON}
+		}()
+
+		out, err := testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "/app/db/max/conns")
+
+		envKeyOpts.StripPrefix = "APP_"
+		envKeyOpts.Separator = "__"
+		out, err = testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "/db/max_conns")
+		assert.NotContains(t, out, "/app/")
+
+		envKeyOpts.Lowercase = false
+		out, err = testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "/DB/MAX_CONNS")
+	})
+
+	t.Run("Parse properties with a key mapping", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "app.properties")
+		require.NoError(t, os.WriteFile(configFile, []byte("spring.DB.host=localhost\n"), 0644))
+
+		parseOpts.FilePath = configFile
+		parseOpts.Format = "properties"
+		outputFormat = "simple"
+		defer func() {
+			propertiesKeyOpts = parsers.DefaultPropertiesKeyMapping
+			flattenOpts = parsers.FlattenOptions{Arrays: parsers.ArrayJSON}
+		}()
+
+		propertiesKeyOpts.StripPrefix = "spring."
+		propertiesKeyOpts.Lowercase = true
+		out, err := testutil.CaptureStdout(func() error {
+			return runParse(parseCmd, []string{})
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "/db/host")
+		assert.NotContains(t, out, "spring")
+	})
 }
@@ -52,6 +52,8 @@ func init() {
 		"preview the operation without writing to etcd")
 	putCmd.Flags().BoolVar(&putOpts.validate, "validate", false,
 		"validate key and value before writing")
+
+	registerKeyCompletion(putCmd, 1)
 }
 
 func runPut(_ *cobra.Command, args []string) error {
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+// relocateOptions holds the flags shared by cp and mv.
+type relocateOptions struct {
+	toContext string
+	prefix    bool
+	overwrite bool
+	dryRun    bool
+}
+
+// keyMove is a single key to copy or move. dstRev is the mod revision of the
+// existing destination key, or 0 if the destination does not exist.
+type keyMove struct {
+	src    *client.KeyValue
+	dstKey string
+	dstRev int64
+}
+
+// txnUnit is the set of guards and ops for one key. A unit is never split
+// across transactions.
+type txnUnit struct {
+	guards []client.TxnGuard
+	ops    []client.TxnOp
+}
+
+func addRelocateFlags(cmd *cobra.Command, opts *relocateOptions, verb string) {
+	cmd.Flags().BoolVar(&opts.prefix, "prefix", false,
+		verb+" all keys under the source prefix to the destination prefix")
+	cmd.Flags().BoolVar(&opts.overwrite, "overwrite", false,
+		"replace destination keys that already exist")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false,
+		"preview the writes without applying them")
+	cmd.Flags().StringVar(&opts.toContext, "to-context", "",
+		"write to this context instead of the source context")
+	registerContextCompletion(cmd, "to-context")
+}
+
+func runRelocate(src, dst string, opts *relocateOptions, move bool) error {
+	allowedFormats := []string{
+		output.FormatSimple.String(),
+		output.FormatJSON.String(),
+		output.FormatTable.String(),
+	}
+	if err := validateOutputFormat(allowedFormats); err != nil {
+		return err
+	}
+	if err := validateKeyPrefix(src); err != nil {
+		return err
+	}
+	if err := validateKeyPrefix(dst); err != nil {
+		return err
+	}
+
+	sameCluster := opts.toContext == "" || opts.toContext == resolveContextName()
+	if sameCluster {
+		if err := validateRelocatePaths(src, dst, opts.prefix); err != nil {
+			return err
+		}
+	}
+
+	srcCfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+	srcClient, cleanupSrc, err := newEtcdClient(srcCfg)
+	if err != nil {
+		return err
+	}
+	defer cleanupSrc()
+
+	dstClient := srcClient
+	if !sameCluster {
+		dstCfg, cfgErr := config.GetEtcdConfigWithContext(opts.toContext)
+		if cfgErr != nil {
+			return wrapNotConnectedError(cfgErr)
+		}
+		var cleanupDst func()
+		dstClient, cleanupDst, err = newEtcdClient(dstCfg)
+		if err != nil {
+			return err
+		}
+		defer cleanupDst()
+	}
+
+	ctx, cancel := getOperationContext()
+	defer cancel()
+
+	moves, err := planRelocation(ctx, srcClient, dstClient, src, dst, opts.prefix)
+	if err != nil {
+		return wrapContextError(err)
+	}
+
+	if !opts.overwrite {
+		if err := checkRelocateConflicts(moves); err != nil {
+			return err
+		}
+	}
+
+	dstUnits, srcUnits := relocationUnits(moves, move, sameCluster)
+
+	if opts.dryRun {
+		preview := client.NewDryRunClient()
+		if _, _, err := commitUnits(ctx, preview, dstUnits); err != nil {
+			return err
+		}
+		if _, _, err := commitUnits(ctx, preview, srcUnits); err != nil {
+			return err
+		}
+		return output.PrintDryRunOperations(dryRunViewOps(preview.Operations()), outputFormat)
+	}
+
+	verb := "Copied"
+	if move {
+		verb = "Moved"
+	}
+
+	committed, txns, err := commitUnits(ctx, dstClient, dstUnits)
+	if err != nil {
+		if committed > 0 {
+			output.Warning(fmt.Sprintf("Partial failure: %d/%d keys written in earlier transactions", committed, len(moves)))
+		}
+		if errors.Is(err, client.ErrTxnConflict) {
+			return fmt.Errorf("✗ aborted: a source or destination key changed after it was read; run the command again")
+		}
+		return wrapContextError(err)
+	}
+
+	if len(srcUnits) > 0 {
+		deleted, _, err := commitUnits(ctx, srcClient, srcUnits)
+		if err != nil {
+			output.Warning(fmt.Sprintf("Keys were copied to %s but only %d/%d source keys were deleted",
+				opts.toContext, deleted, len(srcUnits)))
+			if errors.Is(err, client.ErrTxnConflict) {
+				return fmt.Errorf("✗ source keys changed during the move and were kept")
+			}
+			return wrapContextError(err)
+		}
+	}
+
+	noun := "keys"
+	if len(moves) == 1 {
+		noun = "key"
+	}
+	output.Success(fmt.Sprintf("%s %d %s from %s to %s (%s)", verb, len(moves), noun, src, dst, describeTxns(txns)))
+	return nil
+}
+
+// validateRelocatePaths rejects sources and destinations that would copy a
+// key onto itself or a prefix into itself within one cluster.
+func validateRelocatePaths(src, dst string, prefix bool) error {
+	if src == dst {
+		return fmt.Errorf("✗ source and destination are the same: %s", src)
+	}
+	if prefix && (strings.HasPrefix(dst, src) || strings.HasPrefix(src, dst)) {
+		return fmt.Errorf("✗ source prefix %s and destination prefix %s overlap", src, dst)
+	}
+	return nil
+}
+
+// planRelocation reads the source keys and the current state of their
+// destinations. With prefix set, every key under src is mapped under dst;
+// otherwise src and dst are single keys.
+func planRelocation(ctx context.Context, srcReader, dstReader client.EtcdReader, src, dst string, prefix bool) ([]keyMove, error) {
+	if !prefix {
+		resp, err := srcReader.GetWithOptions(ctx, src, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", src, err)
+		}
+		if len(resp.Kvs) == 0 {
+			return nil, fmt.Errorf("key not found: %s", src)
+		}
+		existing, err := dstReader.GetWithOptions(ctx, dst, &client.GetOptions{KeysOnly: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dst, err)
+		}
+		move := keyMove{src: resp.Kvs[0], dstKey: dst}
+		if len(existing.Kvs) > 0 {
+			move.dstRev = existing.Kvs[0].ModRevision
+		}
+		return []keyMove{move}, nil
+	}
+
+	kvs, _, err := client.GetAllWithPrefix(ctx, srcReader, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys under %s: %w", src, err)
+	}
+	if len(kvs) == 0 {
+		return nil, fmt.Errorf("no keys found with prefix %s", src)
+	}
+
+	existing, _, err := client.GetAllWithPrefix(ctx, dstReader, dst, &client.PageOptions{KeysOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keys under %s: %w", dst, err)
+	}
+	dstRevs := make(map[string]int64, len(existing))
+	for _, kv := range existing {
+		dstRevs[kv.Key] = kv.ModRevision
+	}
+
+	moves := make([]keyMove, len(kvs))
+	for i, kv := range kvs {
+		dstKey := rewriteKeyPrefix(kv.Key, src, dst)
+		moves[i] = keyMove{src: kv, dstKey: dstKey, dstRev: dstRevs[dstKey]}
+	}
+	return moves, nil
+}
+
+func checkRelocateConflicts(moves []keyMove) error {
+	var conflicts []string
+	for _, m := range moves {
+		if m.dstRev != 0 {
+			conflicts = append(conflicts, m.dstKey)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	shown := conflicts
+	if len(shown) > 3 {
+		shown = shown[:3]
+	}
+	list := strings.Join(shown, ", ")
+	if len(conflicts) > len(shown) {
+		list += fmt.Sprintf(", and %d more", len(conflicts)-len(shown))
+	}
+	return fmt.Errorf("✗ %d destination keys already exist (%s); use --overwrite to replace them", len(conflicts), list)
+}
+
+// relocationUnits builds the transaction units for the moves. Within one
+// cluster each unit guards the source and destination revisions and, for a
+// move, deletes the source in the same transaction. Across clusters the
+// destination writes and the source deletes are separate units.
+func relocationUnits(moves []keyMove, move, sameCluster bool) (dstUnits, srcUnits []txnUnit) {
+	dstUnits = make([]txnUnit, len(moves))
+	for i, m := range moves {
+		srcGuard := client.TxnGuard{Key: m.src.Key, ModRevision: m.src.ModRevision}
+		unit := txnUnit{
+			guards: []client.TxnGuard{{Key: m.dstKey, ModRevision: m.dstRev}},
+			ops:    []client.TxnOp{{Type: client.TxnOpPut, Key: m.dstKey, Value: m.src.Value}},
+		}
+
+		if sameCluster {
+			unit.guards = append([]client.TxnGuard{srcGuard}, unit.guards...)
+			if move {
+				unit.ops = append(unit.ops, client.TxnOp{Type: client.TxnOpDelete, Key: m.src.Key})
+			}
+		} else if move {
+			srcUnits = append(srcUnits, txnUnit{
+				guards: []client.TxnGuard{srcGuard},
+				ops:    []client.TxnOp{{Type: client.TxnOpDelete, Key: m.src.Key}},
+			})
+		}
+		dstUnits[i] = unit
+	}
+	return dstUnits, srcUnits
+}
+
+// commitUnits commits units in as few transactions as the per-transaction
+// limit allows, so a set that fits is applied atomically. It returns how many
+// units were committed and how many transactions were used. On error, units
+// in earlier transactions stay committed.
+func commitUnits(ctx context.Context, writer client.EtcdWriter, units []txnUnit) (int, int, error) {
+	committed, txns := 0, 0
+	for start := 0; start < len(units); {
+		var guards []client.TxnGuard
+		var ops []client.TxnOp
+		end := start
+		for end < len(units) &&
+			len(guards)+len(units[end].guards) <= client.DefaultMaxOpsPerTxn &&
+			len(ops)+len(units[end].ops) <= client.DefaultMaxOpsPerTxn {
+			guards = append(guards, units[end].guards...)
+			ops = append(ops, units[end].ops...)
+			end++
+		}
+
+		if _, err := writer.Txn(ctx, guards, ops); err != nil {
+			return committed, txns, err
+		}
+		committed += end - start
+		txns++
+		start = end
+	}
+	return committed, txns, nil
+}
+
+func describeTxns(txns int) string {
+	if txns == 1 {
+		return "1 atomic transaction"
+	}
+	return fmt.Sprintf("%d transactions", txns)
+}
@@ -0,0 +1,91 @@
+//go:build integration
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCpMv_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	tempDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	defer os.Setenv("HOME", oldHome)
+
+	fullEndpoint := setupEtcdContainerForCmd(t)
+	ctx := context.Background()
+
+	etcdClient, err := client.NewClient(&client.Config{Endpoints: []string{fullEndpoint}})
+	require.NoError(t, err)
+	defer etcdClient.Close()
+
+	appCfg := &config.Config{
+		Contexts: map[string]*config.ContextConfig{
+			"test": {Endpoints: []string{fullEndpoint}},
+		},
+		CurrentContext: "test",
+	}
+	require.NoError(t, config.SaveConfig(appCfg))
+
+	for k, v := range map[string]string{
+		"/reloc/v1/a":   "1",
+		"/reloc/v1/b/c": "2",
+	} {
+		require.NoError(t, etcdClient.Put(ctx, k, v))
+	}
+
+	t.Cleanup(func() {
+		cpOpts = relocateOptions{}
+		mvOpts = relocateOptions{}
+	})
+
+	t.Run("cp prefix", func(t *testing.T) {
+		cpOpts = relocateOptions{prefix: true}
+		_, err := testutil.CaptureStdout(func() error {
+			return runCp(cpCmd, []string{"/reloc/v1/", "/reloc/v2/"})
+		})
+		require.NoError(t, err)
+
+		value, err := etcdClient.Get(ctx, "/reloc/v2/b/c")
+		require.NoError(t, err)
+		assert.Equal(t, "2", value)
+	})
+
+	t.Run("cp refuses to overwrite", func(t *testing.T) {
+		cpOpts = relocateOptions{prefix: true}
+		_, err := testutil.CaptureStdout(func() error {
+			return runCp(cpCmd, []string{"/reloc/v1/", "/reloc/v2/"})
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already exist")
+	})
+
+	t.Run("mv prefix", func(t *testing.T) {
+		mvOpts = relocateOptions{prefix: true}
+		_, err := testutil.CaptureStdout(func() error {
+			return runMv(mvCmd, []string{"/reloc/v1/", "/reloc/v3/"})
+		})
+		require.NoError(t, err)
+
+		resp, err := etcdClient.GetWithOptions(ctx, "/reloc/v1/", &client.GetOptions{Prefix: true, CountOnly: true})
+		require.NoError(t, err)
+		assert.Zero(t, resp.Count)
+
+		value, err := etcdClient.Get(ctx, "/reloc/v3/a")
+		require.NoError(t, err)
+		assert.Equal(t, "1", value)
+	})
+}
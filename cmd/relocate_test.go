@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+)
+
+func TestValidateRelocatePaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		dst     string
+		wantErr string
+		prefix  bool
+	}{
+		{name: "rename key", src: "/a/host", dst: "/a/hostname"},
+		{name: "key into own subtree is fine", src: "/a", dst: "/a/b"},
+		{name: "same key", src: "/a", dst: "/a", wantErr: "are the same"},
+		{name: "disjoint prefixes", src: "/v1/", dst: "/v2/", prefix: true},
+		{name: "destination inside source", src: "/app/", dst: "/app/copy/", prefix: true, wantErr: "overlap"},
+		{name: "source inside destination", src: "/app/sub/", dst: "/app/", prefix: true, wantErr: "overlap"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRelocatePaths(tt.src, tt.dst, tt.prefix)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestPlanRelocation_SingleKey(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, key string, _ *client.GetOptions) (*client.GetResponse, error) {
+			switch key {
+			case "/src":
+				return &client.GetResponse{Kvs: []*client.KeyValue{{Key: "/src", Value: "v", ModRevision: 7}}}, nil
+			case "/dst":
+				return &client.GetResponse{Kvs: []*client.KeyValue{{Key: "/dst", ModRevision: 9}}}, nil
+			}
+			return &client.GetResponse{}, nil
+		},
+	}
+
+	moves, err := planRelocation(context.Background(), mock, mock, "/src", "/dst", false)
+	require.NoError(t, err)
+	require.Len(t, moves, 1)
+	assert.Equal(t, "/src", moves[0].src.Key)
+	assert.Equal(t, "/dst", moves[0].dstKey)
+	assert.Equal(t, int64(9), moves[0].dstRev)
+
+	_, err = planRelocation(context.Background(), mock, mock, "/missing", "/dst", false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "key not found: /missing")
+}
+
+func TestPlanRelocation_Prefix(t *testing.T) {
+	source := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return &client.GetResponse{Revision: 20, Kvs: []*client.KeyValue{
+				{Key: "/v1/a", Value: "1", ModRevision: 3},
+				{Key: "/v1/b/c", Value: "2", ModRevision: 4},
+			}}, nil
+		},
+	}
+	dest := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, opts *client.GetOptions) (*client.GetResponse, error) {
+			assert.True(t, opts.KeysOnly)
+			return &client.GetResponse{Kvs: []*client.KeyValue{{Key: "/v2/b/c", ModRevision: 11}}}, nil
+		},
+	}
+
+	moves, err := planRelocation(context.Background(), source, dest, "/v1", "/v2", true)
+	require.NoError(t, err)
+	require.Len(t, moves, 2)
+	assert.Equal(t, "/v2/a", moves[0].dstKey)
+	assert.Zero(t, moves[0].dstRev)
+	assert.Equal(t, "/v2/b/c", moves[1].dstKey)
+	assert.Equal(t, int64(11), moves[1].dstRev)
+}
+
+func TestPlanRelocation_EmptyPrefix(t *testing.T) {
+	mock := client.NewMockClient()
+	mock.GetWithOptionsFunc = func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+		return &client.GetResponse{}, nil
+	}
+
+	_, err := planRelocation(context.Background(), mock, mock, "/none", "/dst", true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no keys found with prefix /none")
+}
+
+func TestCheckRelocateConflicts(t *testing.T) {
+	moves := []keyMove{
+		{dstKey: "/a"},
+		{dstKey: "/b", dstRev: 2},
+	}
+	err := checkRelocateConflicts(moves)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 destination keys already exist (/b)")
+	assert.Contains(t, err.Error(), "--overwrite")
+
+	for i := range 5 {
+		moves = append(moves, keyMove{dstKey: fmt.Sprintf("/x%d", i), dstRev: 1})
+	}
+	err = checkRelocateConflicts(moves)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "6 destination keys already exist (/b, /x0, /x1, and 3 more)")
+
+	assert.NoError(t, checkRelocateConflicts([]keyMove{{dstKey: "/a"}}))
+}
+
+func TestRelocationUnits_SameCluster(t *testing.T) {
+	moves := []keyMove{{
+		src:    &client.KeyValue{Key: "/old", Value: "v", ModRevision: 5},
+		dstKey: "/new",
+		dstRev: 8,
+	}}
+
+	dstUnits, srcUnits := relocationUnits(moves, true, true)
+	assert.Empty(t, srcUnits)
+	require.Len(t, dstUnits, 1)
+	assert.Equal(t, []client.TxnGuard{
+		{Key: "/old", ModRevision: 5},
+		{Key: "/new", ModRevision: 8},
+	}, dstUnits[0].guards)
+	assert.Equal(t, []client.TxnOp{
+		{Type: client.TxnOpPut, Key: "/new", Value: "v"},
+		{Type: client.TxnOpDelete, Key: "/old"},
+	}, dstUnits[0].ops)
+
+	// A copy keeps the source guard but does not delete
+	dstUnits, _ = relocationUnits(moves, false, true)
+	assert.Len(t, dstUnits[0].guards, 2)
+	assert.Equal(t, []client.TxnOp{{Type: client.TxnOpPut, Key: "/new", Value: "v"}}, dstUnits[0].ops)
+}
+
+func TestRelocationUnits_CrossCluster(t *testing.T) {
+	moves := []keyMove{{
+		src:    &client.KeyValue{Key: "/old", Value: "v", ModRevision: 5},
+		dstKey: "/old",
+	}}
+
+	dstUnits, srcUnits := relocationUnits(moves, true, false)
+	require.Len(t, dstUnits, 1)
+	assert.Equal(t, []client.TxnGuard{{Key: "/old"}}, dstUnits[0].guards)
+	assert.Equal(t, []client.TxnOp{{Type: client.TxnOpPut, Key: "/old", Value: "v"}}, dstUnits[0].ops)
+
+	require.Len(t, srcUnits, 1)
+	assert.Equal(t, []client.TxnGuard{{Key: "/old", ModRevision: 5}}, srcUnits[0].guards)
+	assert.Equal(t, []client.TxnOp{{Type: client.TxnOpDelete, Key: "/old"}}, srcUnits[0].ops)
+
+	_, srcUnits = relocationUnits(moves, false, false)
+	assert.Empty(t, srcUnits)
+}
+
+func makeMoves(n int) []keyMove {
+	moves := make([]keyMove, n)
+	for i := range moves {
+		moves[i] = keyMove{
+			src:    &client.KeyValue{Key: fmt.Sprintf("/src/%03d", i), Value: "v", ModRevision: int64(i + 1)},
+			dstKey: fmt.Sprintf("/dst/%03d", i),
+		}
+	}
+	return moves
+}
+
+func TestCommitUnits_SingleTransaction(t *testing.T) {
+	mock := client.NewMockClient()
+	units, _ := relocationUnits(makeMoves(64), true, true)
+
+	committed, txns, err := commitUnits(context.Background(), mock, units)
+	require.NoError(t, err)
+	assert.Equal(t, 64, committed)
+	assert.Equal(t, 1, txns)
+	require.Len(t, mock.TxnCalls, 1)
+	assert.Len(t, mock.TxnCalls[0].Guards, 128)
+	assert.Len(t, mock.TxnCalls[0].Ops, 128)
+}
+
+func TestCommitUnits_Batches(t *testing.T) {
+	mock := client.NewMockClient()
+	units, _ := relocationUnits(makeMoves(150), true, true)
+
+	committed, txns, err := commitUnits(context.Background(), mock, units)
+	require.NoError(t, err)
+	assert.Equal(t, 150, committed)
+	assert.Equal(t, 3, txns)
+	for _, call := range mock.TxnCalls {
+		assert.LessOrEqual(t, len(call.Guards), client.DefaultMaxOpsPerTxn)
+		assert.LessOrEqual(t, len(call.Ops), client.DefaultMaxOpsPerTxn)
+		// A key's put and delete always travel together
+		assert.Equal(t, len(call.Guards), len(call.Ops))
+	}
+}
+
+func TestCommitUnits_ConflictStopsBatching(t *testing.T) {
+	mock := client.NewMockClient()
+	mock.TxnFunc = func(_ context.Context, _ []client.TxnGuard, _ []client.TxnOp) (int64, error) {
+		if len(mock.TxnCalls) == 2 {
+			return 0, client.ErrTxnConflict
+		}
+		return 0, nil
+	}
+	units, _ := relocationUnits(makeMoves(200), true, true)
+
+	committed, txns, err := commitUnits(context.Background(), mock, units)
+	assert.ErrorIs(t, err, client.ErrTxnConflict)
+	assert.Equal(t, 64, committed)
+	assert.Equal(t, 1, txns)
+	assert.Len(t, mock.TxnCalls, 2)
+}
+
+func TestCommitUnits_DryRun(t *testing.T) {
+	preview := client.NewDryRunClient()
+	units, _ := relocationUnits(makeMoves(1), true, true)
+
+	_, _, err := commitUnits(context.Background(), preview, units)
+	require.NoError(t, err)
+	assert.Equal(t, []client.Operation{
+		{Type: "PUT", Key: "/dst/000", Value: "v"},
+		{Type: "DELETE", Key: "/src/000"},
+	}, preview.Operations())
+}
+
+func TestCommitUnits_Error(t *testing.T) {
+	mock := client.NewMockClient()
+	mock.TxnFunc = func(_ context.Context, _ []client.TxnGuard, _ []client.TxnOp) (int64, error) {
+		return 0, errors.New("etcdserver: request timed out")
+	}
+	units, _ := relocationUnits(makeMoves(3), false, true)
+
+	committed, _, err := commitUnits(context.Background(), mock, units)
+	require.Error(t, err)
+	assert.Zero(t, committed)
+}
+
+func TestDescribeTxns(t *testing.T) {
+	assert.Equal(t, "1 atomic transaction", describeTxns(1))
+	assert.Equal(t, "3 transactions", describeTxns(3))
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/backup"
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+var (
+	restoreOpts struct {
+		file     string
+		toPrefix string
+		force    bool
+		dryRun   bool
+	}
+
+	restoreCmd = &cobra.Command{
+		Use:   "restore -f <file>",
+		Short: "Restore keys from a backup archive",
+		Long: `Restore keys from an archive written by 'etu backup'.
+
+The archive checksum is verified first, then the writes are previewed and applied
+after confirmation. Keys are restored under their original prefix unless
+--to-prefix is given. Leases belong to the source cluster and expire, so leased
+keys are restored without a lease.`,
+		Example: `  # Restore a backup
+  etu restore -f app.etub
+
+  # Restore under a different prefix
+  etu restore -f app.etub --to-prefix /app2
+
+  # Preview without writing
+  etu restore -f app.etub --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: runRestore,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().StringVarP(&restoreOpts.file, "file", "f", "",
+		"path of the archive to restore (required)")
+	restoreCmd.Flags().StringVar(&restoreOpts.toPrefix, "to-prefix", "",
+		"restore keys under this prefix instead of the original one")
+	restoreCmd.Flags().BoolVar(&restoreOpts.force, "force", false,
+		"skip confirmation prompt")
+	restoreCmd.Flags().BoolVar(&restoreOpts.dryRun, "dry-run", false,
+		"preview the restore without writing")
+
+	_ = restoreCmd.MarkFlagRequired("file")
+}
+
+func runRestore(_ *cobra.Command, _ []string) error {
+	allowedFormats := []string{
+		output.FormatSimple.String(),
+		output.FormatJSON.String(),
+		output.FormatTable.String(),
+	}
+	if err := validateOutputFormat(allowedFormats); err != nil {
+		return err
+	}
+	if restoreOpts.toPrefix != "" {
+		if err := validateKeyPrefix(restoreOpts.toPrefix); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(restoreOpts.file)
+	if err != nil {
+		return fmt.Errorf("✗ failed to open backup: %w", err)
+	}
+	header, entries, err := backup.Read(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("✗ failed to read backup %s: %w", restoreOpts.file, err)
+	}
+
+	if outputFormat == output.FormatSimple.String() {
+		printBackupHeader(header)
+	}
+	if len(entries) == 0 {
+		output.Warning("Backup contains no keys")
+		return nil
+	}
+
+	pairs, leased := restorePairs(header, entries, restoreOpts.toPrefix)
+	if leased > 0 {
+		output.Warning(fmt.Sprintf("%d keys had leases in the source cluster and will be restored without a lease", leased))
+	}
+
+	cfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+
+	etcdClient, cleanup, err := newEtcdClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := getOperationContext()
+	defer cancel()
+
+	preview := client.NewDryRunClientWithReader(etcdClient)
+	if _, err := preview.PutAllWithOptions(ctx, pairs, nil, nil); err != nil {
+		return err
+	}
+	if err := output.PrintDryRunOperations(dryRunViewOps(preview.Operations()), outputFormat); err != nil {
+		return err
+	}
+
+	if restoreOpts.dryRun {
+		return nil
+	}
+
+	target := header.Prefix
+	if restoreOpts.toPrefix != "" {
+		target = restoreOpts.toPrefix
+	}
+	if !restoreOpts.force {
+		if !confirmRestore(len(pairs), target, os.Stdin, os.Stdout) {
+			output.Info("Restore canceled")
+			return nil
+		}
+	}
+
+	var onProgress client.ProgressFunc
+	if outputFormat == output.FormatSimple.String() {
+		onProgress = func(current, total int, key string) {
+			output.PrintApplyProgress(current, total, key)
+		}
+	}
+
+	result, err := etcdClient.PutAllWithOptions(ctx, pairs, onProgress, client.DefaultBatchOptions())
+	if err != nil {
+		if result != nil && result.Succeeded > 0 {
+			output.Warning(fmt.Sprintf("Partial failure: %d/%d keys restored before error",
+				result.Succeeded, result.Total))
+		}
+		return wrapContextError(fmt.Errorf("failed to restore backup: %w", err))
+	}
+
+	output.Success(fmt.Sprintf("Restored %d keys to %s", result.Succeeded, target))
+	return nil
+}
+
+// restorePairs converts archive entries to pairs, moving keys from the
+// backup prefix to toPrefix when set. It also returns how many entries had a lease.
+func restorePairs(header *backup.Header, entries []backup.Entry, toPrefix string) ([]*models.ConfigPair, int) {
+	pairs := make([]*models.ConfigPair, len(entries))
+	leased := 0
+	for i, e := range entries {
+		key := e.Key
+		if toPrefix != "" {
+			key = rewriteKeyPrefix(key, header.Prefix, toPrefix)
+		}
+		pairs[i] = &models.ConfigPair{Key: key, Value: e.Value}
+		if e.Lease != 0 {
+			leased++
+		}
+	}
+	return pairs, leased
+}
+
+func printBackupHeader(header *backup.Header) {
+	source := header.Context
+	if source == "" {
+		source = "unknown context"
+	}
+	if header.ClusterID != 0 {
+		source = fmt.Sprintf("%s, cluster %x", source, header.ClusterID)
+	}
+	output.Info(fmt.Sprintf("Backup of %s from %s at revision %d (%d keys, created %s)",
+		header.Prefix, source, header.Revision, header.Count, header.CreatedAt.Format("2006-01-02 15:04:05 MST")))
+}
+
+func confirmRestore(count int, prefix string, in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "\nRestore %d keys to %q? [y/N]: ", count, prefix)
+	return readConfirmation(in)
+}
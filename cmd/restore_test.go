@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/backup"
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+func TestRestorePairs(t *testing.T) {
+	header := &backup.Header{Prefix: "/app"}
+	entries := []backup.Entry{
+		{Key: "/app/a", Value: "1"},
+		{Key: "/app/b/c", Value: "", Lease: 12},
+	}
+
+	pairs, leased := restorePairs(header, entries, "")
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "/app/a", Value: "1"},
+		{Key: "/app/b/c", Value: ""},
+	}, pairs)
+	assert.Equal(t, 1, leased)
+
+	pairs, _ = restorePairs(header, entries, "/app2")
+	assert.Equal(t, "/app2/a", pairs[0].Key)
+	assert.Equal(t, "/app2/b/c", pairs[1].Key)
+}
+
+func TestConfirmRestore(t *testing.T) {
+	out := &bytes.Buffer{}
+	assert.True(t, confirmRestore(3, "/app", strings.NewReader("yes\n"), out))
+	assert.Contains(t, out.String(), `Restore 3 keys to "/app"?`)
+
+	assert.False(t, confirmRestore(3, "/app", strings.NewReader("\n"), &bytes.Buffer{}))
+}
+
+func TestRunRestore_CorruptArchive(t *testing.T) {
+	originalOpts := restoreOpts
+	defer func() { restoreOpts = originalOpts }()
+
+	path := filepath.Join(t.TempDir(), "bad.etub")
+	require.NoError(t, os.WriteFile(path, []byte("not an archive"), 0o600))
+	restoreOpts.file = path
+
+	err := runRestore(restoreCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not an etu backup archive")
+}
+
+func TestRunRestore_MissingFile(t *testing.T) {
+	originalOpts := restoreOpts
+	defer func() { restoreOpts = originalOpts }()
+	restoreOpts.file = filepath.Join(t.TempDir(), "missing.etub")
+
+	err := runRestore(restoreCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open backup")
+}
+
+func TestRunRestore_InvalidToPrefix(t *testing.T) {
+	originalOpts := restoreOpts
+	defer func() { restoreOpts = originalOpts }()
+	restoreOpts.file = "unused.etub"
+	restoreOpts.toPrefix = "app2"
+
+	err := runRestore(restoreCmd, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must start with '/'")
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+var (
+	rollbackOpts struct {
+		toRev  int64
+		prefix bool
+		force  bool
+		dryRun bool
+	}
+
+	rollbackCmd = &cobra.Command{
+		Use:   "rollback <key|prefix> --to-rev N",
+		Short: "Restore a key or prefix to an earlier revision",
+		Long: `Read the state of a key (or every key under a prefix) at an earlier revision and
+restore it. Keys that changed since are put back to their old values, keys created
+since are deleted, and keys deleted since are recreated.
+
+The required operations are previewed before anything is written, then applied in
+as few transactions as etcd allows (one for most rollbacks). Every key is guarded
+at the revision it was read at, so the rollback aborts if a key changes in the
+meantime. The revision must not have been compacted.`,
+		Example: `  # Restore a single key to its value at revision 120
+  etu rollback /config/app/database/host --to-rev 120
+
+  # Restore everything under a prefix
+  etu rollback /config/app/ --prefix --to-rev 120
+
+  # Preview the operations without applying them
+  etu rollback /config/app/ --prefix --to-rev 120 --dry-run
+
+  # Skip confirmation
+  etu rollback /config/app/ --prefix --to-rev 120 --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: runRollback,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().Int64Var(&rollbackOpts.toRev, "to-rev", 0,
+		"revision to restore (required)")
+	rollbackCmd.Flags().BoolVar(&rollbackOpts.prefix, "prefix", false,
+		"roll back all keys with the given prefix")
+	rollbackCmd.Flags().BoolVar(&rollbackOpts.force, "force", false,
+		"skip confirmation prompt")
+	rollbackCmd.Flags().BoolVar(&rollbackOpts.dryRun, "dry-run", false,
+		"preview the rollback without applying it")
+
+	_ = rollbackCmd.MarkFlagRequired("to-rev")
+
+	registerKeyCompletion(rollbackCmd, 1)
+}
+
+func runRollback(_ *cobra.Command, args []string) error {
+	allowedFormats := []string{
+		output.FormatSimple.String(),
+		output.FormatJSON.String(),
+		output.FormatTable.String(),
+	}
+	if err := validateOutputFormat(allowedFormats); err != nil {
+		return err
+	}
+
+	key := args[0]
+	if err := validateKeyPrefix(key); err != nil {
+		return err
+	}
+	if rollbackOpts.toRev <= 0 {
+		return fmt.Errorf("✗ --to-rev must be a positive revision")
+	}
+
+	cfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+
+	etcdClient, cleanup, err := newEtcdClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := getOperationContext()
+	defer cancel()
+
+	logVerbose("Computing rollback", "key", key, "revision", rollbackOpts.toRev, "prefix", rollbackOpts.prefix)
+	plan, err := computeRollback(ctx, etcdClient, key, rollbackOpts.toRev, rollbackOpts.prefix)
+	if err != nil {
+		return wrapContextError(err)
+	}
+
+	diff := plan.diff
+	if diff.Added+diff.Modified+diff.Deleted == 0 {
+		output.Info(fmt.Sprintf("Nothing to roll back: %s already matches revision %d", key, rollbackOpts.toRev))
+		return nil
+	}
+
+	units := rollbackUnits(plan)
+
+	// Record the operations against a dry-run client to build the preview
+	preview := client.NewDryRunClientWithReader(etcdClient)
+	if _, _, err := commitUnits(ctx, preview, units); err != nil {
+		return err
+	}
+	if err := output.PrintDryRunOperations(dryRunViewOps(preview.Operations()), outputFormat); err != nil {
+		return err
+	}
+
+	if rollbackOpts.dryRun {
+		return nil
+	}
+
+	if !rollbackOpts.force {
+		if !confirmRollback(key, rollbackOpts.toRev, os.Stdin, os.Stdout) {
+			output.Info("Rollback canceled")
+			return nil
+		}
+	}
+
+	applied, txns, err := commitUnits(ctx, etcdClient, units)
+	if err != nil {
+		if applied > 0 {
+			output.Warning(fmt.Sprintf("Partial failure: %d/%d operations applied in earlier transactions", applied, len(units)))
+		}
+		if errors.Is(err, client.ErrTxnConflict) {
+			return fmt.Errorf("✗ aborted: a key changed after the rollback was computed; run the command again")
+		}
+		return wrapContextError(fmt.Errorf("failed to roll back: %w", err))
+	}
+
+	output.Success(fmt.Sprintf("Rolled back %s to revision %d (%d operations in %s)", key, rollbackOpts.toRev, applied, describeTxns(txns)))
+	return nil
+}
+
+// rollbackPlan is the diff between the state at the target revision (as the
+// desired state) and the current state, with the mod revision of every
+// current key it was computed against.
+type rollbackPlan struct {
+	diff *output.DiffResult
+	revs map[string]int64
+}
+
+// computeRollback diffs the state at rev against the current state. Added
+// and modified entries must be put, deleted entries removed.
+func computeRollback(ctx context.Context, reader client.EtcdReader, key string, rev int64, prefix bool) (*rollbackPlan, error) {
+	past, err := readRollbackState(ctx, reader, key, rev, prefix)
+	if errors.Is(err, client.ErrCompacted) {
+		return nil, fmt.Errorf("✗ revision %d has been compacted and can no longer be read", rev)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revision %d: %w", rev, err)
+	}
+
+	current, err := readRollbackState(ctx, reader, key, 0, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current state: %w", err)
+	}
+
+	revs := make(map[string]int64, len(current))
+	for _, kv := range current {
+		revs[kv.Key] = kv.ModRevision
+	}
+	return &rollbackPlan{
+		diff: output.DiffKeyValues(kvsToMap(past), kvsToMap(current)),
+		revs: revs,
+	}, nil
+}
+
+// readRollbackState reads key at rev (0 = current). With prefix set it reads
+// every key under key page by page, all pinned to one revision.
+func readRollbackState(ctx context.Context, reader client.EtcdReader, key string, rev int64, prefix bool) ([]*client.KeyValue, error) {
+	if prefix {
+		kvs, _, err := client.GetAllWithPrefix(ctx, reader, key, &client.PageOptions{Revision: rev})
+		return kvs, err
+	}
+	resp, err := reader.GetWithOptions(ctx, key, &client.GetOptions{Revision: rev})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Kvs, nil
+}
+
+// rollbackUnits builds one transaction unit per change in plan. Each unit
+// guards the key at the revision it was read at, or its absence for keys
+// that are recreated, so a concurrent write aborts the rollback.
+func rollbackUnits(plan *rollbackPlan) []txnUnit {
+	var units []txnUnit
+	for _, entry := range plan.diff.Entries {
+		guards := []client.TxnGuard{{Key: entry.Key, ModRevision: plan.revs[entry.Key]}}
+		switch entry.Status {
+		case output.DiffStatusAdded, output.DiffStatusModified:
+			units = append(units, txnUnit{
+				guards: guards,
+				ops:    []client.TxnOp{{Type: client.TxnOpPut, Key: entry.Key, Value: entry.NewValue}},
+			})
+		case output.DiffStatusDeleted:
+			units = append(units, txnUnit{
+				guards: guards,
+				ops:    []client.TxnOp{{Type: client.TxnOpDelete, Key: entry.Key}},
+			})
+		}
+	}
+	return units
+}
+
+func kvsToMap(kvs []*client.KeyValue) map[string]string {
+	m := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func confirmRollback(key string, rev int64, in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "\nRoll back %q to revision %d? [y/N]: ", key, rev)
+	return readConfirmation(in)
+}
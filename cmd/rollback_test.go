@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+func rollbackMock(past, current []*client.KeyValue) *client.MockClient {
+	return &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, opts *client.GetOptions) (*client.GetResponse, error) {
+			if opts.Revision != 0 {
+				return &client.GetResponse{Kvs: past}, nil
+			}
+			return &client.GetResponse{Kvs: current}, nil
+		},
+	}
+}
+
+func TestComputeRollback(t *testing.T) {
+	mock := rollbackMock(
+		[]*client.KeyValue{
+			{Key: "/app/a", Value: "1"},
+			{Key: "/app/b", Value: "old"},
+			{Key: "/app/removed", Value: "x"},
+		},
+		[]*client.KeyValue{
+			{Key: "/app/a", Value: "1", ModRevision: 10},
+			{Key: "/app/b", Value: "new", ModRevision: 50},
+			{Key: "/app/created", Value: "y", ModRevision: 51},
+		},
+	)
+
+	plan, err := computeRollback(context.Background(), mock, "/app/", 42, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, plan.diff.Added)
+	assert.Equal(t, 1, plan.diff.Modified)
+	assert.Equal(t, 1, plan.diff.Deleted)
+	assert.Equal(t, 1, plan.diff.Unchanged)
+	assert.Equal(t, map[string]int64{"/app/a": 10, "/app/b": 50, "/app/created": 51}, plan.revs)
+
+	require.Len(t, mock.GetWithOptionsCalls, 2)
+	assert.Equal(t, int64(42), mock.GetWithOptionsCalls[0].Opts.Revision)
+	// Prefixes are read in pages bounded by the prefix range end
+	assert.Positive(t, mock.GetWithOptionsCalls[0].Opts.Limit)
+	assert.Equal(t, "/app0", mock.GetWithOptionsCalls[0].Opts.RangeEnd)
+	assert.Equal(t, int64(0), mock.GetWithOptionsCalls[1].Opts.Revision)
+}
+
+func TestComputeRollback_Compacted(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, key string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return nil, fmt.Errorf("failed to get key %s: %w", key, client.ErrCompacted)
+		},
+	}
+
+	_, err := computeRollback(context.Background(), mock, "/app/key", 3, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "revision 3 has been compacted")
+}
+
+func testRollbackPlan() *rollbackPlan {
+	return &rollbackPlan{
+		diff: output.DiffKeyValues(
+			map[string]string{"/app/a": "1", "/app/b": "old", "/app/removed": "x"},
+			map[string]string{"/app/a": "1", "/app/b": "new", "/app/created": "y"},
+		),
+		revs: map[string]int64{"/app/a": 10, "/app/b": 50, "/app/created": 51},
+	}
+}
+
+func TestRollbackUnits(t *testing.T) {
+	mock := client.NewMockClient()
+	applied, txns, err := commitUnits(context.Background(), mock, rollbackUnits(testRollbackPlan()))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, applied)
+	assert.Equal(t, 1, txns)
+	assert.Empty(t, mock.PutCalls)
+	assert.Empty(t, mock.DeleteCalls)
+
+	require.Len(t, mock.TxnCalls, 1)
+	assert.ElementsMatch(t, []client.TxnGuard{
+		{Key: "/app/b", ModRevision: 50},
+		// Recreated keys must still be absent
+		{Key: "/app/removed", ModRevision: 0},
+		{Key: "/app/created", ModRevision: 51},
+	}, mock.TxnCalls[0].Guards)
+	assert.ElementsMatch(t, []client.TxnOp{
+		{Type: client.TxnOpPut, Key: "/app/b", Value: "old"},
+		{Type: client.TxnOpPut, Key: "/app/removed", Value: "x"},
+		{Type: client.TxnOpDelete, Key: "/app/created"},
+	}, mock.TxnCalls[0].Ops)
+}
+
+func TestRollbackUnits_DryRunRecordsOperations(t *testing.T) {
+	preview := client.NewDryRunClient()
+	_, _, err := commitUnits(context.Background(), preview, rollbackUnits(testRollbackPlan()))
+	require.NoError(t, err)
+
+	ops := dryRunViewOps(preview.Operations())
+	require.Len(t, ops, 3)
+	assert.Contains(t, ops, output.DryRunOperation{Type: "PUT", Key: "/app/b", Value: "old"})
+	assert.Contains(t, ops, output.DryRunOperation{Type: "DELETE", Key: "/app/created"})
+}
+
+func TestRollbackUnits_Conflict(t *testing.T) {
+	mock := &client.MockClient{
+		TxnFunc: func(_ context.Context, _ []client.TxnGuard, _ []client.TxnOp) (int64, error) {
+			return 0, client.ErrTxnConflict
+		},
+	}
+
+	applied, _, err := commitUnits(context.Background(), mock, rollbackUnits(testRollbackPlan()))
+	assert.ErrorIs(t, err, client.ErrTxnConflict)
+	assert.Equal(t, 0, applied)
+	assert.Empty(t, mock.PutCalls)
+}
+
+func TestConfirmRollback(t *testing.T) {
+	for input, want := range map[string]bool{"y\n": true, "yes\n": true, "n\n": false, "\n": false} {
+		out := &bytes.Buffer{}
+		got := confirmRollback("/app/", 7, strings.NewReader(input), out)
+		assert.Equal(t, want, got, "input %q", input)
+		assert.Contains(t, out.String(), "revision 7")
+	}
+}
+
+func TestRollbackCommand_Flags(t *testing.T) {
+	for _, name := range []string{"to-rev", "prefix", "force", "dry-run"} {
+		assert.NotNil(t, rollbackCmd.Flags().Lookup(name), "missing flag %s", name)
+	}
+}
@@ -0,0 +1,754 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/logger"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+// shellHistoryLimit bounds the number of lines kept in the history file.
+const shellHistoryLimit = 1000
+
+// shellKeySpec describes which arguments of a command are keys, so the shell
+// can resolve them against the working prefix.
+type shellKeySpec struct {
+	// args is the number of leading positional arguments that are keys;
+	// -1 means all of them.
+	args int
+	// flags lists the flags whose values are keys.
+	flags []string
+	// defaultToCwd passes the working prefix when no key argument is given.
+	defaultToCwd bool
+	// cwdFlag is set to the working prefix when it is not given.
+	cwdFlag string
+}
+
+var shellKeyArgs = map[string]shellKeySpec{
+	"get":      {args: -1},
+	"delete":   {args: -1},
+	"watch":    {args: -1},
+	"put":      {args: 1},
+	"edit":     {args: 1},
+	"history":  {args: 1},
+	"rollback": {args: 1},
+	"cp":       {args: 2},
+	"mv":       {args: 2},
+	"ls":       {args: 1, defaultToCwd: true},
+	"du":       {args: 1, defaultToCwd: true},
+	"find":     {args: 1, defaultToCwd: true},
+	"ui":       {args: 1, defaultToCwd: true},
+	"grep":     {flags: []string{"prefix"}, cwdFlag: "prefix"},
+	"backup":   {flags: []string{"prefix"}},
+	"diff":     {flags: []string{"prefix"}},
+	"export":   {flags: []string{"prefix"}},
+	"mirror":   {flags: []string{"prefix"}},
+	"restore":  {flags: []string{"to-prefix"}},
+}
+
+// shellSession is the connection shared by the commands run from 'etu shell'.
+type shellSession struct {
+	client  client.EtcdClient
+	config  client.Config
+	context string
+	cwd     string
+	prevCwd string
+	flags   map[*pflag.Flag]flagState
+}
+
+// flagState is the value of a flag when the shell started.
+type flagState struct {
+	value   string
+	changed bool
+}
+
+// activeShell is set while 'etu shell' runs. newEtcdClient hands out its
+// client to commands that resolve to the same connection settings.
+var activeShell *shellSession
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Run etu commands in an interactive shell with one connection",
+	Long: `Start an interactive shell that keeps a single etcd connection open.
+
+Every etu command can be run without the 'etu' prefix, and all of them share
+the connection, so configuration loading, TLS setup and dialing happen once.
+Commands that pass a different --context or auth flags open their own
+connection for that line.
+
+The shell tracks a working prefix. Keys that do not start with '/' are
+resolved against it, and 'ls', 'du', 'find', 'ui' and 'grep' default to it.
+
+Built-in commands:
+  cd [prefix]   change the working prefix ('cd ..', 'cd -', 'cd' for /)
+  pwd           print the working prefix
+  exit, quit    leave the shell (Ctrl-D also works)
+
+Tab completes command names, flags and keys. History is kept in
+shell_history next to the config file. When stdin is not a terminal, lines
+are read as a script without prompts.`,
+	Example: `  # Start a shell on the current context
+  etu shell
+
+  # Then, inside the shell:
+  #   cd /app/config
+  #   ls
+  #   get database/host
+  #   put feature/enabled true
+
+  # Run a script against one connection
+  etu shell < commands.txt`,
+	Args: cobra.NoArgs,
+	RunE: runShell,
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+}
+
+func runShell(_ *cobra.Command, _ []string) error {
+	if activeShell != nil {
+		return fmt.Errorf("✗ already running in etu shell")
+	}
+	if globalPasswordStdin {
+		return fmt.Errorf("✗ --password-stdin is not supported by shell: stdin is needed for commands")
+	}
+
+	cfg, err := config.GetEtcdConfigWithContext(contextName)
+	if err != nil {
+		return wrapNotConnectedError(err)
+	}
+	if err := applyGlobalOverrides(cfg); err != nil {
+		return err
+	}
+	shared := *cfg
+
+	etcdClient, cleanup, err := newEtcdClient(cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	session := &shellSession{
+		client:  etcdClient,
+		config:  shared,
+		context: resolveContextName(),
+		cwd:     "/",
+		prevCwd: "/",
+		flags:   snapshotFlags(rootCmd),
+	}
+	activeShell = session
+	defer func() { activeShell = nil }()
+
+	// Commands install their own interrupt handlers; between commands an
+	// interrupt must not end the shell
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	go func() {
+		for range interrupts {
+			logger.Log.Debug("Ignoring interrupt between shell commands")
+		}
+	}()
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !output.IsTerminal() {
+		return session.runScript(os.Stdin, os.Stderr)
+	}
+	return session.runInteractive(fd)
+}
+
+// runScript executes each line of in and reports how many lines failed.
+func (s *shellSession) runScript(in io.Reader, errOut io.Writer) error {
+	failed := 0
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		done, err := s.execLine(scanner.Text())
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			failed++
+		}
+		if done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("✗ failed to read commands: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("✗ %d command(s) failed", failed)
+	}
+	return nil
+}
+
+func (s *shellSession) runInteractive(fd int) error {
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	terminal.History = loadShellHistory()
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		newLine, newPos, candidates := s.complete(line, pos)
+		if len(candidates) > 1 {
+			// The terminal is locked during the callback; Write waits for it
+			go fmt.Fprintln(terminal, strings.Join(candidates, "  "))
+		}
+		return newLine, newPos, newLine != line
+	}
+
+	for {
+		if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+			_ = terminal.SetSize(width, height)
+		}
+		terminal.SetPrompt(s.prompt())
+
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("✗ failed to set up terminal: %w", err)
+		}
+		line, err := terminal.ReadLine()
+		_ = term.Restore(fd, state)
+		if err == io.EOF {
+			fmt.Println()
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("✗ failed to read input: %w", err)
+		}
+
+		done, err := s.execLine(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+func (s *shellSession) prompt() string {
+	label := "etu"
+	if s.context != "" {
+		label += ":" + s.context
+	}
+	return fmt.Sprintf("%s %s> ", label, s.cwd)
+}
+
+// execLine runs one shell line. done reports that the shell should exit.
+func (s *shellSession) execLine(line string) (done bool, err error) {
+	words, err := splitShellLine(line)
+	if err != nil {
+		return false, err
+	}
+	if len(words) > 0 && words[0] == "etu" {
+		words = words[1:]
+	}
+	if len(words) == 0 {
+		return false, nil
+	}
+
+	switch words[0] {
+	case "exit", "quit":
+		return true, nil
+	case "pwd":
+		fmt.Println(s.cwd)
+		return false, nil
+	case "cd":
+		return false, s.changeDir(words[1:])
+	case "shell":
+		return false, fmt.Errorf("✗ already running in etu shell")
+	}
+
+	args := s.resolveArgs(words)
+	defer s.restoreFlags()
+	rootCmd.SetArgs(args)
+	defer rootCmd.SetArgs(nil)
+	return false, rootCmd.Execute()
+}
+
+func (s *shellSession) changeDir(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("✗ cd takes at most one prefix")
+	}
+
+	target := "/"
+	if len(args) == 1 {
+		target = args[0]
+	}
+	if target == "-" {
+		target = s.prevCwd
+	}
+	target = resolveShellKey(s.cwd, target)
+	if target != "/" {
+		target = strings.TrimSuffix(target, "/")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+	resp, err := s.client.GetWithOptions(ctx, lsDir(target), &client.GetOptions{Prefix: true, CountOnly: true})
+	if err != nil {
+		return wrapContextError(err)
+	}
+	if resp.Count == 0 {
+		output.Warning(fmt.Sprintf("No keys under %s", lsDir(target)))
+	}
+
+	s.prevCwd, s.cwd = s.cwd, target
+	return nil
+}
+
+// resolveArgs rewrites the key arguments of words relative to the working
+// prefix and fills in the working prefix for commands that default to it.
+func (s *shellSession) resolveArgs(words []string) []string {
+	cmd, _, err := rootCmd.Find(words)
+	if err != nil || cmd == rootCmd {
+		return words
+	}
+	spec, ok := shellKeyArgs[cmd.Name()]
+	if !ok {
+		return words
+	}
+
+	isKeyFlag := func(name string) bool {
+		for _, f := range spec.flags {
+			if f == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	args := append([]string{}, words[:1]...)
+	positional := 0
+	sawCwdFlag := false
+	keyPositional := func(arg string) string {
+		positional++
+		if spec.args < 0 || positional <= spec.args {
+			return resolveShellKey(s.cwd, arg)
+		}
+		return arg
+	}
+
+	rest := words[1:]
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		switch {
+		case arg == "--":
+			args = append(args, arg)
+			for _, a := range rest[i+1:] {
+				args = append(args, keyPositional(a))
+			}
+			i = len(rest)
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			if name == spec.cwdFlag {
+				sawCwdFlag = true
+			}
+			switch {
+			case hasValue && isKeyFlag(name):
+				args = append(args, "--"+name+"="+resolveShellKey(s.cwd, value))
+			case !hasValue && flagTakesValue(lookupFlag(cmd, name, false)) && i+1 < len(rest):
+				value = rest[i+1]
+				if isKeyFlag(name) {
+					value = resolveShellKey(s.cwd, value)
+				}
+				args = append(args, arg, value)
+				i++
+			default:
+				args = append(args, arg)
+			}
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			args = append(args, arg)
+			// In a group like -lo json, the first flag that takes a value
+			// consumes the rest of the group or the next word
+			for j := 1; j < len(arg); j++ {
+				if !flagTakesValue(lookupFlag(cmd, arg[j:j+1], true)) {
+					continue
+				}
+				if j == len(arg)-1 && i+1 < len(rest) {
+					args = append(args, rest[i+1])
+					i++
+				}
+				break
+			}
+		default:
+			args = append(args, keyPositional(arg))
+		}
+	}
+
+	if spec.defaultToCwd && positional == 0 {
+		args = append(args, s.cwd)
+	}
+	if spec.cwdFlag != "" && !sawCwdFlag && s.cwd != "/" {
+		args = append(args, "--"+spec.cwdFlag, lsDir(s.cwd))
+	}
+	return args
+}
+
+// lookupFlag finds a flag of cmd by long name, or by shorthand when short
+// is set, including the persistent flags of its parents.
+func lookupFlag(cmd *cobra.Command, name string, short bool) *pflag.Flag {
+	for _, flags := range []*pflag.FlagSet{cmd.Flags(), cmd.InheritedFlags()} {
+		f := flags.Lookup(name)
+		if short {
+			f = flags.ShorthandLookup(name)
+		}
+		if f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// flagTakesValue reports whether f needs an argument. Unknown flags are
+// treated as switches and left for cobra to report.
+func flagTakesValue(f *pflag.Flag) bool {
+	return f != nil && f.NoOptDefVal == ""
+}
+
+// resolveShellKey resolves a key relative to cwd. Absolute keys are
+// returned unchanged; a trailing '/' is kept.
+func resolveShellKey(cwd, key string) string {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return key
+	}
+	resolved := path.Join(cwd, key)
+	if strings.HasSuffix(key, "/") && resolved != "/" {
+		resolved += "/"
+	}
+	return resolved
+}
+
+// splitShellLine splits line into words. Single quotes keep text literally,
+// double quotes allow backslash escapes, and a backslash outside quotes
+// escapes the next character.
+func splitShellLine(line string) ([]string, error) {
+	var (
+		words   []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("✗ unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("✗ line ends with an unfinished escape")
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// complete returns line with the word before pos completed, and the
+// candidates when more than one matches.
+func (s *shellSession) complete(line string, pos int) (string, int, []string) {
+	head, tail := line[:pos], line[pos:]
+	start := strings.LastIndexAny(head, " \t") + 1
+	word := head[start:]
+
+	before, err := splitShellLine(head[:start])
+	if err != nil {
+		return line, pos, nil
+	}
+	if len(before) > 0 && before[0] == "etu" {
+		before = before[1:]
+	}
+
+	var candidates []string
+	dirsOnly := false
+	switch {
+	case len(before) == 0:
+		candidates = shellCommandNames(word)
+	case strings.HasPrefix(word, "-"):
+		if cmd, _, err := rootCmd.Find(before); err == nil {
+			candidates = flagNames(cmd, word)
+		}
+	case before[0] == "cd":
+		dirsOnly = true
+		candidates = s.keyCandidates(word, dirsOnly)
+	default:
+		if cmd, _, err := rootCmd.Find(before); err == nil {
+			if _, ok := shellKeyArgs[cmd.Name()]; ok {
+				candidates = s.keyCandidates(word, dirsOnly)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		return line, pos, nil
+	}
+
+	completed := candidates[0]
+	if len(candidates) > 1 {
+		completed = commonPrefix(candidates)
+	} else if !strings.HasSuffix(completed, "/") {
+		completed += " "
+	}
+	if len(completed) < len(word) {
+		completed = word
+	}
+
+	newHead := head[:start] + completed
+	return newHead + tail, len(newHead), candidates
+}
+
+// keyCandidates lists the children whose path starts with word, resolved
+// against the working prefix. Directories end with '/'.
+func (s *shellSession) keyCandidates(word string, dirsOnly bool) []string {
+	dirPart := word[:strings.LastIndex(word, "/")+1]
+	namePrefix := word[len(dirPart):]
+
+	dir := s.cwd
+	if dirPart != "" {
+		dir = resolveShellKey(s.cwd, dirPart)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
+	defer cancel()
+	children, _, err := client.ReadChildren(ctx, s.client, lsDir(dir), &client.PageOptions{KeysOnly: true})
+	if err != nil {
+		return nil
+	}
+
+	var candidates []string
+	for _, child := range children {
+		if !strings.HasPrefix(child.Name, namePrefix) || (dirsOnly && !child.Dir) {
+			continue
+		}
+		candidate := dirPart + child.Name
+		if child.Dir {
+			candidate += "/"
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+func shellCommandNames(prefix string) []string {
+	names := []string{"cd", "pwd", "exit", "quit"}
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.IsAvailableCommand() && cmd.Name() != "shell" {
+			names = append(names, cmd.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+func flagNames(cmd *cobra.Command, prefix string) []string {
+	var names []string
+	seen := map[string]bool{}
+	add := func(f *pflag.Flag) {
+		name := "--" + f.Name
+		if f.Hidden || seen[name] || !strings.HasPrefix(name, prefix) {
+			return
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	cmd.Flags().VisitAll(add)
+	cmd.InheritedFlags().VisitAll(add)
+	sort.Strings(names)
+	return names
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// snapshotFlags records the value of every flag in the command tree, so each
+// shell line starts from the flags the shell itself was started with.
+func snapshotFlags(root *cobra.Command) map[*pflag.Flag]flagState {
+	states := map[*pflag.Flag]flagState{}
+	visitCommandFlags(root, func(f *pflag.Flag) {
+		states[f] = flagState{value: f.Value.String(), changed: f.Changed}
+	})
+	return states
+}
+
+// restoreFlags resets every flag to its value when the shell started. Flags
+// added since then, such as cobra's help flags, go back to their defaults.
+func (s *shellSession) restoreFlags() {
+	visitCommandFlags(rootCmd, func(f *pflag.Flag) {
+		state, ok := s.flags[f]
+		if !ok {
+			state = flagState{value: f.DefValue}
+		}
+		if slice, isSlice := f.Value.(pflag.SliceValue); isSlice {
+			_ = slice.Replace(parseSliceFlag(state.value))
+		} else {
+			_ = f.Value.Set(state.value)
+		}
+		f.Changed = state.changed
+	})
+}
+
+func visitCommandFlags(cmd *cobra.Command, fn func(*pflag.Flag)) {
+	cmd.Flags().VisitAll(fn)
+	cmd.PersistentFlags().VisitAll(fn)
+	for _, child := range cmd.Commands() {
+		visitCommandFlags(child, fn)
+	}
+}
+
+// parseSliceFlag parses the "[a,b]" form pflag uses to print slice flags.
+func parseSliceFlag(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	if value == "" {
+		return []string{}
+	}
+	values, err := csv.NewReader(strings.NewReader(value)).Read()
+	if err != nil {
+		return []string{value}
+	}
+	return values
+}
+
+// clientFor returns the shared client when cfg matches the settings it was
+// created with, or nil.
+func (s *shellSession) clientFor(cfg *client.Config) client.EtcdClient {
+	if s == nil || !reflect.DeepEqual(s.config, *cfg) {
+		return nil
+	}
+	return s.client
+}
+
+// shellHistory is a bounded history that is appended to a file.
+type shellHistory struct {
+	entries []string
+	path    string
+}
+
+func loadShellHistory() *shellHistory {
+	h := &shellHistory{}
+	configPath, err := config.GetConfigPath()
+	if err != nil {
+		return h
+	}
+	h.path = filepath.Join(filepath.Dir(configPath), "shell_history")
+
+	data, err := os.ReadFile(h.path)
+	if err != nil {
+		return h
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if len(h.entries) > shellHistoryLimit {
+		h.entries = h.entries[len(h.entries)-shellHistoryLimit:]
+	}
+	return h
+}
+
+// Add records entry and appends it to the history file. Repeated lines are
+// stored once.
+func (h *shellHistory) Add(entry string) {
+	entry = strings.TrimSpace(entry)
+	if entry == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > shellHistoryLimit {
+		h.entries = h.entries[1:]
+	}
+
+	if h.path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, entry)
+}
+
+// Len returns the number of entries.
+func (h *shellHistory) Len() int {
+	return len(h.entries)
+}
+
+// At returns the entry idx lines back; 0 is the most recent.
+func (h *shellHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
@@ -0,0 +1,353 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/output"
+	"github.com/kazuma-desu/etu/pkg/testutil"
+)
+
+// treeMock serves range and exact reads over a fixed set of keys.
+func treeMock(keys ...string) *client.MockClient {
+	sort.Strings(keys)
+	return &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, key string, opts *client.GetOptions) (*client.GetResponse, error) {
+			resp := &client.GetResponse{Revision: 1}
+			for _, k := range keys {
+				var match bool
+				switch {
+				case opts == nil:
+					match = k == key
+				case opts.Prefix:
+					match = strings.HasPrefix(k, key)
+				case opts.RangeEnd != "":
+					match = k >= key && k < opts.RangeEnd
+				default:
+					match = k == key
+				}
+				if !match {
+					continue
+				}
+				resp.Count++
+				if opts == nil || !opts.CountOnly {
+					resp.Kvs = append(resp.Kvs, &client.KeyValue{Key: k, Value: "v"})
+				}
+			}
+			return resp, nil
+		},
+	}
+}
+
+// startTestShell makes mock the shared client of a shell session for the
+// current context of a temporary config.
+func startTestShell(t *testing.T, mock client.EtcdClient) *shellSession {
+	t.Helper()
+	t.Setenv("ETUCONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	require.NoError(t, config.SaveConfig(&config.Config{
+		CurrentContext: "dev",
+		Contexts: map[string]*config.ContextConfig{
+			"dev": {Endpoints: []string{"http://localhost:2379"}},
+		},
+	}))
+
+	cfg, err := config.GetEtcdConfigWithContext("")
+	require.NoError(t, err)
+
+	// Other tests change the global format directly
+	oldFormat := outputFormat
+	outputFormat = output.FormatSimple.String()
+
+	session := &shellSession{
+		client:  mock,
+		config:  *cfg,
+		context: "dev",
+		cwd:     "/",
+		prevCwd: "/",
+		flags:   snapshotFlags(rootCmd),
+	}
+	activeShell = session
+	t.Cleanup(func() {
+		session.restoreFlags()
+		activeShell = nil
+		outputFormat = oldFormat
+	})
+	return session
+}
+
+func TestSplitShellLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"plain words", "get /a  --prefix", []string{"get", "/a", "--prefix"}},
+		{"single quotes", `put /a 'hello world'`, []string{"put", "/a", "hello world"}},
+		{"double quotes with escape", `put /a "say \"hi\""`, []string{"put", "/a", `say "hi"`}},
+		{"backslash escapes space", `get /a\ b`, []string{"get", "/a b"}},
+		{"empty quotes", `put /a ''`, []string{"put", "/a", ""}},
+		{"blank line", "   ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitShellLine(tt.line)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSplitShellLine_Errors(t *testing.T) {
+	_, err := splitShellLine(`put /a "open`)
+	assert.EqualError(t, err, `✗ unterminated " quote`)
+
+	_, err = splitShellLine(`get /a\`)
+	assert.EqualError(t, err, "✗ line ends with an unfinished escape")
+}
+
+func TestResolveShellKey(t *testing.T) {
+	tests := []struct {
+		cwd, key, want string
+	}{
+		{"/app", "/abs", "/abs"},
+		{"/app", "name", "/app/name"},
+		{"/app", "config/", "/app/config/"},
+		{"/app/config", "..", "/app"},
+		{"/app", "../other", "/other"},
+		{"/app", ".", "/app"},
+		{"/", "..", "/"},
+		{"/app", "*/db", "/app/*/db"},
+		{"/app", "", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, resolveShellKey(tt.cwd, tt.key), "cwd=%s key=%s", tt.cwd, tt.key)
+	}
+}
+
+func TestShellResolveArgs(t *testing.T) {
+	s := &shellSession{cwd: "/app"}
+
+	tests := []struct {
+		name string
+		line []string
+		want []string
+	}{
+		{"relative key", []string{"get", "name"}, []string{"get", "/app/name"}},
+		{"range end", []string{"get", "a", "z"}, []string{"get", "/app/a", "/app/z"}},
+		{"flag value skipped", []string{"get", "-o", "json", "name"}, []string{"get", "-o", "json", "/app/name"}},
+		{"long flag value skipped", []string{"get", "--output", "json", "name"}, []string{"get", "--output", "json", "/app/name"}},
+		{"absolute key kept", []string{"get", "/x"}, []string{"get", "/x"}},
+		{"put value kept", []string{"put", "k", "v"}, []string{"put", "/app/k", "v"}},
+		{"put from stdin", []string{"put", "k", "-"}, []string{"put", "/app/k", "-"}},
+		{"two keys", []string{"cp", "a", "b"}, []string{"cp", "/app/a", "/app/b"}},
+		{"ls defaults to cwd", []string{"ls", "-l"}, []string{"ls", "-l", "/app"}},
+		{"ls relative", []string{"ls", "config"}, []string{"ls", "/app/config"}},
+		{"grep defaults prefix", []string{"grep", "host"}, []string{"grep", "host", "--prefix", "/app/"}},
+		{"grep prefix given", []string{"grep", "host", "--prefix=config"}, []string{"grep", "host", "--prefix=/app/config"}},
+		{"export prefix", []string{"export", "--prefix", "config"}, []string{"export", "--prefix", "/app/config"}},
+		{"after double dash", []string{"get", "--", "-odd"}, []string{"get", "--", "/app/-odd"}},
+		{"non-key command", []string{"config", "use-context", "prod"}, []string{"config", "use-context", "prod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.resolveArgs(tt.line))
+		})
+	}
+}
+
+func TestShellResolveArgs_Root(t *testing.T) {
+	s := &shellSession{cwd: "/"}
+
+	assert.Equal(t, []string{"grep", "host"}, s.resolveArgs([]string{"grep", "host"}))
+	assert.Equal(t, []string{"ls", "/"}, s.resolveArgs([]string{"ls"}))
+}
+
+func TestShellExecLine_UsesSharedClient(t *testing.T) {
+	mock := treeMock("/app/name", "/app/port")
+	s := startTestShell(t, mock)
+	s.cwd = "/app"
+
+	out, err := testutil.CaptureStdout(func() error {
+		_, err := s.execLine("get name")
+		return err
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "/app/name")
+	require.Len(t, mock.GetWithOptionsCalls, 1)
+	assert.Equal(t, "/app/name", mock.GetWithOptionsCalls[0].Key)
+	assert.False(t, mock.CloseCalled, "commands must not close the shared client")
+}
+
+func TestShellExecLine_ResetsFlags(t *testing.T) {
+	mock := treeMock("/app/name", "/app/port")
+	s := startTestShell(t, mock)
+
+	_, err := testutil.CaptureStdout(func() error {
+		if _, err := s.execLine("get /app --prefix -o json"); err != nil {
+			return err
+		}
+		_, err := s.execLine("get /app/name")
+		return err
+	})
+	require.NoError(t, err)
+
+	require.Len(t, mock.GetWithOptionsCalls, 2)
+	assert.True(t, mock.GetWithOptionsCalls[0].Opts.Prefix)
+	assert.False(t, mock.GetWithOptionsCalls[1].Opts.Prefix, "--prefix must not carry over")
+	assert.Equal(t, "simple", outputFormat)
+}
+
+func TestShellExecLine_Builtins(t *testing.T) {
+	s := startTestShell(t, treeMock("/app/config/db"))
+
+	done, err := s.execLine("cd app/config")
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, "/app/config", s.cwd)
+
+	out, err := testutil.CaptureStdout(func() error {
+		_, err := s.execLine("pwd")
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/app/config\n", out)
+
+	_, err = s.execLine("cd ..")
+	require.NoError(t, err)
+	assert.Equal(t, "/app", s.cwd)
+
+	_, err = s.execLine("cd -")
+	require.NoError(t, err)
+	assert.Equal(t, "/app/config", s.cwd)
+
+	_, err = s.execLine("cd")
+	require.NoError(t, err)
+	assert.Equal(t, "/", s.cwd)
+
+	_, err = s.execLine("shell")
+	assert.EqualError(t, err, "✗ already running in etu shell")
+
+	done, err = s.execLine("exit")
+	require.NoError(t, err)
+	assert.True(t, done)
+}
+
+func TestShellChangeDir_ReadError(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return nil, errors.New("unavailable")
+		},
+	}
+	s := &shellSession{client: mock, cwd: "/", prevCwd: "/"}
+
+	err := s.changeDir([]string{"/app"})
+	assert.EqualError(t, err, "unavailable")
+	assert.Equal(t, "/", s.cwd)
+
+	err = s.changeDir([]string{"a", "b"})
+	assert.EqualError(t, err, "✗ cd takes at most one prefix")
+}
+
+func TestShellRunScript(t *testing.T) {
+	s := startTestShell(t, treeMock("/app/name"))
+
+	var errOut strings.Builder
+	_, err := testutil.CaptureStdout(func() error {
+		return s.runScript(strings.NewReader("cd /app\n\nget 'unclosed\nexit\nget name\n"), &errOut)
+	})
+
+	assert.EqualError(t, err, "✗ 1 command(s) failed")
+	assert.Contains(t, errOut.String(), "unterminated")
+	assert.Equal(t, "/app", s.cwd)
+}
+
+func TestShellComplete(t *testing.T) {
+	s := &shellSession{client: treeMock("/app/config/db", "/app/config/port", "/app/name", "/apple"), cwd: "/"}
+
+	tests := []struct {
+		name       string
+		line       string
+		want       string
+		candidates []string
+	}{
+		{"command name", "hist", "history ", []string{"history"}},
+		{"common prefix of dir and key", "get ap", "get app", []string{"app/", "apple"}},
+		{"unique key", "get app/n", "get app/name ", []string{"app/name"}},
+		{"descends into dir", "ls app/c", "ls app/config/", []string{"app/config/"}},
+		{"common prefix", "get /app/config/", "get /app/config/", []string{"/app/config/db", "/app/config/port"}},
+		{"cd lists dirs only", "cd app/", "cd app/config/", []string{"app/config/"}},
+		{"flag", "get --pref", "get --prefix ", []string{"--prefix"}},
+		{"common flag prefix", "get --pre", "get --pre", []string{"--prefix", "--preserve-types"}},
+		{"non-key command", "config use", "config use", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, pos, candidates := s.complete(tt.line, len(tt.line))
+			assert.Equal(t, tt.want, line)
+			assert.Equal(t, len(tt.want), pos)
+			assert.Equal(t, tt.candidates, candidates)
+		})
+	}
+}
+
+func TestShellComplete_KeepsTextAfterCursor(t *testing.T) {
+	s := &shellSession{client: treeMock("/app/name"), cwd: "/app"}
+
+	line, pos, _ := s.complete("get na -o json", len("get na"))
+	assert.Equal(t, "get name  -o json", line)
+	assert.Equal(t, len("get name "), pos)
+}
+
+func TestParseSliceFlag(t *testing.T) {
+	assert.Equal(t, []string{}, parseSliceFlag("[]"))
+	assert.Equal(t, []string{"a", "b"}, parseSliceFlag("[a,b]"))
+	assert.Equal(t, []string{"a,b", "c"}, parseSliceFlag(`["a,b",c]`))
+}
+
+func TestShellHistory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ETUCONFIG", filepath.Join(dir, "config.yaml"))
+
+	h := loadShellHistory()
+	h.Add("ls")
+	h.Add("get /a")
+	h.Add("get /a ")
+	h.Add("")
+
+	assert.Equal(t, 2, h.Len())
+	assert.Equal(t, "get /a", h.At(0))
+	assert.Equal(t, "ls", h.At(1))
+
+	data, err := os.ReadFile(filepath.Join(dir, "shell_history"))
+	require.NoError(t, err)
+	assert.Equal(t, "ls\nget /a\n", string(data))
+
+	reloaded := loadShellHistory()
+	assert.Equal(t, 2, reloaded.Len())
+	assert.Equal(t, "get /a", reloaded.At(0))
+}
+
+func TestShellClientFor(t *testing.T) {
+	mock := &client.MockClient{}
+	s := &shellSession{client: mock, config: client.Config{Endpoints: []string{"http://a:2379"}}}
+
+	assert.Equal(t, mock, s.clientFor(&client.Config{Endpoints: []string{"http://a:2379"}}))
+	assert.Nil(t, s.clientFor(&client.Config{Endpoints: []string{"http://b:2379"}}))
+
+	var none *shellSession
+	assert.Nil(t, none.clientFor(&client.Config{}))
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/config"
+	"github.com/kazuma-desu/etu/pkg/output"
+	"github.com/kazuma-desu/etu/pkg/tui"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui [prefix]",
+	Short: "Browse keys in an interactive full-screen UI",
+	Long: `Browse etcd in a full-screen terminal UI.
+
+The left pane lists one directory level at a time, like 'etu ls'. The right
+pane shows the selected value, pretty-printed when it is JSON or YAML.
+
+Keys:
+  ↑/↓ j/k      move              enter/→ l   open directory
+  ←/h          parent directory  pgup/pgdn   scroll value
+  e            edit value        ctrl+s      save (in editor)
+  d            delete key        /           search key names
+  c            switch context    w           toggle live updates
+  r            reload            q           quit
+
+Edits and deletes only apply if the key still has the revision that was
+displayed; if someone else changed it first, nothing is written. Live updates
+watch the current directory and refresh the panes as keys change.`,
+	Example: `  # Browse from the top
+  etu ui
+
+  # Start in /app
+  etu ui /app
+
+  # Browse a specific context
+  etu ui --context prod`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runUI,
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+	registerKeyCompletion(uiCmd, 1)
+}
+
+func runUI(_ *cobra.Command, args []string) error {
+	root := "/"
+	if len(args) > 0 {
+		root = args[0]
+	}
+	if err := validateKeyPrefix(root); err != nil {
+		return err
+	}
+	if !output.IsTerminal() {
+		return fmt.Errorf("✗ etu ui needs an interactive terminal")
+	}
+	if globalPasswordStdin {
+		return fmt.Errorf("✗ --password-stdin is not supported by ui: the terminal is needed for input")
+	}
+
+	etcdClient, cleanup, err := connectContext(contextName)
+	if err != nil {
+		return err
+	}
+
+	contexts, _ := completeContextNames(nil, nil, "")
+	return tui.Run(tui.Options{
+		Client:   etcdClient,
+		Cleanup:  cleanup,
+		Connect:  connectContext,
+		Context:  resolveContextName(),
+		Contexts: contexts,
+		Root:     lsDir(root),
+		Timeout:  operationTimeout,
+	})
+}
+
+// connectContext opens a client for the named context, or the current
+// context if name is empty.
+func connectContext(name string) (client.EtcdClient, func(), error) {
+	cfg, err := config.GetEtcdConfigWithContext(name)
+	if err != nil {
+		return nil, nil, wrapNotConnectedError(err)
+	}
+	return newEtcdClient(cfg)
+}
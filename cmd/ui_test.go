@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUI_InvalidPrefix(t *testing.T) {
+	err := runUI(uiCmd, []string{"app"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "key must start with '/'")
+}
+
+func TestRunUI_RequiresTerminal(t *testing.T) {
+	// Tests never run with stdout attached to a terminal
+	err := runUI(uiCmd, []string{"/app"})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "needs an interactive terminal")
+}
@@ -23,6 +23,9 @@ var (
   # Strict mode (warnings as errors)
   etu validate -f config.txt --strict
 
+  # Validate the keys as they will be written
+  etu validate -f service.yaml --prefix /services/api --key-map keys.yaml
+
   # JSON output for CI/CD
   etu validate -f config.txt -o json`,
 		RunE: runValidate,
@@ -33,17 +36,26 @@ func init() {
 	rootCmd.AddCommand(validateCmd)
 
 	validateCmd.Flags().StringVarP(&validateOpts.FilePath, "file", "f", "",
-		"path to configuration file (required)")
+		"path to configuration file or '-' for stdin (required)")
 	validateCmd.Flags().StringVar((*string)(&validateOpts.Format), "format", "",
-		"file format: auto, etcdctl (overrides config)")
+		"file format: auto, etcdctl, yaml, json, toml, dotenv, properties (overrides config)")
+	validateCmd.Flags().StringVar(&validateOpts.Prefix, "prefix", "",
+		"mount the file's keys under this prefix")
+	validateCmd.Flags().StringVar(&validateOpts.KeyMap, "key-map", "",
+		"YAML file of rules that rewrite keys before validation")
 	validateCmd.Flags().BoolVar(&validateOpts.Strict, "strict", false,
 		"treat validation warnings as errors (overrides config)")
 
+	addFlattenFlags(validateCmd)
+	addTemplateFlags(validateCmd)
+
 	if err := validateCmd.MarkFlagRequired("file"); err != nil {
 		panic(fmt.Sprintf("failed to mark flag as required: %v", err))
 	}
 
 	registerFileCompletion(validateCmd, "file")
+	registerFileCompletion(validateCmd, "key-map")
+	registerPrefixCompletion(validateCmd, "prefix")
 }
 
 func runValidate(cmd *cobra.Command, _ []string) error {
@@ -65,6 +77,17 @@ func runValidate(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
+	if err := rewriteKeys(pairs, validateOpts.KeyMap, validateOpts.Prefix); err != nil {
+		return err
+	}
+	// Keys clash within the context they resolve to, so a document without a
+	// context and one naming the selected context can't set the same key
+	current := resolveContextName()
+	for _, pair := range pairs {
+		if pair.Context == "" {
+			pair.Context = current
+		}
+	}
 
 	strict := resolveStrictOption(validateOpts.Strict, cmd.Flags().Changed("strict"), appCfg)
 
@@ -74,7 +97,7 @@ func runValidate(cmd *cobra.Command, _ []string) error {
 		logVerboseInfo("Validating configuration")
 	}
 
-	v := validator.NewValidator(strict)
+	v := validator.NewValidatorWithLayout(strict, flattenOpts.Layout)
 	result := v.Validate(pairs)
 
 	if err := output.PrintValidationWithFormat(result, strict, outputFormat); err != nil {
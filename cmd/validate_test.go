@@ -7,6 +7,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/testutil"
 )
 
 func TestValidateCommand(t *testing.T) {
@@ -53,6 +56,56 @@ value
 		assert.Contains(t, err.Error(), "validation failed")
 	})
 
+	t.Run("Validate reports file positions", func(t *testing.T) {
+		configFile := filepath.Join(t.TempDir(), "app.yaml")
+		content := "app:\n  name: myapp\n  bad key: x\n"
+		require.NoError(t, os.WriteFile(configFile, []byte(content), 0644))
+
+		validateOpts.FilePath = configFile
+		validateOpts.Format = ""
+		validateOpts.Strict = false
+		origFormat := outputFormat
+		outputFormat = "simple"
+		defer func() { outputFormat = origFormat }()
+
+		output, err := testutil.CaptureStdout(func() error {
+			return runValidate(validateCmd, []string{})
+		})
+		require.Error(t, err)
+		assert.Contains(t, output, configFile+":3:3: /app/bad key: key contains invalid characters")
+	})
+
+	t.Run("Validate reports duplicate keys as issues", func(t *testing.T) {
+		dir := t.TempDir()
+		etcdctlFile := filepath.Join(dir, "dup.txt")
+		require.NoError(t, os.WriteFile(etcdctlFile, []byte("/a\n1\n/a\n2\n"), 0644))
+		yamlFile := filepath.Join(dir, "app.yaml")
+		require.NoError(t, os.WriteFile(yamlFile, []byte("db:\n  host: a\n---\n$etu:\n  context: dev\ndb:\n  host: b\n"), 0644))
+
+		validateOpts.Strict = false
+		origFormat, origContext := outputFormat, contextName
+		outputFormat = "json"
+		contextName = "dev"
+		defer func() { outputFormat, contextName = origFormat, origContext }()
+
+		tests := []struct {
+			file, format, expected string
+		}{
+			{etcdctlFile, "etcdctl", "duplicate key found (first defined at " + etcdctlFile + ":1:1)"},
+			{yamlFile, "yaml", "duplicate key found (first defined at " + yamlFile + ":2:3)"},
+		}
+		for _, tt := range tests {
+			validateOpts.FilePath = tt.file
+			validateOpts.Format = models.FormatType(tt.format)
+			out, err := testutil.CaptureStdout(func() error {
+				return runValidate(validateCmd, []string{})
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "validation failed")
+			assert.Contains(t, out, tt.expected)
+		}
+	})
+
 	t.Run("Validate with strict mode", func(t *testing.T) {
 		tempDir := t.TempDir()
 		configFile := filepath.Join(tempDir, "strict.txt")
@@ -29,6 +29,8 @@ var (
 
 Monitors etcd for PUT and DELETE events on the specified key.
 Use --prefix to watch all keys with a given prefix.
+The key may be a glob pattern such as '/app/*/db/host'; the literal prefix
+is watched and only events for matching keys are printed.
 Use -o flag to control output format (simple=raw value, json=full event).
 Press Ctrl+C to stop watching.`,
 		Example: `  # Watch a single key
@@ -37,6 +39,9 @@ Press Ctrl+C to stop watching.`,
   # Watch all keys with a prefix
   etu watch /config/app/ --prefix
 
+  # Watch every timeout under /svc
+  etu watch '/svc/**/timeout'
+
   # Watch from a specific revision
   etu watch /config/app/ --prefix --rev 100
 
@@ -56,6 +61,8 @@ func init() {
 		"revision to start watching from (0 = current)")
 	watchCmd.Flags().BoolVar(&watchOpts.prevKV, "prev-kv", false,
 		"include previous key-value pair in events")
+
+	registerKeyCompletion(watchCmd, 1)
 }
 
 func runWatch(_ *cobra.Command, args []string) error {
@@ -73,6 +80,14 @@ func runWatch(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("✗ invalid --rev: must be non-negative")
 	}
 
+	pattern, err := compileKeyPattern(key)
+	if err != nil {
+		return err
+	}
+	if pattern != nil && watchOpts.prefix {
+		return fmt.Errorf("✗ a glob pattern cannot be combined with --prefix")
+	}
+
 	cfg, err := config.GetEtcdConfigWithContext(contextName)
 	if err != nil {
 		return wrapNotConnectedError(err)
@@ -108,8 +123,15 @@ func runWatch(_ *cobra.Command, args []string) error {
 		PrevKV:   watchOpts.prevKV,
 	}
 
+	if pattern != nil {
+		key = pattern.Prefix()
+		opts.Prefix = true
+	}
+
 	if outputFormat != output.FormatJSON.String() {
-		if watchOpts.prefix {
+		if pattern != nil {
+			output.Info(fmt.Sprintf("Watching keys matching: %s", pattern))
+		} else if watchOpts.prefix {
 			output.Info(fmt.Sprintf("Watching keys with prefix: %s", key))
 		} else {
 			output.Info(fmt.Sprintf("Watching key: %s", key))
@@ -130,6 +152,9 @@ func runWatch(_ *cobra.Command, args []string) error {
 		}
 
 		for _, event := range resp.Events {
+			if pattern != nil && !pattern.Match(event.Key) {
+				continue
+			}
 			if err := printWatchEvent(event); err != nil {
 				return err
 			}
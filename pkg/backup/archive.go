@@ -0,0 +1,178 @@
+// Package backup reads and writes etu backup archives (.etub).
+//
+// An archive is a gzip-compressed stream of newline-delimited JSON. The first
+// line is a Header, every following line is an Entry. Keys and values are
+// base64-encoded so binary data survives unchanged. The header carries a
+// SHA-256 checksum over the entry lines to detect truncation or corruption.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// FormatName identifies etu backup archives in the header.
+	FormatName = "etub"
+
+	// Version is the archive format version written by Write.
+	Version = 1
+
+	checksumPrefix = "sha256:"
+)
+
+var (
+	// ErrNotArchive is returned when the input is not an etu backup archive.
+	ErrNotArchive = errors.New("not an etu backup archive")
+
+	// ErrChecksumMismatch is returned when the entries do not match the header checksum.
+	ErrChecksumMismatch = errors.New("backup checksum mismatch: archive is corrupted or truncated")
+)
+
+// Header describes the source and contents of a backup archive.
+type Header struct {
+	CreatedAt time.Time `json:"created_at"`
+	Format    string    `json:"format"`
+	Prefix    string    `json:"prefix"`
+	Context   string    `json:"context,omitempty"`
+	Checksum  string    `json:"checksum"`
+	Endpoints []string  `json:"endpoints,omitempty"`
+	Version   int       `json:"version"`
+	Count     int       `json:"count"`
+	Revision  int64     `json:"revision"`
+	ClusterID uint64    `json:"cluster_id,omitempty"`
+}
+
+// Entry is a single key in a backup archive.
+type Entry struct {
+	Key            string
+	Value          string
+	CreateRevision int64
+	ModRevision    int64
+	Version        int64
+	Lease          int64
+}
+
+// entryJSON is the on-disk form of an Entry. Byte slices encode as base64.
+type entryJSON struct {
+	Key            []byte `json:"key"`
+	Value          []byte `json:"value"`
+	CreateRevision int64  `json:"create_revision"`
+	ModRevision    int64  `json:"mod_revision"`
+	Version        int64  `json:"version"`
+	Lease          int64  `json:"lease,omitempty"`
+}
+
+// Write writes header and entries as a compressed archive. It fills in the
+// header's Format, Version, Count and Checksum fields.
+func Write(w io.Writer, header *Header, entries []Entry) error {
+	var body bytes.Buffer
+	hash := sha256.New()
+	for _, e := range entries {
+		line, err := json.Marshal(entryJSON{
+			Key:            []byte(e.Key),
+			Value:          []byte(e.Value),
+			CreateRevision: e.CreateRevision,
+			ModRevision:    e.ModRevision,
+			Version:        e.Version,
+			Lease:          e.Lease,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode key %q: %w", e.Key, err)
+		}
+		line = append(line, '\n')
+		hash.Write(line)
+		body.Write(line)
+	}
+
+	header.Format = FormatName
+	header.Version = Version
+	header.Count = len(entries)
+	header.Checksum = checksumPrefix + hex.EncodeToString(hash.Sum(nil))
+
+	headerLine, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to encode header: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(append(headerLine, '\n')); err != nil {
+		return err
+	}
+	if _, err := gz.Write(body.Bytes()); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read reads a compressed archive and verifies its version, entry count and checksum.
+func Read(r io.Reader) (*Header, []Entry, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrNotArchive, err)
+	}
+	defer gz.Close()
+
+	br := bufio.NewReader(gz)
+	header, err := readHeader(br)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hash := sha256.New()
+	entries := make([]Entry, 0, header.Count)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			hash.Write(line)
+			var e entryJSON
+			if jsonErr := json.Unmarshal(line, &e); jsonErr != nil {
+				return nil, nil, fmt.Errorf("failed to decode entry %d: %w", len(entries)+1, jsonErr)
+			}
+			entries = append(entries, Entry{
+				Key:            string(e.Key),
+				Value:          string(e.Value),
+				CreateRevision: e.CreateRevision,
+				ModRevision:    e.ModRevision,
+				Version:        e.Version,
+				Lease:          e.Lease,
+			})
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+	}
+
+	if len(entries) != header.Count || checksumPrefix+hex.EncodeToString(hash.Sum(nil)) != header.Checksum {
+		return nil, nil, ErrChecksumMismatch
+	}
+
+	return header, entries, nil
+}
+
+func readHeader(br *bufio.Reader) (*Header, error) {
+	line, err := br.ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read archive header: %w", err)
+	}
+
+	var header Header
+	if err := json.Unmarshal(line, &header); err != nil || header.Format != FormatName {
+		return nil, ErrNotArchive
+	}
+	if header.Version > Version {
+		return nil, fmt.Errorf("unsupported backup version %d (this etu supports up to %d)", header.Version, Version)
+	}
+	return &header, nil
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleEntries() []Entry {
+	return []Entry{
+		{Key: "/app/name", Value: "myapp", CreateRevision: 2, ModRevision: 5, Version: 3},
+		{Key: "/app/empty", Value: "", CreateRevision: 3, ModRevision: 3, Version: 1},
+		{Key: "/app/binary", Value: "\x00\xff\xfe", CreateRevision: 4, ModRevision: 4, Version: 1, Lease: 7587},
+		{Key: "/app/multi", Value: "a\nb\n", CreateRevision: 6, ModRevision: 6, Version: 1},
+	}
+}
+
+func TestWriteRead_RoundTrip(t *testing.T) {
+	header := &Header{
+		Prefix:    "/app",
+		Context:   "prod",
+		Endpoints: []string{"localhost:2379"},
+		ClusterID: 42,
+		Revision:  10,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, header, sampleEntries()))
+
+	assert.Equal(t, FormatName, header.Format)
+	assert.Equal(t, Version, header.Version)
+	assert.Equal(t, 4, header.Count)
+	assert.True(t, strings.HasPrefix(header.Checksum, "sha256:"))
+
+	got, entries, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, header, got)
+	assert.Equal(t, sampleEntries(), entries)
+}
+
+func TestWrite_IsCompressedNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, &Header{Prefix: "/app"}, sampleEntries()))
+
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(gz)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(string(raw), "\n"), "\n")
+	require.Len(t, lines, 5)
+	assert.Contains(t, lines[0], `"format":"etub"`)
+	assert.Contains(t, lines[3], `"lease":7587`)
+}
+
+func TestRead_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, &Header{Prefix: "/none"}, nil))
+
+	header, entries, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 0, header.Count)
+	assert.Empty(t, entries)
+}
+
+func TestRead_ChecksumMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, &Header{Prefix: "/app"}, sampleEntries()))
+
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	raw, err := io.ReadAll(gz)
+	require.NoError(t, err)
+
+	tampered := strings.Replace(string(raw), `"mod_revision":5`, `"mod_revision":6`, 1)
+	_, _, err = Read(gzipped(t, tampered))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	// Dropping the last entry is caught as well
+	lines := strings.SplitAfter(string(raw), "\n")
+	truncated := strings.Join(lines[:len(lines)-2], "")
+	_, _, err = Read(gzipped(t, truncated))
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+}
+
+func TestRead_NotArchive(t *testing.T) {
+	_, _, err := Read(strings.NewReader("plain text"))
+	assert.ErrorIs(t, err, ErrNotArchive)
+
+	_, _, err = Read(gzipped(t, `{"format":"other"}`+"\n"))
+	assert.ErrorIs(t, err, ErrNotArchive)
+}
+
+func TestRead_NewerVersion(t *testing.T) {
+	_, _, err := Read(gzipped(t, `{"format":"etub","version":99}`+"\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported backup version 99")
+}
+
+func gzipped(t *testing.T, content string) io.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return &buf
+}
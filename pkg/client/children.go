@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Child is an immediate child of a directory-style prefix: either a key
+// directly under it or a subtree collapsed into one entry.
+type Child struct {
+	// KV is the key-value for a key directly under the prefix, nil for a
+	// directory.
+	KV *KeyValue
+
+	// Name is the path segment below the prefix, without a trailing '/'.
+	Name string
+
+	// Keys is the number of keys below a directory.
+	Keys int64
+
+	// Dir reports whether the child is a subtree.
+	Dir bool
+}
+
+// Path returns the full key of a child key, or the prefix of a child
+// directory including its trailing '/'.
+func (c Child) Path(dir string) string {
+	if c.Dir {
+		return dir + c.Name + "/"
+	}
+	return dir + c.Name
+}
+
+// ListChildren returns the immediate children of dir, which should end in
+// '/', in key order, like ReadChildren. Directories are then counted with
+// count-only requests at the same revision.
+func ListChildren(ctx context.Context, reader EtcdReader, dir string, opts *PageOptions) ([]Child, error) {
+	children, revision, err := ReadChildren(ctx, reader, dir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range children {
+		if !children[i].Dir {
+			continue
+		}
+		path := children[i].Path(dir)
+		resp, err := reader.GetWithOptions(ctx, path, &GetOptions{
+			RangeEnd:  clientv3.GetPrefixRangeEnd(path),
+			CountOnly: true,
+			Revision:  revision,
+		})
+		if err != nil {
+			return nil, err
+		}
+		children[i].Keys = resp.Count
+	}
+
+	return children, nil
+}
+
+// ReadChildren returns the immediate children of dir, which should end in
+// '/', in key order, without counting the keys below directories. Keys are
+// read in pages, and once a page ends inside a subtree the rest of that
+// subtree is skipped, so large prefixes cost about one request per child
+// directory rather than one per key. It also returns the revision the
+// children were read at.
+func ReadChildren(ctx context.Context, reader EtcdReader, dir string, opts *PageOptions) ([]Child, int64, error) {
+	pageSize := DefaultPageSize
+	var revision int64
+	var keysOnly bool
+	if opts != nil {
+		if opts.PageSize > 0 {
+			pageSize = opts.PageSize
+		}
+		revision = opts.Revision
+		keysOnly = opts.KeysOnly
+	}
+
+	rangeEnd := clientv3.GetPrefixRangeEnd(dir)
+	key := dir
+	var children []Child
+
+	for {
+		resp, err := reader.GetWithOptions(ctx, key, &GetOptions{
+			RangeEnd: rangeEnd,
+			Limit:    pageSize,
+			Revision: revision,
+			KeysOnly: keysOnly,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		if revision == 0 {
+			revision = resp.Revision
+		}
+
+		for _, kv := range resp.Kvs {
+			rel := kv.Key[len(dir):]
+			slash := strings.IndexByte(rel, '/')
+			if slash < 0 {
+				children = append(children, Child{KV: kv, Name: rel})
+				continue
+			}
+
+			// Keys of one subtree are contiguous, so comparing with the
+			// previous child is enough to collapse them
+			name := rel[:slash]
+			if n := len(children); n > 0 && children[n-1].Dir && children[n-1].Name == name {
+				continue
+			}
+			children = append(children, Child{Name: name, Dir: true})
+		}
+
+		if len(resp.Kvs) == 0 || !resp.More {
+			break
+		}
+
+		key = resp.Kvs[len(resp.Kvs)-1].Key + "\x00"
+		if last := children[len(children)-1]; last.Dir {
+			key = clientv3.GetPrefixRangeEnd(last.Path(dir))
+		}
+	}
+
+	return children, revision, nil
+}
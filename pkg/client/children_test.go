@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListChildren(t *testing.T) {
+	keys := []string{
+		"/app/name",
+		"/app/config/host",
+		"/app/config/port",
+		"/app/config-old",
+		"/app/db/primary/host",
+		"/apple",
+	}
+	mock := rangeMock(keys, 7)
+
+	children, err := ListChildren(context.Background(), mock, "/app/", nil)
+	require.NoError(t, err)
+
+	// Byte order puts "config-old" before the "config/" subtree
+	require.Len(t, children, 4)
+	assert.Equal(t, "config-old", children[0].Name)
+	require.NotNil(t, children[0].KV)
+	assert.Equal(t, "/app/config-old", children[0].KV.Key)
+	assert.Equal(t, Child{Name: "config", Keys: 2, Dir: true}, children[1])
+	assert.Equal(t, Child{Name: "db", Keys: 1, Dir: true}, children[2])
+	assert.Equal(t, "name", children[3].Name)
+
+	assert.Equal(t, "/app/config/", children[1].Path("/app/"))
+	assert.Equal(t, "/app/name", children[3].Path("/app/"))
+}
+
+func TestListChildren_SkipsSubtrees(t *testing.T) {
+	keys := []string{"/a/x"}
+	for i := range 50 {
+		keys = append(keys, fmt.Sprintf("/a/big/%02d", i))
+	}
+	keys = append(keys, "/a/small/1", "/a/z")
+	mock := rangeMock(keys, 3)
+
+	children, err := ListChildren(context.Background(), mock, "/a/", &PageOptions{PageSize: 5, KeysOnly: true})
+	require.NoError(t, err)
+
+	require.Len(t, children, 4)
+	assert.Equal(t, int64(50), children[0].Keys)
+	assert.Equal(t, "small", children[1].Name)
+	assert.Equal(t, "x", children[2].Name)
+	assert.Equal(t, "z", children[3].Name)
+
+	// The page ending inside /a/big/ resumes after the whole subtree, and
+	// every request is pinned to the first page's revision
+	require.GreaterOrEqual(t, len(mock.GetWithOptionsCalls), 2)
+	assert.Equal(t, "/a/big0", mock.GetWithOptionsCalls[1].Key)
+	for _, call := range mock.GetWithOptionsCalls[1:] {
+		assert.Equal(t, int64(3), call.Opts.Revision)
+	}
+	assert.True(t, mock.GetWithOptionsCalls[0].Opts.KeysOnly)
+}
+
+func TestReadChildren_SkipsCounts(t *testing.T) {
+	mock := rangeMock([]string{"/a/dir/1", "/a/dir/2", "/a/key"}, 4)
+
+	children, revision, err := ReadChildren(context.Background(), mock, "/a/", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4), revision)
+	require.Len(t, children, 2)
+	assert.Equal(t, Child{Name: "dir", Dir: true}, children[0])
+	assert.Equal(t, "key", children[1].Name)
+	require.Len(t, mock.GetWithOptionsCalls, 1, "directories are not counted")
+}
+
+func TestListChildren_Empty(t *testing.T) {
+	children, err := ListChildren(context.Background(), rangeMock(nil, 1), "/none/", nil)
+	require.NoError(t, err)
+	assert.Empty(t, children)
+}
+
+func TestListChildren_Error(t *testing.T) {
+	mock := &MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *GetOptions) (*GetResponse, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	_, err := ListChildren(context.Background(), mock, "/app/", nil)
+	require.Error(t, err)
+}
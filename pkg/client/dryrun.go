@@ -102,6 +102,18 @@ func (d *DryRunClient) DeletePrefix(_ context.Context, prefix string) (int64, er
 	return 0, nil
 }
 
+// Txn records ops as individual operations. Guards are not checked.
+func (d *DryRunClient) Txn(_ context.Context, _ []TxnGuard, ops []TxnOp) (int64, error) {
+	for _, op := range ops {
+		recorded := Operation{Type: string(op.Type), Key: op.Key}
+		if op.Type == TxnOpPut {
+			recorded.Value = formatValue(op.Value)
+		}
+		d.operations = append(d.operations, recorded)
+	}
+	return 0, nil
+}
+
 func (d *DryRunClient) Close() error {
 	return nil
 }
@@ -196,6 +196,23 @@ func TestDryRunClient_Delete(t *testing.T) {
 	})
 }
 
+func TestDryRunClient_Txn(t *testing.T) {
+	client := NewDryRunClient()
+
+	_, err := client.Txn(context.Background(),
+		[]TxnGuard{{Key: "/old", ModRevision: 3}},
+		[]TxnOp{
+			{Type: TxnOpPut, Key: "/new", Value: "value"},
+			{Type: TxnOpDelete, Key: "/old"},
+		})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Operation{
+		{Type: "PUT", Key: "/new", Value: "value"},
+		{Type: "DELETE", Key: "/old"},
+	}, client.Operations())
+}
+
 func TestDryRunClient_DeletePrefix(t *testing.T) {
 	t.Run("records delete prefix operation", func(t *testing.T) {
 		client := NewDryRunClient()
@@ -9,6 +9,7 @@ import (
 	"os"
 	"time"
 
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/grpclog"
@@ -29,6 +30,10 @@ const (
 	WarnValueSize = 100 * 1024
 )
 
+// ErrCompacted is returned (wrapped) by reads at a revision that has been
+// compacted away. Use errors.Is to detect it.
+var ErrCompacted = rpctypes.ErrCompacted
+
 type Client struct {
 	client *clientv3.Client
 	config *Config
@@ -314,7 +319,9 @@ type KeyValue struct {
 type GetResponse struct {
 	Kvs   []*KeyValue
 	Count int64
-	More  bool
+	// Revision is the store revision the response was served at.
+	Revision int64
+	More     bool
 }
 
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
@@ -347,6 +354,9 @@ func (c *Client) GetWithOptions(ctx context.Context, key string, opts *GetOption
 		More:  resp.More,
 		Kvs:   make([]*KeyValue, len(resp.Kvs)),
 	}
+	if resp.Header != nil {
+		result.Revision = resp.Header.Revision
+	}
 
 	for i, kv := range resp.Kvs {
 		result.Kvs[i] = &KeyValue{
@@ -378,6 +388,39 @@ func (c *Client) DeletePrefix(ctx context.Context, prefix string) (int64, error)
 	return resp.Deleted, nil
 }
 
+func (c *Client) Txn(ctx context.Context, guards []TxnGuard, ops []TxnOp) (int64, error) {
+	if len(guards) > DefaultMaxOpsPerTxn || len(ops) > DefaultMaxOpsPerTxn {
+		return 0, fmt.Errorf("transaction too large: %d guards and %d ops (limit %d each)",
+			len(guards), len(ops), DefaultMaxOpsPerTxn)
+	}
+
+	cmps := make([]clientv3.Cmp, len(guards))
+	for i, g := range guards {
+		cmps[i] = clientv3.Compare(clientv3.ModRevision(g.Key), "=", g.ModRevision)
+	}
+
+	thenOps := make([]clientv3.Op, 0, len(ops))
+	for _, op := range ops {
+		switch op.Type {
+		case TxnOpPut:
+			thenOps = append(thenOps, clientv3.OpPut(op.Key, op.Value))
+		case TxnOpDelete:
+			thenOps = append(thenOps, clientv3.OpDelete(op.Key))
+		default:
+			return 0, fmt.Errorf("unknown transaction op %q for key %s", op.Type, op.Key)
+		}
+	}
+
+	resp, err := c.client.Txn(ctx).If(cmps...).Then(thenOps...).Commit()
+	if err != nil {
+		return 0, fmt.Errorf("transaction failed: %w", err)
+	}
+	if !resp.Succeeded {
+		return resp.Header.Revision, ErrTxnConflict
+	}
+	return resp.Header.Revision, nil
+}
+
 func (c *Client) Watch(ctx context.Context, key string, opts *WatchOptions) WatchChan {
 	ch := make(chan WatchResponse)
 
@@ -453,7 +496,7 @@ func (c *Client) Status(ctx context.Context, endpoint string) (*StatusResponse,
 		return nil, err
 	}
 
-	return &StatusResponse{
+	status := &StatusResponse{
 		Version:          resp.Version,
 		DbSize:           resp.DbSize,
 		Leader:           resp.Leader,
@@ -462,7 +505,11 @@ func (c *Client) Status(ctx context.Context, endpoint string) (*StatusResponse,
 		RaftAppliedIndex: resp.RaftAppliedIndex,
 		Errors:           resp.Errors,
 		IsLearner:        resp.IsLearner,
-	}, nil
+	}
+	if resp.Header != nil {
+		status.ClusterID = resp.Header.ClusterId
+	}
+	return status, nil
 }
 
 func warnLargeValues(log Logger, pairs []*models.ConfigPair) {
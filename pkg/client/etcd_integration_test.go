@@ -527,6 +527,49 @@ func TestGetWithOptions_Integration(t *testing.T) {
 	})
 }
 
+func TestTxn_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	endpoint := setupEtcdContainer(t)
+	client := newTestClient(t, endpoint)
+	ctx := testContext(t)
+
+	require.NoError(t, client.Put(ctx, "/txn/src", "value"))
+	resp, err := client.GetWithOptions(ctx, "/txn/src", nil)
+	require.NoError(t, err)
+	srcRev := resp.Kvs[0].ModRevision
+
+	t.Run("commits when guards hold", func(t *testing.T) {
+		rev, err := client.Txn(ctx,
+			[]TxnGuard{{Key: "/txn/src", ModRevision: srcRev}, {Key: "/txn/dst"}},
+			[]TxnOp{
+				{Type: TxnOpPut, Key: "/txn/dst", Value: "value"},
+				{Type: TxnOpDelete, Key: "/txn/src"},
+			})
+		require.NoError(t, err)
+		assert.Greater(t, rev, srcRev)
+
+		value, err := client.Get(ctx, "/txn/dst")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+		_, err = client.Get(ctx, "/txn/src")
+		assert.Error(t, err, "source should be deleted")
+	})
+
+	t.Run("aborts when a guard fails", func(t *testing.T) {
+		_, err := client.Txn(ctx,
+			[]TxnGuard{{Key: "/txn/dst"}},
+			[]TxnOp{{Type: TxnOpPut, Key: "/txn/dst", Value: "overwritten"}})
+		assert.ErrorIs(t, err, ErrTxnConflict)
+
+		value, err := client.Get(ctx, "/txn/dst")
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	})
+}
+
 func TestPutAllWithProgress_BatchOperations_Integration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -1066,3 +1109,27 @@ func TestClient_TLS_Integration(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestListChildren_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	endpoint := setupEtcdContainer(t)
+	client := newTestClient(t, endpoint)
+	ctx := testContext(t)
+
+	for _, key := range []string{"/ls/name", "/ls/config/host", "/ls/config/port", "/ls/db/primary/host"} {
+		require.NoError(t, client.Put(ctx, key, "value"))
+	}
+
+	children, err := ListChildren(ctx, client, "/ls/", &PageOptions{PageSize: 1})
+	require.NoError(t, err)
+
+	require.Len(t, children, 3)
+	assert.Equal(t, Child{Name: "config", Keys: 2, Dir: true}, children[0])
+	assert.Equal(t, Child{Name: "db", Keys: 1, Dir: true}, children[1])
+	assert.Equal(t, "name", children[2].Name)
+	require.NotNil(t, children[2].KV)
+	assert.Equal(t, "value", children[2].KV.Value)
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/kazuma-desu/etu/pkg/models"
@@ -155,6 +156,35 @@ type WatchOptions struct {
 	PrevKV bool
 }
 
+// TxnOpType is the kind of write inside a transaction.
+type TxnOpType string
+
+const (
+	// TxnOpPut writes Value to Key.
+	TxnOpPut TxnOpType = "PUT"
+	// TxnOpDelete deletes Key.
+	TxnOpDelete TxnOpType = "DELETE"
+)
+
+// TxnOp is a single write inside a transaction.
+type TxnOp struct {
+	Type  TxnOpType
+	Key   string
+	Value string
+}
+
+// TxnGuard is a precondition of a transaction: Key must still be at
+// ModRevision when the transaction commits. A ModRevision of 0 requires
+// the key to not exist.
+type TxnGuard struct {
+	Key         string
+	ModRevision int64
+}
+
+// ErrTxnConflict is returned by Txn when a guard no longer holds because a
+// guarded key was written concurrently. Use errors.Is to detect it.
+var ErrTxnConflict = errors.New("transaction aborted: a guarded key was modified concurrently")
+
 // EtcdReader defines read operations on etcd.
 // Implementations must be safe for concurrent use.
 type EtcdReader interface {
@@ -180,6 +210,11 @@ type EtcdWriter interface {
 	PutAllWithOptions(ctx context.Context, pairs []*models.ConfigPair, onProgress ProgressFunc, opts *BatchOptions) (*PutAllResult, error)
 	Delete(ctx context.Context, key string) (int64, error)
 	DeletePrefix(ctx context.Context, prefix string) (int64, error)
+
+	// Txn applies ops atomically if every guard holds and returns the
+	// revision of the commit. It returns ErrTxnConflict if a guard fails.
+	// Guards and ops are each limited to DefaultMaxOpsPerTxn entries.
+	Txn(ctx context.Context, guards []TxnGuard, ops []TxnOp) (int64, error)
 }
 
 // StatusResponse contains the status information for an etcd cluster member.
@@ -194,6 +229,9 @@ type StatusResponse struct {
 	// Leader is the member ID of the leader.
 	Leader uint64
 
+	// ClusterID is the ID of the cluster the member belongs to.
+	ClusterID uint64
+
 	// RaftIndex is the current raft index.
 	RaftIndex uint64
 
@@ -20,6 +20,11 @@ type PutAllWithProgressCall struct {
 	Pairs []*models.ConfigPair
 }
 
+type TxnCall struct {
+	Guards []TxnGuard
+	Ops    []TxnOp
+}
+
 type MockClient struct {
 	PutFunc                func(ctx context.Context, key, value string) error
 	PutAllFunc             func(ctx context.Context, pairs []*models.ConfigPair) error
@@ -32,6 +37,7 @@ type MockClient struct {
 	CloseFunc              func() error
 	StatusFunc             func(ctx context.Context, endpoint string) (*StatusResponse, error)
 	WatchFunc              func(ctx context.Context, key string, opts *WatchOptions) WatchChan
+	TxnFunc                func(ctx context.Context, guards []TxnGuard, ops []TxnOp) (int64, error)
 
 	PutCalls                []PutCall
 	PutAllCalls             [][]*models.ConfigPair
@@ -42,6 +48,7 @@ type MockClient struct {
 	DeletePrefixCalls       []string
 	StatusCalls             []string
 	WatchCalls              []WatchCall
+	TxnCalls                []TxnCall
 	CloseCalled             bool
 }
 
@@ -61,6 +68,7 @@ func NewMockClient() *MockClient {
 		DeletePrefixCalls:       make([]string, 0),
 		StatusCalls:             make([]string, 0),
 		WatchCalls:              make([]WatchCall, 0),
+		TxnCalls:                make([]TxnCall, 0),
 	}
 }
 
@@ -180,6 +188,17 @@ func (m *MockClient) Watch(ctx context.Context, key string, opts *WatchOptions)
 	return ch
 }
 
+func (m *MockClient) Txn(ctx context.Context, guards []TxnGuard, ops []TxnOp) (int64, error) {
+	m.TxnCalls = append(m.TxnCalls, TxnCall{
+		Guards: append([]TxnGuard(nil), guards...),
+		Ops:    append([]TxnOp(nil), ops...),
+	})
+	if m.TxnFunc != nil {
+		return m.TxnFunc(ctx, guards, ops)
+	}
+	return 0, nil
+}
+
 func (m *MockClient) Reset() {
 	m.PutCalls = make([]PutCall, 0)
 	m.PutAllCalls = make([][]*models.ConfigPair, 0)
@@ -190,6 +209,7 @@ func (m *MockClient) Reset() {
 	m.DeletePrefixCalls = make([]string, 0)
 	m.StatusCalls = make([]string, 0)
 	m.WatchCalls = make([]WatchCall, 0)
+	m.TxnCalls = make([]TxnCall, 0)
 	m.CloseCalled = false
 }
 
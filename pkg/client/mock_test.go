@@ -189,6 +189,24 @@ func TestMockClient_Close(t *testing.T) {
 	assert.True(t, mock.CloseCalled)
 }
 
+func TestMockClient_Txn(t *testing.T) {
+	mock := NewMockClient()
+	guards := []TxnGuard{{Key: "/src", ModRevision: 4}, {Key: "/dst"}}
+	ops := []TxnOp{{Type: TxnOpPut, Key: "/dst", Value: "v"}, {Type: TxnOpDelete, Key: "/src"}}
+
+	_, err := mock.Txn(context.Background(), guards, ops)
+	require.NoError(t, err)
+	require.Len(t, mock.TxnCalls, 1)
+	assert.Equal(t, guards, mock.TxnCalls[0].Guards)
+	assert.Equal(t, ops, mock.TxnCalls[0].Ops)
+
+	mock.TxnFunc = func(_ context.Context, _ []TxnGuard, _ []TxnOp) (int64, error) {
+		return 0, ErrTxnConflict
+	}
+	_, err = mock.Txn(context.Background(), guards, ops)
+	assert.ErrorIs(t, err, ErrTxnConflict)
+}
+
 func TestMockClient_Reset(t *testing.T) {
 	mock := NewMockClient()
 	mock.Put(context.Background(), "/key", "value")
@@ -197,6 +215,7 @@ func TestMockClient_Reset(t *testing.T) {
 	mock.Get(context.Background(), "/key")
 	mock.GetWithOptions(context.Background(), "/prefix/", &GetOptions{Prefix: true})
 	mock.Status(context.Background(), "http://localhost:2379")
+	mock.Txn(context.Background(), nil, []TxnOp{{Type: TxnOpDelete, Key: "/key"}})
 	mock.Close()
 
 	mock.Reset()
@@ -207,6 +226,7 @@ func TestMockClient_Reset(t *testing.T) {
 	assert.Empty(t, mock.GetCalls)
 	assert.Empty(t, mock.GetWithOptionsCalls)
 	assert.Empty(t, mock.StatusCalls)
+	assert.Empty(t, mock.TxnCalls)
 	assert.False(t, mock.CloseCalled)
 }
 
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// DefaultPageSize is the number of keys fetched per request by ForEachPage.
+const DefaultPageSize int64 = 1000
+
+// PageOptions configures a paginated prefix read.
+type PageOptions struct {
+	// PageSize is the maximum number of keys per request.
+	// Default: DefaultPageSize
+	PageSize int64
+
+	// Revision pins all pages to a revision. If 0, the revision of the
+	// first page is used so the walk sees a consistent snapshot.
+	Revision int64
+
+	// KeysOnly skips fetching values.
+	KeysOnly bool
+}
+
+// ForEachPage reads all keys under prefix in pages and calls fn for each
+// non-empty page, in key order. Reading in pages keeps memory and request
+// sizes bounded for large prefixes. It returns the revision the pages were
+// read at, which callers can use to resume with a watch from revision+1.
+func ForEachPage(ctx context.Context, reader EtcdReader, prefix string, opts *PageOptions, fn func(kvs []*KeyValue) error) (int64, error) {
+	pageSize := DefaultPageSize
+	var revision int64
+	var keysOnly bool
+	if opts != nil {
+		if opts.PageSize > 0 {
+			pageSize = opts.PageSize
+		}
+		revision = opts.Revision
+		keysOnly = opts.KeysOnly
+	}
+
+	rangeEnd := clientv3.GetPrefixRangeEnd(prefix)
+	key := prefix
+
+	for {
+		resp, err := reader.GetWithOptions(ctx, key, &GetOptions{
+			RangeEnd: rangeEnd,
+			Limit:    pageSize,
+			Revision: revision,
+			KeysOnly: keysOnly,
+		})
+		if err != nil {
+			return revision, err
+		}
+		if revision == 0 {
+			revision = resp.Revision
+		}
+
+		if len(resp.Kvs) == 0 {
+			return revision, nil
+		}
+		if err := fn(resp.Kvs); err != nil {
+			return revision, err
+		}
+		if !resp.More {
+			return revision, nil
+		}
+
+		// Continue right after the last key of this page
+		key = resp.Kvs[len(resp.Kvs)-1].Key + "\x00"
+	}
+}
+
+// GetAllWithPrefix reads every key under prefix using ForEachPage and returns
+// them together with the revision they were read at.
+func GetAllWithPrefix(ctx context.Context, reader EtcdReader, prefix string, opts *PageOptions) ([]*KeyValue, int64, error) {
+	var all []*KeyValue
+	revision, err := ForEachPage(ctx, reader, prefix, opts, func(kvs []*KeyValue) error {
+		all = append(all, kvs...)
+		return nil
+	})
+	if err != nil {
+		return nil, revision, err
+	}
+	return all, revision, nil
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeMock serves sorted keys for [key, RangeEnd) honoring Limit and CountOnly.
+func rangeMock(keys []string, revision int64) *MockClient {
+	sort.Strings(keys)
+	return &MockClient{
+		GetWithOptionsFunc: func(_ context.Context, key string, opts *GetOptions) (*GetResponse, error) {
+			resp := &GetResponse{Revision: revision}
+			for _, k := range keys {
+				if k < key || k >= opts.RangeEnd {
+					continue
+				}
+				if opts.CountOnly {
+					resp.Count++
+					continue
+				}
+				if opts.Limit > 0 && int64(len(resp.Kvs)) == opts.Limit {
+					resp.More = true
+					break
+				}
+				resp.Kvs = append(resp.Kvs, &KeyValue{Key: k, Value: "v" + k})
+			}
+			if !opts.CountOnly {
+				resp.Count = int64(len(resp.Kvs))
+			}
+			return resp, nil
+		},
+	}
+}
+
+func TestForEachPage(t *testing.T) {
+	keys := make([]string, 0, 25)
+	for i := range 25 {
+		keys = append(keys, fmt.Sprintf("/app/key%02d", i))
+	}
+	keys = append(keys, "/other/key", "/apple")
+	mock := rangeMock(keys, 42)
+
+	var pages [][]*KeyValue
+	rev, err := ForEachPage(context.Background(), mock, "/app/", &PageOptions{PageSize: 10}, func(kvs []*KeyValue) error {
+		pages = append(pages, kvs)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(42), rev)
+	require.Len(t, pages, 3)
+	assert.Len(t, pages[0], 10)
+	assert.Len(t, pages[2], 5)
+	assert.Equal(t, "/app/key24", pages[2][4].Key)
+
+	// Later pages start after the last key and are pinned to the first page's revision
+	require.Len(t, mock.GetWithOptionsCalls, 3)
+	assert.Equal(t, "/app/", mock.GetWithOptionsCalls[0].Key)
+	assert.Equal(t, int64(0), mock.GetWithOptionsCalls[0].Opts.Revision)
+	assert.Equal(t, "/app/key09\x00", mock.GetWithOptionsCalls[1].Key)
+	assert.Equal(t, int64(42), mock.GetWithOptionsCalls[1].Opts.Revision)
+	assert.Equal(t, "/app0", mock.GetWithOptionsCalls[1].Opts.RangeEnd)
+}
+
+func TestForEachPage_Empty(t *testing.T) {
+	called := false
+	_, err := ForEachPage(context.Background(), rangeMock(nil, 1), "/app/", nil, func([]*KeyValue) error {
+		called = true
+		return nil
+	})
+	require.NoError(t, err)
+	assert.False(t, called)
+}
+
+func TestForEachPage_CallbackError(t *testing.T) {
+	keys := []string{"/a/1", "/a/2", "/a/3"}
+	stop := errors.New("stop")
+
+	_, err := ForEachPage(context.Background(), rangeMock(keys, 1), "/a/", &PageOptions{PageSize: 1}, func([]*KeyValue) error {
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestForEachPage_ReadError(t *testing.T) {
+	mock := &MockClient{
+		GetWithOptionsFunc: func(context.Context, string, *GetOptions) (*GetResponse, error) {
+			return nil, errors.New("connection lost")
+		},
+	}
+
+	_, err := ForEachPage(context.Background(), mock, "/a/", nil, func([]*KeyValue) error { return nil })
+	assert.ErrorContains(t, err, "connection lost")
+}
+
+func TestGetAllWithPrefix(t *testing.T) {
+	keys := []string{"/a/1", "/a/2", "/a/3", "/b/1"}
+
+	kvs, rev, err := GetAllWithPrefix(context.Background(), rangeMock(keys, 7), "/a/", &PageOptions{PageSize: 2, KeysOnly: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(7), rev)
+	require.Len(t, kvs, 3)
+	assert.Equal(t, "/a/3", kvs[2].Key)
+}
@@ -20,6 +20,10 @@ type ContextConfig struct {
 	Key                   string   `yaml:"key,omitempty"`
 	Endpoints             []string `yaml:"endpoints"`
 	InsecureSkipTLSVerify bool     `yaml:"insecure-skip-tls-verify,omitempty"`
+	// KeySeparator and KeyRoot set the key layout used when flattening
+	// files for this context, e.g. "." for keys like app.db.host
+	KeySeparator string `yaml:"key-separator,omitempty"`
+	KeyRoot      string `yaml:"key-root,omitempty"`
 }
 
 // Config represents the entire configuration file
@@ -0,0 +1,124 @@
+// Package glob matches etcd keys against shell-style patterns.
+//
+// Keys are split on '/' and matched segment by segment. Within a segment,
+// '*' matches any run of characters, '?' matches one character and '[...]'
+// is a character class, as in path.Match. A segment of exactly "**" matches
+// zero or more whole segments.
+//
+// A backslash escapes the next character.
+package glob
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Pattern is a compiled key pattern.
+type Pattern struct {
+	raw      string
+	prefix   string
+	segments []string
+}
+
+// IsPattern reports whether s contains an unescaped glob metacharacter.
+// Strings without one are plain keys and should be used as-is.
+func IsPattern(s string) bool {
+	return literalEnd(s) < len(s)
+}
+
+// Compile parses a key pattern.
+func Compile(pattern string) (*Pattern, error) {
+	segments := strings.Split(pattern, "/")
+	for _, seg := range segments {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+	}
+
+	return &Pattern{
+		raw:      pattern,
+		prefix:   unescape(pattern[:literalEnd(pattern)]),
+		segments: segments,
+	}, nil
+}
+
+// String returns the pattern as given to Compile.
+func (p *Pattern) String() string {
+	return p.raw
+}
+
+// Prefix returns the longest literal prefix of the pattern. Every matching
+// key starts with it, so it can be used for the range read.
+func (p *Pattern) Prefix() string {
+	return p.prefix
+}
+
+// Match reports whether key matches the pattern.
+func (p *Pattern) Match(key string) bool {
+	if !strings.HasPrefix(key, p.prefix) {
+		return false
+	}
+	return matchSegments(p.segments, strings.Split(key, "/"))
+}
+
+func matchSegments(pattern, key []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated ** and try every split point
+			rest := pattern[1:]
+			for len(rest) > 0 && rest[0] == "**" {
+				rest = rest[1:]
+			}
+			if len(rest) == 0 {
+				return true
+			}
+			for i := range len(key) + 1 {
+				if matchSegments(rest, key[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(key) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], key[0]); !ok {
+			return false
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
+
+// literalEnd returns the index of the first unescaped metacharacter in s,
+// or len(s) if there is none.
+func literalEnd(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '*', '?', '[':
+			return i
+		}
+	}
+	return len(s)
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
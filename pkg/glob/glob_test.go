@@ -0,0 +1,79 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsPattern(t *testing.T) {
+	assert.True(t, IsPattern("/app/*/db/host"))
+	assert.True(t, IsPattern("/svc/**/timeout"))
+	assert.True(t, IsPattern("/app/db?"))
+	assert.True(t, IsPattern("/app/[ab]"))
+	assert.False(t, IsPattern("/app/db/host"))
+	assert.False(t, IsPattern(`/app/\*`))
+}
+
+func TestCompile_Prefix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		prefix  string
+	}{
+		{"/app/*/db/host", "/app/"},
+		{"/svc/**/timeout", "/svc/"},
+		{"/app/db-*", "/app/db-"},
+		{"/*", "/"},
+		{`/a\*b/*`, "/a*b/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			p, err := Compile(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.prefix, p.Prefix())
+			assert.Equal(t, tt.pattern, p.String())
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	_, err := Compile("/app/[a-")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid glob pattern")
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"/app/*/db/host", "/app/api/db/host", true},
+		{"/app/*/db/host", "/app/api/v2/db/host", false},
+		{"/app/*/db/host", "/app/api/db/port", false},
+		{"/svc/**/timeout", "/svc/timeout", true},
+		{"/svc/**/timeout", "/svc/a/timeout", true},
+		{"/svc/**/timeout", "/svc/a/b/c/timeout", true},
+		{"/svc/**/timeout", "/svc/a/b/c/timeout/x", false},
+		{"/svc/**", "/svc/a/b", true},
+		{"/svc/**/**/x", "/svc/x", true},
+		{"/app/db?", "/app/db1", true},
+		{"/app/db?", "/app/db12", false},
+		{"/app/[ab]/x", "/app/b/x", true},
+		{"/app/[ab]/x", "/app/c/x", false},
+		{"/app/*", "/app/", true},
+		{"/app/*", "/other/x", false},
+		{`/a\*b/*`, "/a*b/c", true},
+		{`/a\*b/*`, "/axb/c", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.key, func(t *testing.T) {
+			p, err := Compile(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, p.Match(tt.key))
+		})
+	}
+}
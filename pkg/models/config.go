@@ -1,11 +1,76 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+)
 
 // ConfigPair represents a single etcd key-value configuration pair
 type ConfigPair struct {
 	Value string
 	Key   string
+	// Pos is where the pair was defined in its source file, if known
+	Pos Position
+	// Context is the etcd context the pair targets. Empty means the context
+	// selected for the command.
+	Context string
+	// Type is the type the value had in a typed source file, if tracked
+	Type ValueType
+}
+
+// ValueType is the type of a scalar in a YAML, JSON or TOML file. Values
+// are always stored as strings; the type says how to write them back.
+type ValueType string
+
+const (
+	TypeString ValueType = "string"
+	TypeInt    ValueType = "int"
+	TypeFloat  ValueType = "float"
+	TypeBool   ValueType = "bool"
+	TypeNull   ValueType = "null"
+	// TypeArray is an array stored as its JSON encoding
+	TypeArray ValueType = "array"
+	// TypeList is an array of scalars stored as one CSV record
+	TypeList ValueType = "list"
+)
+
+// IsValid checks if the value type is known
+func (t ValueType) IsValid() bool {
+	switch t {
+	case TypeString, TypeInt, TypeFloat, TypeBool, TypeNull, TypeArray, TypeList:
+		return true
+	default:
+		return false
+	}
+}
+
+// Position is a location in a source file. Line and Column are 1-based;
+// zero means unknown.
+type Position struct {
+	File   string `json:",omitempty" yaml:"file,omitempty"`
+	Line   int    `json:",omitempty" yaml:"line,omitempty"`
+	Column int    `json:",omitempty" yaml:"column,omitempty"`
+}
+
+// IsValid reports whether the position has a line number
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as file:line:col, omitting unknown parts.
+// Without a line number it is just the file name, which may be empty.
+func (p Position) String() string {
+	if !p.IsValid() {
+		return p.File
+	}
+	s := strconv.Itoa(p.Line)
+	if p.Column > 0 {
+		s += ":" + strconv.Itoa(p.Column)
+	}
+	if p.File != "" {
+		s = p.File + ":" + s
+	}
+	return s
 }
 
 // String returns a string representation of the config pair
@@ -17,16 +82,19 @@ func (c *ConfigPair) String() string {
 type FormatType string
 
 const (
-	FormatAuto    FormatType = "auto"
-	FormatEtcdctl FormatType = "etcdctl"
-	FormatYAML    FormatType = "yaml"
-	FormatJSON    FormatType = "json"
+	FormatAuto       FormatType = "auto"
+	FormatEtcdctl    FormatType = "etcdctl"
+	FormatYAML       FormatType = "yaml"
+	FormatJSON       FormatType = "json"
+	FormatTOML       FormatType = "toml"
+	FormatDotenv     FormatType = "dotenv"
+	FormatProperties FormatType = "properties"
 )
 
 // IsValid checks if the format type is valid
 func (f FormatType) IsValid() bool {
 	switch f {
-	case FormatAuto, FormatEtcdctl, FormatYAML, FormatJSON:
+	case FormatAuto, FormatEtcdctl, FormatYAML, FormatJSON, FormatTOML, FormatDotenv, FormatProperties:
 		return true
 	default:
 		return false
@@ -37,6 +105,8 @@ func (f FormatType) IsValid() bool {
 type ApplyOptions struct {
 	FilePath   string
 	Format     FormatType
+	Prefix     string
+	KeyMap     string
 	DryRun     bool
 	NoValidate bool
 	Strict     bool
@@ -46,6 +116,8 @@ type ApplyOptions struct {
 type ValidateOptions struct {
 	FilePath string
 	Format   FormatType
+	Prefix   string
+	KeyMap   string
 	Strict   bool
 }
 
@@ -53,5 +125,7 @@ type ValidateOptions struct {
 type ParseOptions struct {
 	FilePath   string
 	Format     FormatType
+	Prefix     string
+	KeyMap     string
 	JSONOutput bool
 }
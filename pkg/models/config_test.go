@@ -49,7 +49,10 @@ func TestFormatType_IsValid(t *testing.T) {
 		{"json format", FormatJSON, true},
 		{"invalid format", FormatType("invalid"), false},
 		{"empty format", FormatType(""), false},
-		{"random string", FormatType("toml"), false},
+		{"random string", FormatType("xml"), false},
+		{"toml format", FormatTOML, true},
+		{"dotenv format", FormatDotenv, true},
+		{"properties format", FormatProperties, true},
 	}
 
 	for _, tt := range tests {
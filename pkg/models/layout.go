@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// DefaultKeySeparator joins the segments of a key in the default layout
+const DefaultKeySeparator = "/"
+
+// KeyLayout describes how the segments of a nested key are joined into a
+// flat etcd key: Root followed by the segments joined with Separator. The
+// zero value is the default layout, /app/db/host. Other layouts give keys
+// such as app.db.host (Separator ".") or config:app:db (Separator ":" and
+// Root "config:").
+type KeyLayout struct {
+	// Separator joins key segments. Empty means "/".
+	Separator string
+
+	// Root starts every key. Empty means "/" with the default separator and
+	// no root with any other.
+	Root string
+}
+
+// Resolved returns the layout with its defaults filled in
+func (l KeyLayout) Resolved() KeyLayout {
+	if l.Separator == "" {
+		l.Separator = DefaultKeySeparator
+	}
+	if l.Root == "" && l.Separator == DefaultKeySeparator {
+		l.Root = DefaultKeySeparator
+	}
+	return l
+}
+
+// IsDefault reports whether the layout is the default /app/db/host layout
+func (l KeyLayout) IsDefault() bool {
+	return l.Resolved() == KeyLayout{}.Resolved()
+}
+
+// Validate checks that keys built with the layout can be split again
+func (l KeyLayout) Validate() error {
+	if strings.ContainsFunc(l.Separator, unicode.IsSpace) {
+		return errors.New("key separator must not contain whitespace")
+	}
+	if strings.ContainsFunc(l.Root, unicode.IsSpace) {
+		return errors.New("key root must not contain whitespace")
+	}
+	return nil
+}
+
+// Join appends segment to the key parent. An empty parent, or one that is
+// just the root, gives the top-level key for segment.
+func (l KeyLayout) Join(parent, segment string) string {
+	r := l.Resolved()
+	if parent == "" || parent == r.Root {
+		return r.Root + segment
+	}
+	return parent + r.Separator + segment
+}
+
+// Split returns the segments of key after its root. The root key itself
+// has no segments. A key without the root is split as a whole.
+func (l KeyLayout) Split(key string) []string {
+	r := l.Resolved()
+	rest := strings.TrimPrefix(key, r.Root)
+	if rest == "" {
+		return nil
+	}
+	return strings.Split(rest, r.Separator)
+}
+
+// HasRoot reports whether key starts with the root of the layout
+func (l KeyLayout) HasRoot(key string) bool {
+	return strings.HasPrefix(key, l.Resolved().Root)
+}
+
+// Path converts key to the default layout, so app.db.host becomes
+// /app/db/host.
+func (l KeyLayout) Path(key string) string {
+	if l.IsDefault() {
+		return key
+	}
+	return DefaultKeySeparator + strings.Join(l.Split(key), DefaultKeySeparator)
+}
+
+// FromPath converts a key in the default layout to this layout, so
+// /app/db/host becomes app.db.host. It is the reverse of Path.
+func (l KeyLayout) FromPath(path string) string {
+	if l.IsDefault() {
+		return path
+	}
+	r := l.Resolved()
+	rest := strings.TrimPrefix(path, DefaultKeySeparator)
+	if rest == "" {
+		return r.Root
+	}
+	return r.Root + strings.Join(strings.Split(rest, DefaultKeySeparator), r.Separator)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyLayout_Resolved(t *testing.T) {
+	assert.Equal(t, KeyLayout{Separator: "/", Root: "/"}, KeyLayout{}.Resolved())
+	assert.Equal(t, KeyLayout{Separator: ".", Root: ""}, KeyLayout{Separator: "."}.Resolved())
+	assert.Equal(t, KeyLayout{Separator: ":", Root: "config:"}, KeyLayout{Separator: ":", Root: "config:"}.Resolved())
+
+	assert.True(t, KeyLayout{}.IsDefault())
+	assert.True(t, KeyLayout{Separator: "/"}.IsDefault())
+	assert.False(t, KeyLayout{Separator: "."}.IsDefault())
+	assert.False(t, KeyLayout{Root: "/etc/"}.IsDefault())
+}
+
+func TestKeyLayout_Validate(t *testing.T) {
+	assert.NoError(t, KeyLayout{}.Validate())
+	assert.NoError(t, KeyLayout{Separator: "::", Root: "cfg::"}.Validate())
+	assert.EqualError(t, KeyLayout{Separator: " "}.Validate(), "key separator must not contain whitespace")
+	assert.EqualError(t, KeyLayout{Separator: ".", Root: "a\n"}.Validate(), "key root must not contain whitespace")
+}
+
+func TestKeyLayout_JoinSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		layout   KeyLayout
+		segments []string
+		key      string
+	}{
+		{"default", KeyLayout{}, []string{"app", "db", "host"}, "/app/db/host"},
+		{"dots", KeyLayout{Separator: "."}, []string{"app", "db", "host"}, "app.db.host"},
+		{"colons with root", KeyLayout{Separator: ":", Root: "config:"}, []string{"app", "db"}, "config:app:db"},
+		{"multi-character separator", KeyLayout{Separator: "__"}, []string{"app", "db"}, "app__db"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := ""
+			for _, segment := range tt.segments {
+				key = tt.layout.Join(key, segment)
+			}
+			assert.Equal(t, tt.key, key)
+			assert.Equal(t, tt.segments, tt.layout.Split(tt.key))
+			assert.True(t, tt.layout.HasRoot(tt.key))
+
+			path := "/" + strings.Join(tt.segments, "/")
+			assert.Equal(t, path, tt.layout.Path(tt.key))
+			assert.Equal(t, tt.key, tt.layout.FromPath(path))
+		})
+	}
+}
+
+func TestKeyLayout_RootKey(t *testing.T) {
+	assert.Equal(t, "/a", KeyLayout{}.Join("/", "a"))
+	assert.Nil(t, KeyLayout{}.Split("/"))
+	assert.Equal(t, "config:", KeyLayout{Separator: ":", Root: "config:"}.FromPath("/"))
+	assert.False(t, KeyLayout{Separator: ":", Root: "config:"}.HasRoot("app:db"))
+	assert.Equal(t, []string{"app", "db"}, KeyLayout{Separator: ":", Root: "config:"}.Split("app:db"))
+}
@@ -26,6 +26,28 @@ type DiffEntry struct {
 	Status   DiffStatus `json:"status"`
 	OldValue string     `json:"old_value,omitempty"`
 	NewValue string     `json:"new_value,omitempty"`
+
+	// oldDisplay and newDisplay hold canonical renderings used by semantic
+	// diffs so that key order and whitespace changes are not shown.
+	oldDisplay string
+	newDisplay string
+}
+
+// displayValues returns the old and new values to render for this entry.
+func (e *DiffEntry) displayValues() (oldVal, newVal string) {
+	if e.oldDisplay != "" || e.newDisplay != "" {
+		return e.oldDisplay, e.newDisplay
+	}
+	return e.OldValue, e.NewValue
+}
+
+// DiffOptions controls how DiffKeyValuesWithOptions compares values.
+type DiffOptions struct {
+	// IgnoreKeys holds glob patterns for keys left out of the diff.
+	IgnoreKeys []string
+
+	// Semantic compares JSON/YAML values structurally, ignoring key order and whitespace.
+	Semantic bool
 }
 
 // DiffResult contains the diff result with summary counts
@@ -130,8 +152,17 @@ func printAddedEntry(prefix string, e *DiffEntry) {
 
 func printModifiedEntry(prefix string, e *DiffEntry) {
 	fmt.Printf("  %s %s\n", StyleIfTerminal(modifiedStyle, prefix), StyleIfTerminal(keyStyle, e.Key))
-	fmt.Printf("    %sold: %s\n", StyleIfTerminal(oldValueStyle, "  "), StyleIfTerminal(oldValueStyle, e.OldValue))
-	fmt.Printf("    %snew: %s\n", StyleIfTerminal(newValueStyle, "  "), StyleIfTerminal(newValueStyle, e.NewValue))
+
+	oldVal, newVal := e.displayValues()
+	if isMultiline(oldVal) || isMultiline(newVal) {
+		if hunks, ok := unifiedDiff(oldVal, newVal, diffContextLines); ok {
+			printLineDiff(hunks)
+			return
+		}
+	}
+
+	fmt.Printf("    %sold: %s\n", StyleIfTerminal(oldValueStyle, "  "), StyleIfTerminal(oldValueStyle, oldVal))
+	fmt.Printf("    %snew: %s\n", StyleIfTerminal(newValueStyle, "  "), StyleIfTerminal(newValueStyle, newVal))
 }
 
 func printDeletedEntry(prefix string, e *DiffEntry) {
@@ -335,6 +366,21 @@ func printDiffTable(result *DiffResult, showUnchanged bool) error {
 
 // DiffKeyValues computes the diff between two key-value maps
 func DiffKeyValues(fileMap, etcdMap map[string]string) *DiffResult {
+	// Without ignore patterns there is nothing that can fail
+	result, _ := DiffKeyValuesWithOptions(fileMap, etcdMap, nil)
+	return result
+}
+
+// DiffKeyValuesWithOptions computes the diff between two key-value maps,
+// skipping ignored keys and optionally comparing structured values semantically.
+func DiffKeyValuesWithOptions(fileMap, etcdMap map[string]string, opts *DiffOptions) (*DiffResult, error) {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+	if err := ValidateIgnorePatterns(opts.IgnoreKeys); err != nil {
+		return nil, err
+	}
+
 	result := &DiffResult{
 		Entries: make([]*DiffEntry, 0),
 	}
@@ -351,6 +397,16 @@ func DiffKeyValues(fileMap, etcdMap map[string]string) *DiffResult {
 	}
 	sort.Strings(allKeys)
 
+	if len(opts.IgnoreKeys) > 0 {
+		kept := allKeys[:0]
+		for _, k := range allKeys {
+			if !matchesIgnorePattern(k, opts.IgnoreKeys) {
+				kept = append(kept, k)
+			}
+		}
+		allKeys = kept
+	}
+
 	// Compute diff for each key
 	for _, key := range allKeys {
 		fileVal, fileExists := fileMap[key]
@@ -369,10 +425,20 @@ func DiffKeyValues(fileMap, etcdMap map[string]string) *DiffResult {
 			entry.Status = DiffStatusAdded
 			entry.NewValue = fileVal
 		case fileVal != etcdVal:
-			// Key in both, values differ -> modified
-			entry.Status = DiffStatusModified
 			entry.OldValue = etcdVal
 			entry.NewValue = fileVal
+			entry.Status = DiffStatusModified
+			if opts.Semantic {
+				// Structured values that only differ in formatting are unchanged
+				structured, equal, oldCanon, newCanon := compareSemantic(etcdVal, fileVal)
+				switch {
+				case structured && equal:
+					entry.Status = DiffStatusUnchanged
+				case structured:
+					entry.oldDisplay = oldCanon
+					entry.newDisplay = newCanon
+				}
+			}
 		default:
 			// Key in both, values same -> unchanged
 			entry.Status = DiffStatusUnchanged
@@ -394,5 +460,5 @@ func DiffKeyValues(fileMap, etcdMap map[string]string) *DiffResult {
 		}
 	}
 
-	return result
+	return result, nil
 }
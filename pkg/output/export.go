@@ -0,0 +1,236 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/parsers"
+)
+
+// ExportPairs writes pairs to w in a file format that parses back to exactly
+// the same pairs. Empty values are kept and keys that are both a value and a
+// directory use parsers.DirectoryValueKey. Pairs that the format cannot
+// represent are reported as an error rather than silently altered.
+func ExportPairs(w io.Writer, pairs []*models.ConfigPair, format models.FormatType) error {
+	return ExportPairsWithOptions(w, pairs, format, nil)
+}
+
+// ExportOptions controls how ExportPairsWithOptions writes nested formats
+type ExportOptions struct {
+	// Arrays is the array mode the file will be parsed with. Values that mode
+	// produces from arrays are written back as arrays.
+	Arrays parsers.ArrayMode
+
+	// Types writes pairs with a Type as that type rather than guessing it
+	// from the value, so "8080" and 8080 stay distinct.
+	Types bool
+
+	// Layout is the key layout the file will be parsed with. Keys are
+	// nested by its separator.
+	Layout models.KeyLayout
+}
+
+// ExportPairsWithOptions writes pairs like ExportPairs, restoring arrays for
+// YAML and JSON as opts describes.
+func ExportPairsWithOptions(w io.Writer, pairs []*models.ConfigPair, format models.FormatType, opts *ExportOptions) error {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	switch format {
+	case models.FormatYAML:
+		return exportYAML(w, pairs, opts)
+	case models.FormatJSON:
+		return exportJSON(w, pairs, opts)
+	case models.FormatEtcdctl:
+		return exportEtcdctl(w, pairs)
+	default:
+		return fmt.Errorf("unsupported export format: %s (use yaml, json, or etcdctl)", format)
+	}
+}
+
+// exportNested builds the nested map used for YAML and JSON exports.
+func exportNested(pairs []*models.ConfigPair, opts *ExportOptions) (map[string]any, error) {
+	for _, p := range pairs {
+		if !isNestableKey(p.Key, opts.Layout) {
+			return nil, fmt.Errorf("key %q has empty path segments and cannot be nested; use --format etcdctl", p.Key)
+		}
+	}
+	return parsers.UnflattenMapWithOptions(pairs, &parsers.UnflattenOptions{
+		KeepEmpty:       true,
+		DirectoryValues: true,
+		Arrays:          opts.Arrays,
+		Types:           opts.Types,
+		Layout:          opts.Layout,
+	})
+}
+
+// isNestableKey reports whether a key survives splitting by layout
+// unchanged.
+func isNestableKey(key string, layout models.KeyLayout) bool {
+	root := layout.Resolved().Root
+	if key == root {
+		return root != ""
+	}
+	if !strings.HasPrefix(key, root) {
+		return false
+	}
+	for _, part := range layout.Split(key) {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func exportYAML(w io.Writer, pairs []*models.ConfigPair, opts *ExportOptions) error {
+	nested, err := exportNested(pairs, opts)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(exportNode(nested))
+	if err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// exportNode converts a nested map of strings into a YAML node, choosing
+// scalar tags that decode back to the original string.
+func exportNode(v any) *yaml.Node {
+	if typed, ok := v.(parsers.TypedValue); ok {
+		node := &yaml.Node{}
+		if err := node.Encode(typed); err == nil {
+			return node
+		}
+		return losslessStringNode(typed.Value)
+	}
+
+	if items, ok := v.([]any); ok {
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range items {
+			node.Content = append(node.Content, exportNode(item))
+		}
+		return node
+	}
+
+	m, ok := v.(map[string]any)
+	if !ok {
+		s, _ := v.(string)
+		return losslessStringNode(s)
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range keys {
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k}
+		node.Content = append(node.Content, keyNode, exportNode(m[k]))
+	}
+	return node
+}
+
+// losslessStringNode is stringToNode restricted to numbers that format back
+// to the same text once parsed (e.g. "1.50" and "1e3" stay quoted strings).
+func losslessStringNode(val string) *yaml.Node {
+	node := stringToNode(val)
+	switch node.Tag {
+	case "!!int":
+		if n, err := strconv.ParseInt(val, 10, 64); err != nil || strconv.FormatInt(n, 10) != val {
+			return scalarNode("!!str", val)
+		}
+	case "!!float":
+		if f, err := strconv.ParseFloat(val, 64); err != nil || models.FormatValue(f) != val {
+			return scalarNode("!!str", val)
+		}
+	}
+	return node
+}
+
+func exportJSON(w io.Writer, pairs []*models.ConfigPair, opts *ExportOptions) error {
+	nested, err := exportNested(pairs, opts)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(nested)
+}
+
+// exportEtcdctl writes pairs in the etcdctl format read by parsers.EtcdctlParser:
+// a key line, the value, and a blank separator line.
+func exportEtcdctl(w io.Writer, pairs []*models.ConfigPair) error {
+	sorted := make([]*models.ConfigPair, len(pairs))
+	copy(sorted, pairs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+
+	var b strings.Builder
+	for _, p := range sorted {
+		if !strings.HasPrefix(p.Key, "/") || strings.TrimSpace(p.Key) != p.Key || strings.Contains(p.Key, "\n") {
+			return fmt.Errorf("key %q cannot be represented in etcdctl format; use --format yaml or json", p.Key)
+		}
+		value, ok := etcdctlValue(p.Value)
+		if !ok {
+			return fmt.Errorf("value of %q cannot be represented in etcdctl format; use --format yaml or json", p.Key)
+		}
+		b.WriteString(p.Key)
+		b.WriteString("\n")
+		b.WriteString(value)
+		b.WriteString("\n\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// etcdctlValue renders a value so that the etcdctl parser reads it back
+// unchanged. Single-line values are quoted when the parser would otherwise
+// trim them, strip their quotes, or mistake them for a key.
+func etcdctlValue(val string) (string, bool) {
+	if !strings.Contains(val, "\n") {
+		if needsEtcdctlQuotes(val) {
+			return `"` + val + `"`, true
+		}
+		return val, true
+	}
+
+	// Multi-line values are kept verbatim, so every line must survive as-is
+	lines := strings.Split(val, "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "/") || (trimmed == "" && line != "") {
+			return "", false
+		}
+	}
+	if strings.TrimSpace(lines[len(lines)-1]) == "" {
+		return "", false
+	}
+	return val, true
+}
+
+func needsEtcdctlQuotes(val string) bool {
+	if val == "" || strings.TrimSpace(val) != val || strings.HasPrefix(val, "/") {
+		return true
+	}
+	if len(val) >= 2 {
+		first, last := val[0], val[len(val)-1]
+		if (first == '"' && last == '"') || (first == '\'' && last == '\'') {
+			return true
+		}
+	}
+	return false
+}
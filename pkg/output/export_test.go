@@ -0,0 +1,259 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+	"github.com/kazuma-desu/etu/pkg/parsers"
+)
+
+// roundTrip exports pairs, parses the result with the matching parser and
+// returns the parsed pairs. Source positions are cleared so the result
+// compares by key and value.
+func roundTrip(t *testing.T, pairs []*models.ConfigPair, format models.FormatType) []*models.ConfigPair {
+	t.Helper()
+	return roundTripArrays(t, pairs, format, parsers.ArrayJSON)
+}
+
+// roundTripArrays is roundTrip with arrays exported and parsed in mode
+func roundTripArrays(t *testing.T, pairs []*models.ConfigPair, format models.FormatType, mode parsers.ArrayMode) []*models.ConfigPair {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportPairsWithOptions(&buf, pairs, format, &ExportOptions{Arrays: mode}))
+
+	path := filepath.Join(t.TempDir(), "export")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	registry := parsers.NewRegistryWithFlatten(&parsers.FlattenOptions{Arrays: mode})
+	parser, err := registry.GetParser(format)
+	require.NoError(t, err)
+	parsed, err := parser.Parse(context.Background(), path)
+	require.NoError(t, err, "exported content:\n%s", buf.String())
+	for _, pair := range parsed {
+		// The csv mode records types, which etcd values do not have here
+		pair.Pos, pair.Type = models.Position{}, ""
+	}
+	return parsed
+}
+
+func TestExportPairs_RoundTrip(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/app/name", Value: "myapp"},
+		{Key: "/app/empty", Value: ""},
+		{Key: "/app/db", Value: "primary"},
+		{Key: "/app/db/host", Value: "localhost"},
+		{Key: "/app/db/port", Value: "5432"},
+		{Key: "/app/ratio", Value: "1.50"},
+		{Key: "/app/big", Value: "1e3"},
+		{Key: "/app/flag", Value: "true"},
+		{Key: "/app/yes", Value: "yes"},
+		{Key: "/app/quoted", Value: `"already quoted"`},
+		{Key: "/app/path", Value: "/usr/local/bin"},
+		{Key: "/app/padded", Value: "  spaced  "},
+		{Key: "/app/multiline", Value: "line one\n  indented\n\nline four"},
+		{Key: "/app/json", Value: `{"a":[1,2]}`},
+	}
+
+	for _, format := range []models.FormatType{models.FormatYAML, models.FormatJSON, models.FormatEtcdctl} {
+		t.Run(string(format), func(t *testing.T) {
+			assert.ElementsMatch(t, pairs, roundTrip(t, pairs, format))
+		})
+	}
+}
+
+func TestExportPairs_ArrayRoundTrip(t *testing.T) {
+	tests := []struct {
+		mode  parsers.ArrayMode
+		pairs []*models.ConfigPair
+	}{
+		{parsers.ArrayIndexed, []*models.ConfigPair{
+			{Key: "/app/hosts/0", Value: "a.example.com"},
+			{Key: "/app/hosts/1", Value: "b.example.com"},
+			{Key: "/app/ports/0", Value: "80"},
+			{Key: "/app/ports/1", Value: "08"},
+			{Key: "/app/servers/0/name", Value: "one"},
+			{Key: "/app/servers/0/weight", Value: "1.50"},
+		}},
+		{parsers.ArrayCSV, []*models.ConfigPair{
+			{Key: "/app/hosts", Value: "a.example.com,b.example.com"},
+			{Key: "/app/ports", Value: "80,08,true"},
+			{Key: "/app/motd", Value: "hello, world"},
+			{Key: "/app/quoted", Value: `x,"y,z"`},
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, format := range []models.FormatType{models.FormatYAML, models.FormatJSON} {
+			t.Run(string(tt.mode)+"/"+string(format), func(t *testing.T) {
+				assert.ElementsMatch(t, tt.pairs, roundTripArrays(t, tt.pairs, format, tt.mode))
+			})
+		}
+	}
+}
+
+func TestExportPairs_TypedRoundTrip(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/app/port", Value: "8080", Type: models.TypeInt},
+		{Key: "/app/quoted", Value: "8080", Type: models.TypeString},
+		{Key: "/app/ratio", Value: "1.5", Type: models.TypeFloat},
+		{Key: "/app/debug", Value: "true", Type: models.TypeBool},
+		{Key: "/app/enabled", Value: "false", Type: models.TypeString},
+		{Key: "/app/unset", Value: "", Type: models.TypeNull},
+		{Key: "/app/tags", Value: `[1,"a",2.5]`, Type: models.TypeArray},
+	}
+
+	for _, format := range []models.FormatType{models.FormatYAML, models.FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, ExportPairsWithOptions(&buf, pairs, format, &ExportOptions{Types: true}))
+
+			path := filepath.Join(t.TempDir(), "export")
+			require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+			registry := parsers.NewRegistryWithFlatten(&parsers.FlattenOptions{PreserveTypes: true, KeepNulls: true})
+			parser, err := registry.GetParser(format)
+			require.NoError(t, err)
+			parsed, err := parser.Parse(context.Background(), path)
+			require.NoError(t, err, "exported content:\n%s", buf.String())
+			for _, pair := range parsed {
+				pair.Pos = models.Position{}
+			}
+			assert.ElementsMatch(t, pairs, parsed)
+		})
+	}
+}
+
+func TestExportPairs_LayoutRoundTrip(t *testing.T) {
+	layout := models.KeyLayout{Separator: "."}
+	pairs := []*models.ConfigPair{
+		{Key: "app", Value: "primary"},
+		{Key: "app.db.host", Value: "localhost"},
+		{Key: "app.url", Value: "http://example.com/a/b"},
+	}
+
+	for _, format := range []models.FormatType{models.FormatYAML, models.FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, ExportPairsWithOptions(&buf, pairs, format, &ExportOptions{Layout: layout}))
+
+			registry := parsers.NewRegistryWithFlatten(&parsers.FlattenOptions{Layout: layout})
+			parser, err := registry.GetParser(format)
+			require.NoError(t, err)
+			parsed, err := parser.ParseReader(context.Background(), &buf)
+			require.NoError(t, err)
+			for _, pair := range parsed {
+				pair.Pos = models.Position{}
+			}
+			assert.ElementsMatch(t, pairs, parsed)
+		})
+	}
+
+	err := ExportPairsWithOptions(&bytes.Buffer{}, []*models.ConfigPair{{Key: "app..x", Value: "1"}},
+		models.FormatYAML, &ExportOptions{Layout: layout})
+	assert.ErrorContains(t, err, "empty path segments")
+}
+
+func TestExportPairs_IndexedWritesLists(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/hosts/0", Value: "a"},
+		{Key: "/hosts/1", Value: "b"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, ExportPairsWithOptions(&buf, pairs, models.FormatYAML, &ExportOptions{Arrays: parsers.ArrayIndexed}))
+	assert.Equal(t, "hosts:\n    - a\n    - b\n", buf.String())
+}
+
+func TestExportPairs_RootKey(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/", Value: "root"},
+		{Key: "/db", Value: "x"},
+	}
+
+	for _, format := range []models.FormatType{models.FormatYAML, models.FormatJSON, models.FormatEtcdctl} {
+		t.Run(string(format), func(t *testing.T) {
+			assert.ElementsMatch(t, pairs, roundTrip(t, pairs, format))
+		})
+	}
+}
+
+func TestExportPairs_YAMLLayout(t *testing.T) {
+	var buf bytes.Buffer
+	err := ExportPairs(&buf, []*models.ConfigPair{
+		{Key: "/app/db", Value: "primary"},
+		{Key: "/app/db/host", Value: "localhost"},
+		{Key: "/app/port", Value: "8080"},
+		{Key: "/app/empty", Value: ""},
+	}, models.FormatYAML)
+	require.NoError(t, err)
+
+	assert.Equal(t, `app:
+    db:
+        "": primary
+        host: localhost
+    empty: ""
+    port: 8080
+`, buf.String())
+}
+
+func TestExportPairs_NestedRejectsEmptySegments(t *testing.T) {
+	for _, key := range []string{"/app//db", "/app/dir/"} {
+		var buf bytes.Buffer
+		err := ExportPairs(&buf, []*models.ConfigPair{{Key: key, Value: "x"}}, models.FormatYAML)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--format etcdctl")
+
+		// etcdctl keeps such keys verbatim
+		require.NoError(t, ExportPairs(&buf, []*models.ConfigPair{{Key: key, Value: "x"}}, models.FormatEtcdctl))
+	}
+}
+
+func TestExportPairs_EtcdctlRejectsAmbiguousValues(t *testing.T) {
+	tests := []string{
+		"first\n/looks/like/a/key",
+		"trailing newline\n",
+		"whitespace only line\n   \nend",
+	}
+
+	for _, value := range tests {
+		var buf bytes.Buffer
+		err := ExportPairs(&buf, []*models.ConfigPair{{Key: "/k", Value: value}}, models.FormatEtcdctl)
+		require.Error(t, err, "value %q", value)
+		assert.Contains(t, err.Error(), "--format yaml or json")
+
+		// YAML represents these values losslessly
+		assert.Equal(t, []*models.ConfigPair{{Key: "/k", Value: value}},
+			roundTrip(t, []*models.ConfigPair{{Key: "/k", Value: value}}, models.FormatYAML))
+	}
+}
+
+func TestExportPairs_UnsupportedFormat(t *testing.T) {
+	err := ExportPairs(&bytes.Buffer{}, nil, models.FormatAuto)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported export format")
+}
+
+func TestLosslessStringNode(t *testing.T) {
+	tests := map[string]string{
+		"8080":                 "!!int",
+		"-0":                   "!!str",
+		"1.5":                  "!!float",
+		"1.50":                 "!!str",
+		"1e3":                  "!!str",
+		"true":                 "!!bool",
+		"hello":                "!!str",
+		"99999999999999999999": "!!str",
+	}
+
+	for value, tag := range tests {
+		assert.Equal(t, tag, losslessStringNode(value).Tag, "value %q", value)
+	}
+}
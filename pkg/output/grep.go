@@ -0,0 +1,192 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// grepSnippetWidth is the maximum number of bytes of a line shown in
+	// simple output. Longer lines are cut around the first match.
+	grepSnippetWidth = 120
+
+	// grepSnippetLead is how much text before the first match is kept when a
+	// line is cut.
+	grepSnippetLead = 40
+)
+
+// grepItem is the structured form of a matching line for JSON/YAML output.
+type grepItem struct {
+	Field  string   `json:"field"`
+	Text   string   `json:"text"`
+	Ranges [][2]int `json:"ranges"`
+	Line   int      `json:"line"`
+}
+
+// PrintGrepMatches prints search results in the specified format.
+func PrintGrepMatches(matches []GrepMatch, format string) error {
+	switch format {
+	case FormatSimple.String():
+		printGrepSimple(matches)
+		return nil
+	case FormatJSON.String():
+		return printGrepJSON(matches)
+	case FormatYAML.String():
+		return printGrepYAML(matches)
+	default:
+		return fmt.Errorf("unsupported format: %s (use simple, json, or yaml)", format)
+	}
+}
+
+func printGrepSimple(matches []GrepMatch) {
+	if len(matches) == 0 {
+		Info("No matches found")
+		return
+	}
+
+	lines := 0
+	for _, m := range matches {
+		keyLine := StyleIfTerminal(keyStyle, m.Key)
+		for _, l := range m.Lines {
+			if l.Field == "key" {
+				keyLine = highlightMatches(l.Text, l.Ranges, keyStyle)
+			}
+		}
+		fmt.Println(keyLine)
+
+		for _, l := range m.Lines {
+			lines++
+			if l.Field != "value" {
+				continue
+			}
+			number := StyleIfTerminal(lineNumberStyle, fmt.Sprintf("%4d│", l.Line))
+			fmt.Printf("  %s %s\n", number, grepSnippet(l.Text, l.Ranges))
+		}
+	}
+
+	fmt.Println()
+	Info(fmt.Sprintf("%d matches in %d keys", lines, len(matches)))
+}
+
+// grepSnippet returns the line with its matches highlighted. Lines longer
+// than grepSnippetWidth are cut to a window around the first match.
+func grepSnippet(text string, ranges [][2]int) string {
+	start, end := 0, len(text)
+	if len(text) > grepSnippetWidth && len(ranges) > 0 {
+		start = max(ranges[0][0]-grepSnippetLead, 0)
+		end = min(start+grepSnippetWidth, len(text))
+		end = max(end, ranges[0][1])
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start--
+		}
+		for end < len(text) && !utf8.RuneStart(text[end]) {
+			end++
+		}
+	}
+
+	var clipped [][2]int
+	for _, r := range ranges {
+		s, e := max(r[0], start), min(r[1], end)
+		if s < e {
+			clipped = append(clipped, [2]int{s - start, e - start})
+		}
+	}
+
+	snippet := highlightMatches(text[start:end], clipped, valueStyle)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// highlightMatches renders text with base style and the byte ranges in
+// matchStyle. Ranges must be sorted and non-overlapping.
+func highlightMatches(text string, ranges [][2]int, base lipgloss.Style) string {
+	var b strings.Builder
+	pos := 0
+	for _, r := range ranges {
+		if r[0] > pos {
+			b.WriteString(StyleIfTerminal(base, text[pos:r[0]]))
+		}
+		b.WriteString(StyleIfTerminal(matchStyle, text[r[0]:r[1]]))
+		pos = r[1]
+	}
+	if pos < len(text) {
+		b.WriteString(StyleIfTerminal(base, text[pos:]))
+	}
+	return b.String()
+}
+
+func grepItems(m GrepMatch) []grepItem {
+	items := make([]grepItem, len(m.Lines))
+	for i, l := range m.Lines {
+		ranges := l.Ranges
+		if ranges == nil {
+			ranges = [][2]int{}
+		}
+		items[i] = grepItem{Field: l.Field, Text: l.Text, Ranges: ranges, Line: l.Line}
+	}
+	return items
+}
+
+func printGrepJSON(matches []GrepMatch) error {
+	type jsonMatch struct {
+		Key     string     `json:"key"`
+		Matches []grepItem `json:"matches"`
+	}
+
+	data := make([]jsonMatch, len(matches))
+	for i, m := range matches {
+		data[i] = jsonMatch{Key: m.Key, Matches: grepItems(m)}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(data)
+}
+
+func printGrepYAML(matches []GrepMatch) error {
+	data := make([]any, len(matches))
+	for i, m := range matches {
+		items := grepItems(m)
+		lines := make([]any, len(items))
+		for j, item := range items {
+			ranges := make([]any, len(item.Ranges))
+			for k, r := range item.Ranges {
+				ranges[k] = []any{r[0], r[1]}
+			}
+			lines[j] = map[string]any{
+				"field":  item.Field,
+				"line":   item.Line,
+				"text":   item.Text,
+				"ranges": ranges,
+			}
+		}
+		data[i] = map[string]any{
+			"key":     m.Key,
+			"matches": lines,
+		}
+	}
+
+	node, err := toNode(data)
+	if err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+	yamlBytes, err := yaml.Marshal(node)
+	if err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+
+	fmt.Print(string(yamlBytes))
+	return nil
+}
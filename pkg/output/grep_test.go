@@ -0,0 +1,124 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPrintGrepMatches(t *testing.T) {
+	matches := []GrepMatch{
+		{
+			Key: "/services/api/db",
+			Lines: []GrepLine{
+				{Field: "value", Text: "host: old-db.internal", Line: 2, Ranges: [][2]int{{6, 21}}},
+			},
+		},
+		{
+			Key: "/services/old-db/host",
+			Lines: []GrepLine{
+				{Field: "key", Text: "/services/old-db/host", Line: 1, Ranges: [][2]int{{10, 16}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		matches  []GrepMatch
+		format   Format
+		expected []string
+	}{
+		{"simple", matches, FormatSimple, []string{
+			"/services/api/db\n",
+			"   2│ host: old-db.internal",
+			"/services/old-db/host\n",
+			"2 matches in 2 keys",
+		}},
+		{"simple without matches", nil, FormatSimple, []string{"No matches found"}},
+		{"json without matches", []GrepMatch{}, FormatJSON, []string{"[]\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := captureStdout(t, func() error {
+				return PrintGrepMatches(tt.matches, tt.format.String())
+			})
+			require.NoError(t, err)
+			for _, expected := range tt.expected {
+				assert.Contains(t, out, expected)
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintGrepMatches(matches, FormatJSON.String())
+		})
+		require.NoError(t, err)
+
+		var decoded []struct {
+			Key     string `json:"key"`
+			Matches []struct {
+				Field  string   `json:"field"`
+				Text   string   `json:"text"`
+				Ranges [][2]int `json:"ranges"`
+				Line   int      `json:"line"`
+			} `json:"matches"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+		require.Len(t, decoded, 2)
+		assert.Equal(t, "/services/api/db", decoded[0].Key)
+		assert.Equal(t, "value", decoded[0].Matches[0].Field)
+		assert.Equal(t, 2, decoded[0].Matches[0].Line)
+		assert.Equal(t, [][2]int{{6, 21}}, decoded[0].Matches[0].Ranges)
+		assert.Equal(t, "key", decoded[1].Matches[0].Field)
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintGrepMatches(matches, FormatYAML.String())
+		})
+		require.NoError(t, err)
+
+		var decoded []map[string]any
+		require.NoError(t, yaml.Unmarshal([]byte(out), &decoded))
+		require.Len(t, decoded, 2)
+		assert.Equal(t, "/services/api/db", decoded[0]["key"])
+		first := decoded[0]["matches"].([]any)[0].(map[string]any)
+		assert.Equal(t, "host: old-db.internal", first["text"])
+		assert.Equal(t, []any{6, 21}, first["ranges"].([]any)[0])
+	})
+
+	t.Run("table is unsupported", func(t *testing.T) {
+		err := PrintGrepMatches(nil, FormatTable.String())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported format")
+	})
+}
+
+func TestGrepSnippet(t *testing.T) {
+	t.Run("short line is kept whole", func(t *testing.T) {
+		assert.Equal(t, "host: old-db", grepSnippet("host: old-db", [][2]int{{6, 12}}))
+	})
+
+	t.Run("long line is cut around the first match", func(t *testing.T) {
+		text := strings.Repeat("a", 200) + "NEEDLE" + strings.Repeat("b", 200)
+		snippet := grepSnippet(text, [][2]int{{200, 206}})
+
+		assert.True(t, strings.HasPrefix(snippet, "…"))
+		assert.True(t, strings.HasSuffix(snippet, "…"))
+		assert.Contains(t, snippet, "NEEDLE")
+		assert.Equal(t, grepSnippetWidth+2*len("…"), len(snippet))
+	})
+
+	t.Run("cut respects rune boundaries", func(t *testing.T) {
+		text := strings.Repeat("é", 100) + "x" + strings.Repeat("é", 100)
+		snippet := grepSnippet(text, [][2]int{{200, 201}})
+		assert.True(t, strings.HasPrefix(snippet, "…é"))
+		assert.Contains(t, snippet, "x")
+	})
+}
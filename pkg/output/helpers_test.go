@@ -137,3 +137,21 @@ func TestTruncate_LongString(t *testing.T) {
 	assert.Equal(t, 50, len([]rune(result)))
 	assert.True(t, strings.HasSuffix(result, "..."))
 }
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		expected string
+		input    int64
+	}{
+		{input: 0, expected: "0 B"},
+		{input: 1023, expected: "1023 B"},
+		{input: 1024, expected: "1.0 KiB"},
+		{input: 1536, expected: "1.5 KiB"},
+		{input: 10 * 1024 * 1024, expected: "10.0 MiB"},
+		{input: 3 * 1024 * 1024 * 1024, expected: "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, FormatBytes(tt.input))
+	}
+}
@@ -0,0 +1,182 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// historyItem is the structured form of a history entry for JSON/YAML output.
+type historyItem struct {
+	Value          string `json:"value" yaml:"value"`
+	Diff           string `json:"diff,omitempty" yaml:"diff,omitempty"`
+	Revision       int64  `json:"revision" yaml:"revision"`
+	CreateRevision int64  `json:"create_revision" yaml:"create_revision"`
+	Version        int64  `json:"version" yaml:"version"`
+}
+
+// PrintHistory prints the revision history of a key in the specified format.
+func PrintHistory(view *HistoryView, format string) error {
+	switch format {
+	case FormatSimple.String():
+		printHistorySimple(view)
+		return nil
+	case FormatJSON.String():
+		return printHistoryJSON(view)
+	case FormatYAML.String():
+		return printHistoryYAML(view)
+	case FormatTable.String():
+		printHistoryTable(view)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s (use simple, json, yaml, or table)", format)
+	}
+}
+
+// previousValue returns the value of the version preceding entry i, if known.
+func previousValue(view *HistoryView, i int) (string, bool) {
+	if i+1 >= len(view.Entries) {
+		return "", false
+	}
+	return view.Entries[i+1].Value, true
+}
+
+func printHistorySimple(view *HistoryView) {
+	Info(fmt.Sprintf("History of %s (%d versions)", view.Key, len(view.Entries)))
+	fmt.Println()
+
+	for i, e := range view.Entries {
+		header := fmt.Sprintf("● version %d  revision %d", e.Version, e.Revision)
+		if e.Version == 1 {
+			header += "  (created)"
+		}
+		fmt.Println(StyleIfTerminal(keyStyle, header))
+
+		if prev, ok := previousValue(view, i); ok {
+			printHistoryChange(prev, e.Value)
+		} else {
+			fmt.Printf("    %s\n", StyleIfTerminal(valueStyle, e.Value))
+		}
+		fmt.Println()
+	}
+
+	printHistoryNotes(view)
+}
+
+// printHistoryChange prints what changed between two consecutive versions.
+func printHistoryChange(prev, value string) {
+	if prev == value {
+		fmt.Printf("    %s\n", StyleIfTerminal(unchangedStyle, "(value unchanged)"))
+		return
+	}
+	if isMultiline(prev) || isMultiline(value) {
+		if hunks, ok := unifiedDiff(prev, value, diffContextLines); ok {
+			printLineDiff(hunks)
+			return
+		}
+	}
+	fmt.Printf("    %sold: %s\n", StyleIfTerminal(oldValueStyle, "  "), StyleIfTerminal(oldValueStyle, prev))
+	fmt.Printf("    %snew: %s\n", StyleIfTerminal(newValueStyle, "  "), StyleIfTerminal(newValueStyle, value))
+}
+
+// printHistoryNotes explains why the history may be incomplete.
+func printHistoryNotes(view *HistoryView) {
+	if len(view.Entries) == 0 {
+		return
+	}
+	oldest := view.Entries[len(view.Entries)-1]
+	switch {
+	case view.Compacted:
+		Warning(fmt.Sprintf("Older versions were compacted; history stops at revision %d", oldest.Revision))
+	case view.Truncated:
+		Info(fmt.Sprintf("Showing %d most recent versions; use --limit to see more", len(view.Entries)))
+	}
+}
+
+func historyItems(view *HistoryView) []historyItem {
+	items := make([]historyItem, len(view.Entries))
+	for i, e := range view.Entries {
+		items[i] = historyItem{
+			Value:          e.Value,
+			Revision:       e.Revision,
+			CreateRevision: e.CreateRevision,
+			Version:        e.Version,
+		}
+		if prev, ok := previousValue(view, i); ok {
+			items[i].Diff = UnifiedDiffText(prev, e.Value)
+		}
+	}
+	return items
+}
+
+func printHistoryJSON(view *HistoryView) error {
+	data := map[string]any{
+		"key":       view.Key,
+		"versions":  historyItems(view),
+		"compacted": view.Compacted,
+		"truncated": view.Truncated,
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func printHistoryYAML(view *HistoryView) error {
+	items := historyItems(view)
+	versions := make([]any, len(items))
+	for i, item := range items {
+		entry := map[string]any{
+			"revision":        int(item.Revision),
+			"create_revision": int(item.CreateRevision),
+			"version":         int(item.Version),
+			"value":           item.Value,
+		}
+		if item.Diff != "" {
+			entry["diff"] = item.Diff
+		}
+		versions[i] = entry
+	}
+
+	data := map[string]any{
+		"key":       view.Key,
+		"versions":  versions,
+		"compacted": view.Compacted,
+		"truncated": view.Truncated,
+	}
+
+	yamlBytes, err := SerializeYAML(data)
+	if err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+
+	fmt.Print(string(yamlBytes))
+	return nil
+}
+
+func printHistoryTable(view *HistoryView) {
+	headers := []string{"VERSION", "REVISION", "VALUE", "PREVIOUS"}
+	rows := make([][]string, len(view.Entries))
+
+	for i, e := range view.Entries {
+		prev, ok := previousValue(view, i)
+		if !ok {
+			prev = "-"
+		} else {
+			prev = Truncate(prev, 30)
+		}
+		rows[i] = []string{
+			fmt.Sprintf("%d", e.Version),
+			fmt.Sprintf("%d", e.Revision),
+			Truncate(e.Value, 40),
+			prev,
+		}
+	}
+
+	fmt.Println(RenderTable(TableConfig{
+		Headers: headers,
+		Rows:    rows,
+	}))
+
+	printHistoryNotes(view)
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPrintHistory(t *testing.T) {
+	entries := []HistoryEntry{
+		{Value: "host: a\nport: 2", Revision: 20, CreateRevision: 5, Version: 3},
+		{Value: "host: a\nport: 1", Revision: 9, CreateRevision: 5, Version: 2},
+		{Value: "initial", Revision: 5, CreateRevision: 5, Version: 1},
+	}
+	view := &HistoryView{Key: "/app/config", Entries: entries}
+
+	t.Run("Simple format", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintHistory(view, FormatSimple.String())
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "version 3  revision 20")
+		assert.Contains(t, out, "version 1  revision 5  (created)")
+		assert.Contains(t, out, "-port: 1")
+		assert.Contains(t, out, "+port: 2")
+		assert.Contains(t, out, "    initial")
+	})
+
+	t.Run("Simple format with compacted history", func(t *testing.T) {
+		compacted := &HistoryView{Key: "/app/config", Entries: entries[:2], Compacted: true}
+		out, err := captureStdout(t, func() error {
+			return PrintHistory(compacted, FormatSimple.String())
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "compacted")
+		assert.Contains(t, out, "revision 9")
+		assert.NotContains(t, out, "(created)")
+	})
+
+	t.Run("JSON format", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintHistory(view, FormatJSON.String())
+		})
+		require.NoError(t, err)
+
+		var result struct {
+			Key      string `json:"key"`
+			Versions []struct {
+				Value    string `json:"value"`
+				Diff     string `json:"diff"`
+				Revision int64  `json:"revision"`
+				Version  int64  `json:"version"`
+			} `json:"versions"`
+			Compacted bool `json:"compacted"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(out), &result))
+
+		assert.Equal(t, "/app/config", result.Key)
+		require.Len(t, result.Versions, 3)
+		assert.Equal(t, int64(20), result.Versions[0].Revision)
+		assert.Contains(t, result.Versions[0].Diff, "+port: 2")
+		assert.Empty(t, result.Versions[2].Diff)
+		assert.False(t, result.Compacted)
+	})
+
+	t.Run("YAML format", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintHistory(view, FormatYAML.String())
+		})
+		require.NoError(t, err)
+
+		var result map[string]any
+		require.NoError(t, yaml.Unmarshal([]byte(out), &result))
+		assert.Equal(t, "/app/config", result["key"])
+		versions, ok := result["versions"].([]any)
+		require.True(t, ok)
+		assert.Len(t, versions, 3)
+	})
+
+	t.Run("Table format with truncated history", func(t *testing.T) {
+		truncated := &HistoryView{Key: "/app/config", Entries: entries, Truncated: true}
+		out, err := captureStdout(t, func() error {
+			return PrintHistory(truncated, FormatTable.String())
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "VERSION")
+		assert.Contains(t, out, "PREVIOUS")
+		assert.Contains(t, out, "initial")
+		assert.Contains(t, out, "--limit")
+	})
+
+	t.Run("Invalid format returns error", func(t *testing.T) {
+		err := PrintHistory(view, "tree")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported format")
+	})
+}
@@ -0,0 +1,105 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// keyInfoItem is the structured form of a KeyInfo for JSON output.
+type keyInfoItem struct {
+	Key            string `json:"key"`
+	Size           int64  `json:"size"`
+	CreateRevision int64  `json:"create_revision"`
+	ModRevision    int64  `json:"mod_revision"`
+	Version        int64  `json:"version"`
+	Lease          int64  `json:"lease,omitempty"`
+}
+
+// PrintKeyInfos prints keys with their metadata in the specified format.
+// Simple output lists one key per line.
+func PrintKeyInfos(items []KeyInfo, format string) error {
+	switch format {
+	case FormatSimple.String():
+		for _, item := range items {
+			fmt.Println(item.Key)
+		}
+		return nil
+	case FormatJSON.String():
+		return printKeyInfosJSON(items)
+	case FormatYAML.String():
+		return printKeyInfosYAML(items)
+	case FormatTable.String():
+		printKeyInfosTable(items)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s (use simple, json, yaml, or table)", format)
+	}
+}
+
+func printKeyInfosJSON(items []KeyInfo) error {
+	data := make([]keyInfoItem, len(items))
+	for i, item := range items {
+		data[i] = keyInfoItem(item)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(data)
+}
+
+func printKeyInfosYAML(items []KeyInfo) error {
+	data := make([]any, len(items))
+	for i, item := range items {
+		entry := map[string]any{
+			"key":             item.Key,
+			"size":            int(item.Size),
+			"create_revision": int(item.CreateRevision),
+			"mod_revision":    int(item.ModRevision),
+			"version":         int(item.Version),
+		}
+		if item.Lease != 0 {
+			entry["lease"] = int(item.Lease)
+		}
+		data[i] = entry
+	}
+
+	node, err := toNode(data)
+	if err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+	yamlBytes, err := yaml.Marshal(node)
+	if err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+
+	fmt.Print(string(yamlBytes))
+	return nil
+}
+
+func printKeyInfosTable(items []KeyInfo) {
+	headers := []string{"KEY", "SIZE", "VERSION", "MOD REVISION", "LEASE"}
+	rows := make([][]string, len(items))
+
+	for i, item := range items {
+		lease := "-"
+		if item.Lease != 0 {
+			lease = fmt.Sprintf("%x", item.Lease)
+		}
+		rows[i] = []string{
+			item.Key,
+			FormatBytes(item.Size),
+			fmt.Sprintf("%d", item.Version),
+			fmt.Sprintf("%d", item.ModRevision),
+			lease,
+		}
+	}
+
+	fmt.Println(RenderTable(TableConfig{
+		Headers: headers,
+		Rows:    rows,
+	}))
+}
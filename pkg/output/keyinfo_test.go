@@ -0,0 +1,76 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPrintKeyInfos(t *testing.T) {
+	infos := []KeyInfo{
+		{Key: "/app/big", Size: 20480, CreateRevision: 3, ModRevision: 9, Version: 4},
+		{Key: "/app/session", Size: 0, CreateRevision: 5, ModRevision: 5, Version: 1, Lease: 0x694d},
+	}
+
+	t.Run("Simple format lists keys", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintKeyInfos(infos, FormatSimple.String())
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "/app/big\n/app/session\n", out)
+	})
+
+	t.Run("JSON format", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintKeyInfos(infos, FormatJSON.String())
+		})
+		require.NoError(t, err)
+
+		var decoded []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+		require.Len(t, decoded, 2)
+		assert.Equal(t, "/app/big", decoded[0]["key"])
+		assert.Equal(t, float64(20480), decoded[0]["size"])
+		assert.NotContains(t, decoded[0], "lease")
+		assert.Equal(t, float64(0x694d), decoded[1]["lease"])
+	})
+
+	t.Run("JSON format without keys", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintKeyInfos(nil, FormatJSON.String())
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "[]\n", out)
+	})
+
+	t.Run("YAML format", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintKeyInfos(infos, FormatYAML.String())
+		})
+		require.NoError(t, err)
+
+		var decoded []map[string]any
+		require.NoError(t, yaml.Unmarshal([]byte(out), &decoded))
+		require.Len(t, decoded, 2)
+		assert.Equal(t, 9, decoded[0]["mod_revision"])
+		assert.Equal(t, 0x694d, decoded[1]["lease"])
+	})
+
+	t.Run("Table format", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintKeyInfos(infos, FormatTable.String())
+		})
+		require.NoError(t, err)
+		assert.Contains(t, out, "MOD REVISION")
+		assert.Contains(t, out, "20.0 KiB")
+		assert.Contains(t, out, "694d")
+	})
+
+	t.Run("Invalid format returns error", func(t *testing.T) {
+		err := PrintKeyInfos(nil, FormatTree.String())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,215 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// diffContextLines is the number of unchanged lines shown around each change.
+	diffContextLines = 3
+
+	// maxLineDiffCells bounds the LCS table size; larger values fall back to
+	// printing the whole old and new value.
+	maxLineDiffCells = 4_000_000
+)
+
+// lineDiffKind identifies whether a line was kept, removed or added.
+type lineDiffKind int
+
+const (
+	lineEqual lineDiffKind = iota
+	lineRemoved
+	lineAdded
+)
+
+// lineDiffOp is a single line of a line-level diff.
+type lineDiffOp struct {
+	Text string
+	Kind lineDiffKind
+}
+
+// diffHunk is a group of nearby changes with surrounding context lines.
+type diffHunk struct {
+	Header string
+	Lines  []lineDiffOp
+}
+
+// isMultiline reports whether a value spans more than one line.
+func isMultiline(s string) bool {
+	return strings.Contains(s, "\n")
+}
+
+// diffLines computes a line-level diff between two slices using the
+// longest common subsequence. Returns false if the inputs are too large.
+func diffLines(oldLines, newLines []string) ([]lineDiffOp, bool) {
+	n, m := len(oldLines), len(newLines)
+	if (n+1)*(m+1) > maxLineDiffCells {
+		return nil, false
+	}
+
+	// lcs[i][j] holds the LCS length of oldLines[i:] and newLines[j:]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]lineDiffOp, 0, max(n, m))
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			ops = append(ops, lineDiffOp{Kind: lineEqual, Text: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, lineDiffOp{Kind: lineRemoved, Text: oldLines[i]})
+			i++
+		default:
+			ops = append(ops, lineDiffOp{Kind: lineAdded, Text: newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, lineDiffOp{Kind: lineRemoved, Text: oldLines[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, lineDiffOp{Kind: lineAdded, Text: newLines[j]})
+	}
+
+	return ops, true
+}
+
+// unifiedDiff computes unified diff hunks between two multi-line values.
+// Returns false if the values are too large to diff line by line.
+func unifiedDiff(oldVal, newVal string, context int) ([]diffHunk, bool) {
+	ops, ok := diffLines(strings.Split(oldVal, "\n"), strings.Split(newVal, "\n"))
+	if !ok {
+		return nil, false
+	}
+
+	var hunks []diffHunk
+	for start := 0; start < len(ops); {
+		first := nextChange(ops, start)
+		if first < 0 {
+			break
+		}
+
+		// Extend the hunk while the next change is within 2*context lines
+		last := first
+		for {
+			next := nextChange(ops, last+1)
+			if next < 0 || next-last > 2*context {
+				break
+			}
+			last = next
+		}
+
+		from := max(first-context, 0)
+		to := min(last+context+1, len(ops))
+		hunks = append(hunks, buildHunk(ops, from, to))
+		start = to
+	}
+
+	return hunks, true
+}
+
+// nextChange returns the index of the next non-equal op at or after from, or -1.
+func nextChange(ops []lineDiffOp, from int) int {
+	for i := from; i < len(ops); i++ {
+		if ops[i].Kind != lineEqual {
+			return i
+		}
+	}
+	return -1
+}
+
+// buildHunk creates a hunk for ops[from:to] with a "@@ -a,b +c,d @@" header.
+func buildHunk(ops []lineDiffOp, from, to int) diffHunk {
+	var oldBefore, newBefore int
+	for _, op := range ops[:from] {
+		if op.Kind != lineAdded {
+			oldBefore++
+		}
+		if op.Kind != lineRemoved {
+			newBefore++
+		}
+	}
+
+	var oldCount, newCount int
+	for _, op := range ops[from:to] {
+		if op.Kind != lineAdded {
+			oldCount++
+		}
+		if op.Kind != lineRemoved {
+			newCount++
+		}
+	}
+
+	return diffHunk{
+		Header: fmt.Sprintf("@@ -%s +%s @@", hunkRange(oldBefore, oldCount), hunkRange(newBefore, newCount)),
+		Lines:  ops[from:to],
+	}
+}
+
+// hunkRange formats a unified diff range; empty ranges point at the preceding line.
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// printLineDiff prints unified diff hunks indented under a modified key.
+func printLineDiff(hunks []diffHunk) {
+	for _, h := range hunks {
+		fmt.Printf("    %s\n", StyleIfTerminal(valueStyle, h.Header))
+		for _, line := range h.Lines {
+			switch line.Kind {
+			case lineRemoved:
+				fmt.Printf("    %s\n", StyleIfTerminal(oldValueStyle, "-"+line.Text))
+			case lineAdded:
+				fmt.Printf("    %s\n", StyleIfTerminal(newValueStyle, "+"+line.Text))
+			default:
+				fmt.Printf("    %s\n", StyleIfTerminal(valueStyle, " "+line.Text))
+			}
+		}
+	}
+}
+
+// UnifiedDiffText renders a unified line diff between two values as plain text.
+// Returns an empty string if the values are equal or too large to diff.
+func UnifiedDiffText(oldVal, newVal string) string {
+	hunks, ok := unifiedDiff(oldVal, newVal, diffContextLines)
+	if !ok {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, h := range hunks {
+		b.WriteString(h.Header)
+		b.WriteString("\n")
+		for _, line := range h.Lines {
+			switch line.Kind {
+			case lineRemoved:
+				b.WriteString("-")
+			case lineAdded:
+				b.WriteString("+")
+			default:
+				b.WriteString(" ")
+			}
+			b.WriteString(line.Text)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
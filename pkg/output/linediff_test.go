@@ -0,0 +1,127 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffLines(t *testing.T) {
+	ops, ok := diffLines([]string{"a", "b", "c"}, []string{"a", "x", "c"})
+	require.True(t, ok)
+
+	assert.Equal(t, []lineDiffOp{
+		{Kind: lineEqual, Text: "a"},
+		{Kind: lineRemoved, Text: "b"},
+		{Kind: lineAdded, Text: "x"},
+		{Kind: lineEqual, Text: "c"},
+	}, ops)
+}
+
+func TestDiffLines_AppendAndRemove(t *testing.T) {
+	ops, ok := diffLines([]string{"a"}, []string{"a", "b"})
+	require.True(t, ok)
+	assert.Equal(t, []lineDiffOp{
+		{Kind: lineEqual, Text: "a"},
+		{Kind: lineAdded, Text: "b"},
+	}, ops)
+
+	ops, ok = diffLines([]string{"a", "b"}, []string{"b"})
+	require.True(t, ok)
+	assert.Equal(t, []lineDiffOp{
+		{Kind: lineRemoved, Text: "a"},
+		{Kind: lineEqual, Text: "b"},
+	}, ops)
+}
+
+func TestDiffLines_TooLarge(t *testing.T) {
+	big := make([]string, 3000)
+	_, ok := diffLines(big, big)
+	assert.False(t, ok)
+}
+
+func TestUnifiedDiff_SingleHunk(t *testing.T) {
+	oldVal := "line1\nline2\nline3\nline4\nline5"
+	newVal := "line1\nline2\nchanged\nline4\nline5"
+
+	hunks, ok := unifiedDiff(oldVal, newVal, 1)
+	require.True(t, ok)
+	require.Len(t, hunks, 1)
+
+	assert.Equal(t, "@@ -2,3 +2,3 @@", hunks[0].Header)
+	assert.Equal(t, []lineDiffOp{
+		{Kind: lineEqual, Text: "line2"},
+		{Kind: lineRemoved, Text: "line3"},
+		{Kind: lineAdded, Text: "changed"},
+		{Kind: lineEqual, Text: "line4"},
+	}, hunks[0].Lines)
+}
+
+func TestUnifiedDiff_SeparateHunks(t *testing.T) {
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", i+1)
+	}
+	oldVal := strings.Join(lines, "\n")
+
+	changed := append([]string(nil), lines...)
+	changed[1] = "first change"
+	changed[18] = "second change"
+	newVal := strings.Join(changed, "\n")
+
+	hunks, ok := unifiedDiff(oldVal, newVal, 3)
+	require.True(t, ok)
+	require.Len(t, hunks, 2)
+	assert.Equal(t, "@@ -1,5 +1,5 @@", hunks[0].Header)
+	assert.Equal(t, "@@ -16,5 +16,5 @@", hunks[1].Header)
+}
+
+func TestUnifiedDiff_NoChanges(t *testing.T) {
+	hunks, ok := unifiedDiff("a\nb", "a\nb", 3)
+	require.True(t, ok)
+	assert.Empty(t, hunks)
+}
+
+func TestUnifiedDiff_EmptyRange(t *testing.T) {
+	hunks, ok := unifiedDiff("a\nb", "a\nb\nc", 0)
+	require.True(t, ok)
+	require.Len(t, hunks, 1)
+	assert.Equal(t, "@@ -2,0 +3,1 @@", hunks[0].Header)
+}
+
+func TestPrintModifiedEntry_MultilineUsesLineDiff(t *testing.T) {
+	entry := &DiffEntry{
+		Key:      "/app/config",
+		Status:   DiffStatusModified,
+		OldValue: "host: a\nport: 1\nname: app",
+		NewValue: "host: a\nport: 2\nname: app",
+	}
+
+	output, err := captureStdout(t, func() error {
+		printModifiedEntry("~", entry)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "@@ -1,3 +1,3 @@")
+	assert.Contains(t, output, "-port: 1")
+	assert.Contains(t, output, "+port: 2")
+	assert.Contains(t, output, " host: a")
+	assert.NotContains(t, output, "old:")
+}
+
+func TestPrintModifiedEntry_SingleLineKeepsOldNew(t *testing.T) {
+	entry := &DiffEntry{Key: "/app/key", Status: DiffStatusModified, OldValue: "a", NewValue: "b"}
+
+	output, err := captureStdout(t, func() error {
+		printModifiedEntry("~", entry)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "old: a")
+	assert.Contains(t, output, "new: b")
+	assert.NotContains(t, output, "@@")
+}
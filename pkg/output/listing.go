@@ -0,0 +1,216 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// listItem is the structured form of a ListEntry for JSON output. Metadata
+// fields are omitted for directories; size is only set in long listings.
+type listItem struct {
+	Size           *int64 `json:"size,omitempty"`
+	Name           string `json:"name"`
+	Key            string `json:"key"`
+	Keys           int64  `json:"keys,omitempty"`
+	CreateRevision int64  `json:"create_revision,omitempty"`
+	ModRevision    int64  `json:"mod_revision,omitempty"`
+	Version        int64  `json:"version,omitempty"`
+	Lease          int64  `json:"lease,omitempty"`
+	Dir            bool   `json:"dir"`
+}
+
+// PrintListing prints a directory-style listing in the specified format.
+// Directories are shown with a trailing '/' and the number of keys below
+// them. With long set, keys also show their size, version, mod revision and
+// lease.
+func PrintListing(entries []ListEntry, format string, long bool) error {
+	switch format {
+	case FormatSimple.String():
+		if long {
+			printListingLong(entries)
+		} else {
+			printListingShort(entries)
+		}
+		return nil
+	case FormatJSON.String():
+		return printListingJSON(entries, long)
+	case FormatYAML.String():
+		return printListingYAML(entries, long)
+	case FormatTable.String():
+		printListingTable(entries, long)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s (use simple, json, yaml, or table)", format)
+	}
+}
+
+func countKeys(n int64) string {
+	if n == 1 {
+		return "1 key"
+	}
+	return fmt.Sprintf("%d keys", n)
+}
+
+func listingName(e ListEntry) string {
+	if e.Dir {
+		return StyleIfTerminal(keyStyle, e.Name+"/")
+	}
+	return e.Name
+}
+
+func printListingShort(entries []ListEntry) {
+	for _, e := range entries {
+		if e.Dir {
+			fmt.Printf("%s  %s\n", listingName(e), StyleIfTerminal(valueStyle, "("+countKeys(e.Keys)+")"))
+			continue
+		}
+		fmt.Println(e.Name)
+	}
+}
+
+// listingColumns returns the size, version, mod revision and lease columns
+// of a long listing. Directories show their key count in place of a size.
+func listingColumns(e ListEntry) [4]string {
+	if e.Dir {
+		return [4]string{countKeys(e.Keys), "-", "-", "-"}
+	}
+	lease := "-"
+	if e.Info.Lease != 0 {
+		lease = fmt.Sprintf("%x", e.Info.Lease)
+	}
+	return [4]string{
+		FormatBytes(e.Info.Size),
+		fmt.Sprintf("%d", e.Info.Version),
+		fmt.Sprintf("%d", e.Info.ModRevision),
+		lease,
+	}
+}
+
+func printListingLong(entries []ListEntry) {
+	header := [4]string{"SIZE", "VERSION", "MOD REV", "LEASE"}
+	rows := make([][4]string, len(entries))
+	var widths [4]int
+	for i := range header {
+		widths[i] = len(header[i])
+	}
+	for i, e := range entries {
+		rows[i] = listingColumns(e)
+		for j, col := range rows[i] {
+			widths[j] = max(widths[j], len(col))
+		}
+	}
+
+	format := func(cols [4]string) string {
+		var b strings.Builder
+		for j, col := range cols {
+			fmt.Fprintf(&b, "%*s  ", widths[j], col)
+		}
+		return b.String()
+	}
+
+	fmt.Println(StyleIfTerminal(valueStyle, format(header)+"NAME"))
+	for i, e := range entries {
+		fmt.Println(format(rows[i]) + listingName(e))
+	}
+}
+
+func toListItem(e ListEntry, long bool) listItem {
+	item := listItem{Name: e.Name, Key: e.Info.Key, Dir: e.Dir}
+	if e.Dir {
+		item.Keys = e.Keys
+		return item
+	}
+	item.CreateRevision = e.Info.CreateRevision
+	item.ModRevision = e.Info.ModRevision
+	item.Version = e.Info.Version
+	item.Lease = e.Info.Lease
+	if long {
+		size := e.Info.Size
+		item.Size = &size
+	}
+	return item
+}
+
+func printListingJSON(entries []ListEntry, long bool) error {
+	data := make([]listItem, len(entries))
+	for i, e := range entries {
+		data[i] = toListItem(e, long)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(data)
+}
+
+func printListingYAML(entries []ListEntry, long bool) error {
+	data := make([]any, len(entries))
+	for i, e := range entries {
+		item := toListItem(e, long)
+		entry := map[string]any{
+			"name": item.Name,
+			"key":  item.Key,
+			"dir":  item.Dir,
+		}
+		if item.Dir {
+			entry["keys"] = int(item.Keys)
+		} else {
+			entry["create_revision"] = int(item.CreateRevision)
+			entry["mod_revision"] = int(item.ModRevision)
+			entry["version"] = int(item.Version)
+			if item.Lease != 0 {
+				entry["lease"] = int(item.Lease)
+			}
+			if item.Size != nil {
+				entry["size"] = int(*item.Size)
+			}
+		}
+		data[i] = entry
+	}
+
+	node, err := toNode(data)
+	if err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+	yamlBytes, err := yaml.Marshal(node)
+	if err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+
+	fmt.Print(string(yamlBytes))
+	return nil
+}
+
+func printListingTable(entries []ListEntry, long bool) {
+	headers := []string{"NAME", "KEYS"}
+	if long {
+		headers = []string{"NAME", "SIZE", "VERSION", "MOD REVISION", "LEASE"}
+	}
+
+	rows := make([][]string, len(entries))
+	for i, e := range entries {
+		name := e.Name
+		if e.Dir {
+			name += "/"
+		}
+		if long {
+			cols := listingColumns(e)
+			rows[i] = []string{name, cols[0], cols[1], cols[2], cols[3]}
+			continue
+		}
+		keys := "-"
+		if e.Dir {
+			keys = fmt.Sprintf("%d", e.Keys)
+		}
+		rows[i] = []string{name, keys}
+	}
+
+	fmt.Println(RenderTable(TableConfig{
+		Headers: headers,
+		Rows:    rows,
+	}))
+}
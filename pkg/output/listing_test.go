@@ -0,0 +1,87 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPrintListing(t *testing.T) {
+	entries := []ListEntry{
+		{Name: "config", Info: KeyInfo{Key: "/app/config/"}, Keys: 3, Dir: true},
+		{Name: "db", Info: KeyInfo{Key: "/app/db/"}, Keys: 1, Dir: true},
+		{Name: "name", Info: KeyInfo{Key: "/app/name", Size: 1536, CreateRevision: 2, ModRevision: 8, Version: 3, Lease: 0x2a}},
+	}
+
+	tests := []struct {
+		name     string
+		format   Format
+		long     bool
+		expected []string
+	}{
+		{"simple", FormatSimple, false, []string{"config/  (3 keys)\ndb/  (1 key)\nname\n"}},
+		{"simple long", FormatSimple, true, []string{
+			"   SIZE  VERSION  MOD REV  LEASE  NAME\n",
+			" 3 keys        -        -      -  config/\n",
+			"1.5 KiB        3        8     2a  name\n",
+		}},
+		{"table", FormatTable, false, []string{"config/", "KEYS"}},
+		{"table long", FormatTable, true, []string{"MOD REVISION", "1.5 KiB"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := captureStdout(t, func() error {
+				return PrintListing(entries, tt.format.String(), tt.long)
+			})
+			require.NoError(t, err)
+			for _, expected := range tt.expected {
+				assert.Contains(t, out, expected)
+			}
+		})
+	}
+
+	t.Run("json", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintListing(entries, FormatJSON.String(), false)
+		})
+		require.NoError(t, err)
+
+		var decoded []map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+		require.Len(t, decoded, 3)
+		assert.Equal(t, map[string]any{"name": "config", "key": "/app/config/", "keys": float64(3), "dir": true}, decoded[0])
+		assert.Equal(t, float64(8), decoded[2]["mod_revision"])
+		assert.NotContains(t, decoded[2], "size")
+
+		out, err = captureStdout(t, func() error {
+			return PrintListing(entries, FormatJSON.String(), true)
+		})
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+		assert.Equal(t, float64(1536), decoded[2]["size"])
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintListing(entries, FormatYAML.String(), true)
+		})
+		require.NoError(t, err)
+
+		var decoded []map[string]any
+		require.NoError(t, yaml.Unmarshal([]byte(out), &decoded))
+		require.Len(t, decoded, 3)
+		assert.Equal(t, 3, decoded[0]["keys"])
+		assert.Equal(t, 1536, decoded[2]["size"])
+		assert.Equal(t, 42, decoded[2]["lease"])
+	})
+
+	t.Run("tree is unsupported", func(t *testing.T) {
+		err := PrintListing(nil, "tree", false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported format")
+	})
+}
@@ -36,17 +36,23 @@ func PrintConfigPairs(pairs []*models.ConfigPair, jsonOutput bool) error {
 
 // PrintConfigPairsWithFormat prints configuration pairs in the specified format
 func PrintConfigPairsWithFormat(pairs []*models.ConfigPair, format string) error {
+	return PrintConfigPairsWithLayout(pairs, format, models.KeyLayout{})
+}
+
+// PrintConfigPairsWithLayout prints configuration pairs in the specified
+// format, nesting keys for the yaml and tree formats as layout splits them
+func PrintConfigPairsWithLayout(pairs []*models.ConfigPair, format string, layout models.KeyLayout) error {
 	switch format {
 	case FormatSimple.String():
 		return PrintConfigPairs(pairs, false)
 	case FormatJSON.String():
 		return printJSON(pairs)
 	case FormatYAML.String():
-		return printConfigPairsYAML(pairs)
+		return printConfigPairsYAML(pairs, layout)
 	case FormatTable.String():
 		return printConfigPairsTable(pairs)
 	case FormatTree.String():
-		return PrintTree(pairs)
+		return PrintTreeWithLayout(pairs, layout)
 	default:
 		return fmt.Errorf("unsupported format: %s", format)
 	}
@@ -129,11 +135,15 @@ func printValidationSummary(errorCount, warningCount int) {
 }
 
 // printValidationIssues prints each validation issue with appropriate styling.
+// Issues with a source position are prefixed with file:line:col.
 func printValidationIssues(issues []validator.ValidationIssue) {
 	for _, issue := range issues {
 		prefix, style := getIssueStyle(issue.Level)
 		key := StyleIfTerminal(keyStyle, issue.Key)
 		msg := fmt.Sprintf("%s %s: %s", prefix, key, issue.Message)
+		if issue.Position.IsValid() {
+			msg = fmt.Sprintf("%s %s: %s: %s", prefix, issue.Position, key, issue.Message)
+		}
 		fmt.Println(StyleIfTerminal(style, msg))
 	}
 	fmt.Println()
@@ -198,7 +208,18 @@ func printValidationTable(result *validator.ValidationResult, strict bool) error
 		return nil
 	}
 
+	showLocation := false
+	for _, issue := range result.Issues {
+		if issue.Position.IsValid() {
+			showLocation = true
+			break
+		}
+	}
+
 	headers := []string{"LEVEL", "KEY", "MESSAGE"}
+	if showLocation {
+		headers = []string{"LEVEL", "LOCATION", "KEY", "MESSAGE"}
+	}
 	rows := make([][]string, len(result.Issues))
 
 	for i, issue := range result.Issues {
@@ -209,6 +230,9 @@ func printValidationTable(result *validator.ValidationResult, strict bool) error
 			level = "⚠ WARNING"
 		}
 		rows[i] = []string{level, issue.Key, issue.Message}
+		if showLocation {
+			rows[i] = []string{level, issue.Position.String(), issue.Key, issue.Message}
+		}
 	}
 
 	table := RenderTable(TableConfig{
@@ -551,7 +575,7 @@ func PrintError(err error) {
 	fmt.Println(StyleIfTerminal(errorPanelStyle, msg))
 }
 
-func printConfigPairsYAML(pairs []*models.ConfigPair) error {
+func printConfigPairsYAML(pairs []*models.ConfigPair, layout models.KeyLayout) error {
 	var nilCount int
 	var emptyValueKeys []string
 	validPairs := make([]*models.ConfigPair, 0, len(pairs))
@@ -576,7 +600,7 @@ func printConfigPairsYAML(pairs []*models.ConfigPair) error {
 			len(emptyValueKeys), emptyValueKeys)
 	}
 
-	nested, err := parsers.UnflattenMap(validPairs)
+	nested, err := parsers.UnflattenMapWithOptions(validPairs, &parsers.UnflattenOptions{Layout: layout})
 	if err != nil {
 		return fmt.Errorf("failed to unflatten keys: %w", err)
 	}
@@ -762,25 +786,36 @@ func PrintSecurityWarning() {
 
 // PrintTree renders etcd configuration as a tree structure
 func PrintTree(pairs []*models.ConfigPair) error {
+	return PrintTreeWithLayout(pairs, models.KeyLayout{})
+}
+
+// PrintTreeWithLayout renders etcd configuration as a tree, splitting keys
+// by layout
+func PrintTreeWithLayout(pairs []*models.ConfigPair, layout models.KeyLayout) error {
 	Info(fmt.Sprintf("Found %d configuration items", len(pairs)))
 
-	t := buildEtcdTree(pairs)
+	t := buildEtcdTree(pairs, layout)
 	fmt.Println(t)
 	return nil
 }
 
 // buildEtcdTree builds a lipgloss tree from config pairs
-func buildEtcdTree(pairs []*models.ConfigPair) *tree.Tree {
-	root := tree.Root("/").
+func buildEtcdTree(pairs []*models.ConfigPair, layout models.KeyLayout) *tree.Tree {
+	r := layout.Resolved()
+	label := r.Root
+	if label == "" {
+		label = r.Separator
+	}
+	root := tree.Root(label).
 		Enumerator(tree.RoundedEnumerator)
 
 	if IsTerminal() {
 		root = root.RootStyle(treeRootStyle).EnumeratorStyle(treeEnumeratorStyle)
 	}
 
-	// Build hierarchical structure
+	// Build hierarchical structure, keyed by the key of each folder
 	pathMap := make(map[string]*tree.Tree)
-	pathMap["/"] = root
+	pathMap[""] = root
 
 	// Sort pairs by path for consistent output
 	sorted := make([]*models.ConfigPair, len(pairs))
@@ -790,19 +825,17 @@ func buildEtcdTree(pairs []*models.ConfigPair) *tree.Tree {
 	})
 
 	for _, pair := range sorted {
-		parts := strings.Split(strings.Trim(pair.Key, "/"), "/")
+		var parts []string
+		for _, part := range layout.Split(pair.Key) {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
 		currentPath := ""
 
 		for i, part := range parts {
-			if part == "" {
-				continue
-			}
-
 			parentPath := currentPath
-			if parentPath == "" {
-				parentPath = "/"
-			}
-			currentPath = currentPath + "/" + part
+			currentPath = layout.Join(currentPath, part)
 
 			if _, exists := pathMap[currentPath]; !exists {
 				parent := pathMap[parentPath]
@@ -814,7 +847,7 @@ func buildEtcdTree(pairs []*models.ConfigPair) *tree.Tree {
 					parent.Child(leaf)
 				} else {
 					folder := tree.New().
-						Root(StyleIfTerminal(treeFolderStyle, part+"/"))
+						Root(StyleIfTerminal(treeFolderStyle, part+r.Separator))
 					if IsTerminal() {
 						folder = folder.EnumeratorStyle(treeEnumeratorStyle)
 					}
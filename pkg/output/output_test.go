@@ -477,6 +477,47 @@ func TestPrintTree(t *testing.T) {
 	})
 }
 
+func TestPrintTreeWithLayout(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "config:app:name", Value: "myapp"},
+		{Key: "config:app:db:host", Value: "localhost"},
+	}
+
+	output, err := testutil.CaptureStdoutFunc(func() {
+		require.NoError(t, PrintTreeWithLayout(pairs, models.KeyLayout{Separator: ":", Root: "config:"}))
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "config:\n")
+	assert.Contains(t, output, "app:")
+	assert.Contains(t, output, "db:")
+	assert.Contains(t, output, "host localhost")
+	assert.Contains(t, output, "name myapp")
+	assert.NotContains(t, output, "config:app")
+}
+
+func TestPrintConfigPairsWithLayout(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "app.name", Value: "myapp"},
+		{Key: "app.db.host", Value: "localhost"},
+	}
+	layout := models.KeyLayout{Separator: "."}
+
+	output, err := testutil.CaptureStdoutFunc(func() {
+		require.NoError(t, PrintConfigPairsWithLayout(pairs, FormatYAML.String(), layout))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "app:\n    db:\n        host: localhost\n    name: myapp\n", output)
+
+	// The yaml and tree formats nest keys the same way
+	output, err = testutil.CaptureStdoutFunc(func() {
+		require.NoError(t, PrintConfigPairsWithLayout(pairs, FormatTree.String(), layout))
+	})
+	require.NoError(t, err)
+	assert.Contains(t, output, "host localhost")
+	assert.NotContains(t, output, "app.db")
+}
+
 func TestPrintConfigPairsWithFormat(t *testing.T) {
 	pairs := []*models.ConfigPair{
 		{Key: "/app/name", Value: "myapp"},
@@ -535,6 +576,63 @@ func TestPrintConfigPairsWithFormat(t *testing.T) {
 	})
 }
 
+func TestPrintValidationWithFormat_Positions(t *testing.T) {
+	result := &validator.ValidationResult{
+		Valid: false,
+		Issues: []validator.ValidationIssue{
+			{
+				Key:      "/app/bad key",
+				Message:  "key contains invalid characters",
+				Level:    "error",
+				Position: models.Position{File: "app.yaml", Line: 3, Column: 5},
+			},
+			{Key: "/app/other", Message: "value is empty string", Level: "warning"},
+		},
+	}
+
+	t.Run("Simple format", func(t *testing.T) {
+		output, err := testutil.CaptureStdout(func() error {
+			return PrintValidationWithFormat(result, false, "simple")
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "✗ app.yaml:3:5: /app/bad key: key contains invalid characters")
+		assert.Contains(t, output, "⚠ /app/other: value is empty string")
+	})
+
+	t.Run("JSON format", func(t *testing.T) {
+		output, err := testutil.CaptureStdout(func() error {
+			return PrintValidationWithFormat(result, false, "json")
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, `"File": "app.yaml"`)
+		assert.Contains(t, output, `"Line": 3`)
+		assert.Contains(t, output, `"Column": 5`)
+	})
+
+	t.Run("YAML format", func(t *testing.T) {
+		output, err := testutil.CaptureStdout(func() error {
+			return PrintValidationWithFormat(result, false, "yaml")
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "file: app.yaml")
+		assert.Contains(t, output, "line: 3")
+		assert.Contains(t, output, "column: 5")
+	})
+
+	t.Run("Table format", func(t *testing.T) {
+		output, err := testutil.CaptureStdout(func() error {
+			return PrintValidationWithFormat(result, false, "table")
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, output, "LOCATION")
+		assert.Contains(t, output, "app.yaml:3:5")
+	})
+}
+
 func TestPrintValidationWithFormat(t *testing.T) {
 	result := &validator.ValidationResult{
 		Valid: true,
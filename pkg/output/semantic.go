@@ -0,0 +1,99 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseStructured decodes a value as JSON or YAML and reports whether it
+// holds a map or a list. Scalars are not considered structured so that
+// plain strings keep being compared byte for byte.
+func parseStructured(s string) (any, bool) {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		if yamlErr := yaml.Unmarshal([]byte(s), &v); yamlErr != nil {
+			return nil, false
+		}
+		// Round-trip through JSON so YAML and JSON values share one representation
+		normalized, marshalErr := json.Marshal(v)
+		if marshalErr != nil {
+			return nil, false
+		}
+		v = nil
+		if err := json.Unmarshal(normalized, &v); err != nil {
+			return nil, false
+		}
+	}
+
+	switch v.(type) {
+	case map[string]any, []any:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// canonicalValue renders a structured value as YAML with sorted keys.
+func canonicalValue(v any) (string, bool) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimRight(string(data), "\n"), true
+}
+
+// compareSemantic compares two values structurally. It returns whether both
+// values are structured, whether they are equal, and their canonical forms.
+func compareSemantic(oldVal, newVal string) (structured, equal bool, oldCanon, newCanon string) {
+	oldParsed, ok := parseStructured(oldVal)
+	if !ok {
+		return false, false, "", ""
+	}
+	newParsed, ok := parseStructured(newVal)
+	if !ok {
+		return false, false, "", ""
+	}
+
+	if reflect.DeepEqual(oldParsed, newParsed) {
+		return true, true, "", ""
+	}
+
+	oldCanon, oldOK := canonicalValue(oldParsed)
+	newCanon, newOK := canonicalValue(newParsed)
+	if !oldOK || !newOK {
+		return true, false, "", ""
+	}
+	return true, false, oldCanon, newCanon
+}
+
+// ValidateIgnorePatterns checks that all --ignore-keys patterns are valid globs.
+func ValidateIgnorePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid ignore pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// matchesIgnorePattern reports whether a key matches any ignore pattern.
+// Patterns containing "/" are matched against the full key, other patterns
+// against the last key segment (e.g. "updated_at" or "*_timestamp").
+func matchesIgnorePattern(key string, patterns []string) bool {
+	base := path.Base(key)
+	for _, p := range patterns {
+		target := base
+		if strings.Contains(p, "/") {
+			target = key
+		}
+		if ok, _ := path.Match(p, target); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStructured(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		structured bool
+	}{
+		{"json object", `{"a": 1}`, true},
+		{"json array", `[1, 2]`, true},
+		{"yaml map", "a: 1\nb: two", true},
+		{"yaml list", "- a\n- b", true},
+		{"plain string", "hello", false},
+		{"number", "8080", false},
+		{"multi-line text", "first line\nsecond line", false},
+		{"invalid", "{not json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := parseStructured(tt.input)
+			assert.Equal(t, tt.structured, ok)
+		})
+	}
+}
+
+func TestCompareSemantic(t *testing.T) {
+	t.Run("key order and whitespace ignored", func(t *testing.T) {
+		structured, equal, _, _ := compareSemantic(`{"a":1,"b":[1,2]}`, "{\n  \"b\": [1, 2],\n  \"a\": 1\n}")
+		assert.True(t, structured)
+		assert.True(t, equal)
+	})
+
+	t.Run("json and yaml with same content", func(t *testing.T) {
+		structured, equal, _, _ := compareSemantic(`{"host":"db","port":5432}`, "port: 5432\nhost: db")
+		assert.True(t, structured)
+		assert.True(t, equal)
+	})
+
+	t.Run("different content returns canonical forms", func(t *testing.T) {
+		structured, equal, oldCanon, newCanon := compareSemantic(`{"b":1,"a":1}`, `{"a":1,"b":2}`)
+		assert.True(t, structured)
+		assert.False(t, equal)
+		assert.Equal(t, "a: 1\nb: 1", oldCanon)
+		assert.Equal(t, "a: 1\nb: 2", newCanon)
+	})
+
+	t.Run("scalars are not structured", func(t *testing.T) {
+		structured, _, _, _ := compareSemantic("a", "b")
+		assert.False(t, structured)
+	})
+}
+
+func TestDiffKeyValuesWithOptions_Semantic(t *testing.T) {
+	fileMap := map[string]string{
+		"/app/json":  `{"b":2,"a":1}`,
+		"/app/yaml":  "a: 1\nb: 3",
+		"/app/plain": "x ",
+	}
+	etcdMap := map[string]string{
+		"/app/json":  `{"a": 1, "b": 2}`,
+		"/app/yaml":  "b: 2\na: 1",
+		"/app/plain": "x",
+	}
+
+	result, err := DiffKeyValuesWithOptions(fileMap, etcdMap, &DiffOptions{Semantic: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Unchanged)
+	assert.Equal(t, 2, result.Modified)
+
+	byKey := make(map[string]*DiffEntry)
+	for _, e := range result.Entries {
+		byKey[e.Key] = e
+	}
+	assert.Equal(t, DiffStatusUnchanged, byKey["/app/json"].Status)
+	assert.Equal(t, DiffStatusModified, byKey["/app/yaml"].Status)
+	assert.Equal(t, DiffStatusModified, byKey["/app/plain"].Status)
+
+	oldVal, newVal := byKey["/app/yaml"].displayValues()
+	assert.Equal(t, "a: 1\nb: 2", oldVal)
+	assert.Equal(t, "a: 1\nb: 3", newVal)
+}
+
+func TestDiffKeyValuesWithOptions_NotSemantic(t *testing.T) {
+	fileMap := map[string]string{"/app/json": `{"b":2,"a":1}`}
+	etcdMap := map[string]string{"/app/json": `{"a":1,"b":2}`}
+
+	result, err := DiffKeyValuesWithOptions(fileMap, etcdMap, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Modified)
+}
+
+func TestDiffKeyValuesWithOptions_IgnoreKeys(t *testing.T) {
+	fileMap := map[string]string{
+		"/app/name":           "new",
+		"/app/updated_at":     "2024-02-01",
+		"/app/svc/build_ts":   "2",
+		"/other/svc/build_ts": "2",
+	}
+	etcdMap := map[string]string{
+		"/app/name":         "old",
+		"/app/updated_at":   "2024-01-01",
+		"/app/svc/build_ts": "1",
+	}
+
+	result, err := DiffKeyValuesWithOptions(fileMap, etcdMap, &DiffOptions{
+		IgnoreKeys: []string{"updated_at", "/app/*/build_ts"},
+	})
+	require.NoError(t, err)
+
+	keys := make([]string, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		keys = append(keys, e.Key)
+	}
+	assert.Equal(t, []string{"/app/name", "/other/svc/build_ts"}, keys)
+	assert.Equal(t, 1, result.Modified)
+	assert.Equal(t, 1, result.Added)
+}
+
+func TestDiffKeyValuesWithOptions_InvalidPattern(t *testing.T) {
+	_, err := DiffKeyValuesWithOptions(nil, nil, &DiffOptions{IgnoreKeys: []string{"[bad"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ignore pattern")
+}
+
+func TestMatchesIgnorePattern(t *testing.T) {
+	tests := []struct {
+		key      string
+		patterns []string
+		want     bool
+	}{
+		{"/app/updated_at", []string{"updated_at"}, true},
+		{"/app/x/created_ts", []string{"*_ts"}, true},
+		{"/app/x/host", []string{"*_ts"}, false},
+		{"/app/x/ts", []string{"/app/*/ts"}, true},
+		{"/app/x/y/ts", []string{"/app/*/ts"}, false},
+		{"/app/host", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchesIgnorePattern(tt.key, tt.patterns))
+		})
+	}
+}
+
+func TestPrintModifiedEntry_SemanticUsesCanonicalValues(t *testing.T) {
+	result, err := DiffKeyValuesWithOptions(
+		map[string]string{"/app/cfg": `{"z":1,"a":{"x":1,"port":3}}`},
+		map[string]string{"/app/cfg": `{"a":{"port":2,"x":1},"z":1}`},
+		&DiffOptions{Semantic: true},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 1)
+
+	output, err := captureStdout(t, func() error {
+		printModifiedEntry("~", result.Entries[0])
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "-    port: 2")
+	assert.Contains(t, output, "+    port: 3")
+	assert.NotContains(t, output, "-z: 1")
+}
@@ -126,3 +126,15 @@ var (
 	newValueStyle = lipgloss.NewStyle().
 			Foreground(colorSuccess)
 )
+
+// Search styles
+var (
+	// matchStyle highlights the matched text inside grep snippets.
+	matchStyle = lipgloss.NewStyle().
+			Foreground(colorWarning).
+			Bold(true).
+			Underline(true)
+
+	lineNumberStyle = lipgloss.NewStyle().
+			Foreground(colorMuted)
+)
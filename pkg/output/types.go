@@ -26,3 +26,86 @@ type DryRunOperation struct {
 	Key   string `json:"key"`
 	Value string `json:"value,omitempty"`
 }
+
+// HistoryEntry represents a single version of a key for display purposes.
+type HistoryEntry struct {
+	Value          string
+	Revision       int64
+	CreateRevision int64
+	Version        int64
+}
+
+// HistoryView represents the revision history of a key for display purposes.
+// Entries are ordered newest first.
+type HistoryView struct {
+	Key     string
+	Entries []HistoryEntry
+
+	// Compacted is set when older versions exist but have been compacted away.
+	Compacted bool
+
+	// Truncated is set when the walk stopped early because of --limit.
+	Truncated bool
+}
+
+// GrepLine is one line of a key or value that matched a search pattern.
+type GrepLine struct {
+	// Field is "key" or "value".
+	Field string
+
+	// Text is the full text of the matching line.
+	Text string
+
+	// Line is the 1-based line number within the value. It is 1 for keys.
+	Line int
+
+	// Ranges are the byte offsets [start, end) of each match within Text.
+	Ranges [][2]int
+}
+
+// GrepMatch represents a key whose name or value matched a search pattern.
+type GrepMatch struct {
+	Key   string
+	Lines []GrepLine
+}
+
+// KeyInfo represents a key and its metadata for display purposes.
+type KeyInfo struct {
+	Key            string
+	Size           int64
+	CreateRevision int64
+	ModRevision    int64
+	Version        int64
+	Lease          int64
+}
+
+// ListEntry represents one line of a directory-style listing. Info.Key is
+// the full key, or the prefix of a directory including its trailing '/'.
+type ListEntry struct {
+	Name string
+	Info KeyInfo
+
+	// Keys is the number of keys below a directory.
+	Keys int64
+	Dir  bool
+}
+
+// UsageNode represents the aggregated size of a subtree for display purposes.
+// Children are ordered largest first.
+type UsageNode struct {
+	Name     string
+	Path     string
+	Largest  string
+	Children []*UsageNode
+
+	// Bytes is the total size of all values in the subtree.
+	Bytes       int64
+	LargestSize int64
+
+	// Keys is the number of keys in the subtree, including the node itself.
+	Keys int
+
+	// Dir is set when keys exist below this node, even if they are not
+	// listed as children because of the depth limit.
+	Dir bool
+}
@@ -0,0 +1,156 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss/tree"
+)
+
+// usageItem is the structured form of a UsageNode for JSON output.
+type usageItem struct {
+	Largest  *usageLargest `json:"largest,omitempty"`
+	Path     string        `json:"path"`
+	Children []usageItem   `json:"children,omitempty"`
+	Bytes    int64         `json:"bytes"`
+	Keys     int           `json:"keys"`
+	Dir      bool          `json:"dir"`
+}
+
+type usageLargest struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size"`
+}
+
+// PrintUsage prints subtree statistics in the specified format. Simple and
+// tree output render the same styled tree as PrintTree.
+func PrintUsage(root *UsageNode, format string) error {
+	switch format {
+	case FormatSimple.String(), FormatTree.String():
+		fmt.Println(buildUsageTree(root))
+		return nil
+	case FormatJSON.String():
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(toUsageItem(root))
+	case FormatYAML.String():
+		return printUsageYAML(root)
+	case FormatTable.String():
+		printUsageTable(root)
+		return nil
+	default:
+		return fmt.Errorf("unsupported format: %s (use simple, json, yaml, table, or tree)", format)
+	}
+}
+
+func usageSummary(n *UsageNode) string {
+	noun := "keys"
+	if n.Keys == 1 {
+		noun = "key"
+	}
+	summary := fmt.Sprintf("%s · %d %s", FormatBytes(n.Bytes), n.Keys, noun)
+	if n.Dir && n.Largest != "" {
+		summary += fmt.Sprintf(" · largest %s (%s)", n.Largest, FormatBytes(n.LargestSize))
+	}
+	return summary
+}
+
+func buildUsageTree(root *UsageNode) *tree.Tree {
+	t := tree.Root(StyleIfTerminal(treeRootStyle, root.Path) + "  " + StyleIfTerminal(treeValueStyle, usageSummary(root))).
+		Enumerator(tree.RoundedEnumerator)
+	if IsTerminal() {
+		t = t.EnumeratorStyle(treeEnumeratorStyle)
+	}
+	addUsageChildren(t, root)
+	return t
+}
+
+func addUsageChildren(parent *tree.Tree, node *UsageNode) {
+	for _, child := range node.Children {
+		label := StyleIfTerminal(treeKeyStyle, child.Name)
+		if child.Dir {
+			label = StyleIfTerminal(treeFolderStyle, child.Name+"/")
+		}
+		sub := tree.New().Root(label + "  " + StyleIfTerminal(treeValueStyle, usageSummary(child)))
+		if IsTerminal() {
+			sub = sub.EnumeratorStyle(treeEnumeratorStyle)
+		}
+		addUsageChildren(sub, child)
+		parent.Child(sub)
+	}
+}
+
+func toUsageItem(n *UsageNode) usageItem {
+	item := usageItem{Path: n.Path, Bytes: n.Bytes, Keys: n.Keys, Dir: n.Dir}
+	if n.Largest != "" {
+		item.Largest = &usageLargest{Key: n.Largest, Size: n.LargestSize}
+	}
+	for _, child := range n.Children {
+		item.Children = append(item.Children, toUsageItem(child))
+	}
+	return item
+}
+
+func usageYAMLNode(n *UsageNode) map[string]any {
+	entry := map[string]any{
+		"path":  n.Path,
+		"bytes": int(n.Bytes),
+		"keys":  n.Keys,
+		"dir":   n.Dir,
+	}
+	if n.Largest != "" {
+		entry["largest"] = map[string]any{
+			"key":  n.Largest,
+			"size": int(n.LargestSize),
+		}
+	}
+	if len(n.Children) > 0 {
+		children := make([]any, len(n.Children))
+		for i, child := range n.Children {
+			children[i] = usageYAMLNode(child)
+		}
+		entry["children"] = children
+	}
+	return entry
+}
+
+func printUsageYAML(root *UsageNode) error {
+	yamlBytes, err := SerializeYAML(usageYAMLNode(root))
+	if err != nil {
+		return fmt.Errorf("failed to serialize YAML: %w", err)
+	}
+
+	fmt.Print(string(yamlBytes))
+	return nil
+}
+
+func printUsageTable(root *UsageNode) {
+	headers := []string{"PATH", "SIZE", "KEYS", "LARGEST KEY", "LARGEST SIZE"}
+	var rows [][]string
+
+	var walk func(n *UsageNode)
+	walk = func(n *UsageNode) {
+		path := n.Path
+		if n.Dir && path != root.Path {
+			path += "/"
+		}
+		rows = append(rows, []string{
+			path,
+			FormatBytes(n.Bytes),
+			fmt.Sprintf("%d", n.Keys),
+			n.Largest,
+			FormatBytes(n.LargestSize),
+		})
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+
+	fmt.Println(RenderTable(TableConfig{
+		Headers: headers,
+		Rows:    rows,
+	}))
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPrintUsage(t *testing.T) {
+	root := &UsageNode{
+		Name: "/teams", Path: "/teams", Keys: 3, Bytes: 2048, Dir: true,
+		Largest: "/teams/a/blob", LargestSize: 1500,
+		Children: []*UsageNode{
+			{
+				Name: "a", Path: "/teams/a", Keys: 2, Bytes: 1600, Dir: true,
+				Largest: "/teams/a/blob", LargestSize: 1500,
+			},
+			{
+				Name: "readme", Path: "/teams/readme", Keys: 1, Bytes: 448,
+				Largest: "/teams/readme", LargestSize: 448,
+			},
+		},
+	}
+
+	t.Run("Simple format draws a tree", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintUsage(root, FormatSimple.String())
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "/teams  2.0 KiB · 3 keys · largest /teams/a/blob (1.5 KiB)")
+		assert.Contains(t, out, "a/  1.6 KiB · 2 keys")
+		assert.Contains(t, out, "readme  448 B · 1 key")
+		assert.NotContains(t, out, "readme/")
+		assert.Contains(t, out, "╰──", "uses the rounded tree enumerator")
+	})
+
+	t.Run("JSON format nests children", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintUsage(root, FormatJSON.String())
+		})
+		require.NoError(t, err)
+
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal([]byte(out), &decoded))
+		assert.Equal(t, "/teams", decoded["path"])
+		assert.Equal(t, float64(2048), decoded["bytes"])
+		children := decoded["children"].([]any)
+		require.Len(t, children, 2)
+		first := children[0].(map[string]any)
+		assert.Equal(t, "/teams/a", first["path"])
+		assert.Equal(t, "/teams/a/blob", first["largest"].(map[string]any)["key"])
+		assert.NotContains(t, children[1].(map[string]any), "children")
+
+		out, err = captureStdout(t, func() error {
+			return PrintUsage(root, FormatYAML.String())
+		})
+		require.NoError(t, err)
+		require.NoError(t, yaml.Unmarshal([]byte(out), &decoded))
+		assert.Equal(t, 3, decoded["keys"])
+		assert.Len(t, decoded["children"], 2)
+	})
+
+	t.Run("Table format flattens paths", func(t *testing.T) {
+		out, err := captureStdout(t, func() error {
+			return PrintUsage(root, FormatTable.String())
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "LARGEST KEY")
+		assert.Contains(t, out, "/teams/a/")
+		assert.Contains(t, out, "/teams/readme")
+		assert.Contains(t, out, "448 B")
+	})
+
+	t.Run("Invalid format returns error", func(t *testing.T) {
+		require.Error(t, PrintUsage(root, "xml"))
+	})
+}
@@ -0,0 +1,212 @@
+package parsers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+// DotenvParser parses .env files
+// Format:
+//
+//	# comment
+//	export APP_NAME=myapp
+//	APP_DB_HOST="db.example.com" # inline comment
+//	APP_GREETING='literal $value'
+//
+// Keys are converted to etcd paths with KeyMapping.
+type DotenvParser struct {
+	// KeyMapping overrides DefaultDotenvKeyMapping when set.
+	KeyMapping *KeyMapping
+
+	// Layout joins the mapped path segments. The zero value gives
+	// /app/db/host.
+	Layout models.KeyLayout
+}
+
+// FormatName returns the name of this format
+func (p *DotenvParser) FormatName() string {
+	return "dotenv"
+}
+
+// Parse reads a dotenv file. Double-quoted values support \n, \r, \t, \", \\
+// and \$ escapes; single-quoted values are literal. Both may span lines.
+// Unquoted values end at a " #" comment. When a key is assigned more than
+// once the last value wins.
+func (p *DotenvParser) Parse(ctx context.Context, path string) ([]*models.ConfigPair, error) {
+	return parseFile(ctx, path, p.ParseReader)
+}
+
+// ParseReader parses dotenv content from a stream
+func (p *DotenvParser) ParseReader(ctx context.Context, r io.Reader) ([]*models.ConfigPair, error) {
+	mapping := DefaultDotenvKeyMapping
+	if p.KeyMapping != nil {
+		mapping = *p.KeyMapping
+	}
+
+	var result mappedPairs
+	lines := newLineReader(r)
+	for lines.next() {
+		// Check for cancellation
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		line := strings.TrimSpace(lines.text)
+		pos := models.Position{Line: lines.num, Column: len(lines.text) - len(strings.TrimLeft(lines.text, " \t")) + 1}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(line, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
+
+		name, rawValue, found := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !found {
+			return nil, lineError(pos, "expected KEY=VALUE")
+		}
+		if !isDotenvName(name) {
+			return nil, lineError(pos, "invalid variable name %q", name)
+		}
+
+		value, err := p.parseValue(strings.TrimLeft(rawValue, " \t"), lines)
+		if err != nil {
+			return nil, lineError(pos, "%v", err)
+		}
+
+		key := mapping.MapKey(name)
+		if key == "" {
+			return nil, lineError(pos, "variable %q maps to an empty key", name)
+		}
+		result.set(p.Layout.FromPath(key), value, pos)
+	}
+
+	if err := lines.err(); err != nil {
+		return nil, err
+	}
+
+	return result.pairs, nil
+}
+
+// parseValue parses the text after '=', reading further lines from lines
+// when a quoted value is not closed on the first one.
+func (p *DotenvParser) parseValue(raw string, lines *lineReader) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	quote := raw[0]
+	if quote != '"' && quote != '\'' {
+		return stripInlineComment(raw), nil
+	}
+
+	var value strings.Builder
+	text := raw[1:]
+	for {
+		rest, closed := scanQuoted(text, quote, &value)
+		if closed {
+			rest = strings.TrimSpace(rest)
+			if rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", fmt.Errorf("unexpected text after closing quote: %q", rest)
+			}
+			return value.String(), nil
+		}
+		if !lines.next() {
+			return "", fmt.Errorf("unterminated %c-quoted value", quote)
+		}
+		value.WriteByte('\n')
+		text = lines.text
+	}
+}
+
+// scanQuoted appends the quoted text up to the closing quote to value and
+// returns what follows it. closed is false when the line ends first.
+func scanQuoted(text string, quote byte, value *strings.Builder) (rest string, closed bool) {
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == quote {
+			return text[i+1:], true
+		}
+		if c == '\\' && quote == '"' && i+1 < len(text) {
+			i++
+			switch text[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\', '$':
+				value.WriteByte(text[i])
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(text[i])
+			}
+			continue
+		}
+		value.WriteByte(c)
+	}
+	return "", false
+}
+
+// stripInlineComment removes a trailing comment that starts with whitespace
+// followed by '#', and trims the value.
+func stripInlineComment(s string) string {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '#' && (s[i-1] == ' ' || s[i-1] == '\t') {
+			s = s[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+// isDotenvName reports whether name is a usable variable name: letters,
+// digits, '_', '.' and '-', not starting with a digit.
+func isDotenvName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '.', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// lineReader scans a file line by line and tracks the 1-based line number.
+type lineReader struct {
+	scanner *bufio.Scanner
+	text    string
+	num     int
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	scanner := bufio.NewScanner(r)
+	// Allow long values such as embedded certificates
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	return &lineReader{scanner: scanner}
+}
+
+func (l *lineReader) next() bool {
+	if !l.scanner.Scan() {
+		return false
+	}
+	l.num++
+	l.text = strings.TrimSuffix(l.scanner.Text(), "\r")
+	return true
+}
+
+func (l *lineReader) err() error {
+	return l.scanner.Err()
+}
@@ -0,0 +1,224 @@
+package parsers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+func TestDotenvParser_FormatName(t *testing.T) {
+	parser := &DotenvParser{}
+	assert.Equal(t, "dotenv", parser.FormatName())
+}
+
+func TestDotenvParser_Parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []*models.ConfigPair
+	}{
+		{
+			name:    "simple assignments",
+			content: "APP_NAME=myapp\nAPP_DB_HOST=db.example.com\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/name", Value: "myapp"},
+				{Key: "/app/db/host", Value: "db.example.com"},
+			},
+		},
+		{
+			name:    "comments and blank lines",
+			content: "# database\n\nDB_PORT=5432 # default port\nDB_URL=postgres://db#1\n",
+			expected: []*models.ConfigPair{
+				{Key: "/db/port", Value: "5432"},
+				{Key: "/db/url", Value: "postgres://db#1"},
+			},
+		},
+		{
+			name:    "export prefix",
+			content: "export APP_NAME=myapp\nexport\tAPP_ENV = prod\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/name", Value: "myapp"},
+				{Key: "/app/env", Value: "prod"},
+			},
+		},
+		{
+			name:    "double quoted with escapes",
+			content: `APP_MOTD="hello\n\"world\" \$HOME" # comment` + "\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/motd", Value: "hello\n\"world\" $HOME"},
+			},
+		},
+		{
+			name:    "single quoted is literal",
+			content: `APP_PATTERN='a\nb # not a comment'` + "\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/pattern", Value: `a\nb # not a comment`},
+			},
+		},
+		{
+			name:    "multiline quoted value",
+			content: "APP_CERT=\"-----BEGIN-----\nabc\n-----END-----\"\nAPP_NAME=x\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/cert", Value: "-----BEGIN-----\nabc\n-----END-----"},
+				{Key: "/app/name", Value: "x"},
+			},
+		},
+		{
+			name:    "empty value",
+			content: "APP_EMPTY=\nAPP_QUOTED=\"\"\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/empty", Value: ""},
+				{Key: "/app/quoted", Value: ""},
+			},
+		},
+		{
+			name:    "last assignment wins",
+			content: "APP_NAME=first\nAPP_ENV=dev\nAPP_NAME=second\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/name", Value: "second"},
+				{Key: "/app/env", Value: "dev"},
+			},
+		},
+		{
+			name:    "windows line endings",
+			content: "APP_NAME=myapp\r\nAPP_ENV=prod\r\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/name", Value: "myapp"},
+				{Key: "/app/env", Value: "prod"},
+			},
+		},
+		{
+			name:    "variable named export",
+			content: "export=yes\n",
+			expected: []*models.ConfigPair{
+				{Key: "/export", Value: "yes"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, err := parseDotenv(t, &DotenvParser{}, tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, withoutPositions(pairs))
+		})
+	}
+}
+
+func TestDotenvParser_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{
+			name:    "missing equals",
+			content: "APP_NAME=ok\nJUSTAWORD\n",
+			errMsg:  "2:1: expected KEY=VALUE",
+		},
+		{
+			name:    "invalid name",
+			content: "1APP=x\n",
+			errMsg:  `1:1: invalid variable name "1APP"`,
+		},
+		{
+			name:    "unterminated quote",
+			content: "APP_NAME=\"open\nstill open\n",
+			errMsg:  "1:1: unterminated \"-quoted value",
+		},
+		{
+			name:    "text after closing quote",
+			content: "APP_NAME=\"a\" b\n",
+			errMsg:  "1:1: unexpected text after closing quote",
+		},
+		{
+			name:    "name maps to empty key",
+			content: "___=x\n",
+			errMsg:  `1:1: variable "___" maps to an empty key`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDotenv(t, &DotenvParser{}, tt.content)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestDotenvParser_CustomKeyMapping(t *testing.T) {
+	parser := &DotenvParser{KeyMapping: &KeyMapping{
+		Separator:   "__",
+		StripPrefix: "MYAPP__",
+		Prefix:      "/services/api",
+	}}
+
+	pairs, err := parseDotenv(t, parser, "MYAPP__Database__Host=db\nMYAPP__LOG_LEVEL=debug\n")
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "/services/api/Database/Host", Value: "db"},
+		{Key: "/services/api/LOG_LEVEL", Value: "debug"},
+	}, withoutPositions(pairs))
+}
+
+func TestDotenvParser_Layout(t *testing.T) {
+	parser := &DotenvParser{Layout: models.KeyLayout{Separator: "."}}
+
+	pairs, err := parseDotenv(t, parser, "APP_DB_HOST=db\nAPP_NAME=x\n")
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "app.db.host", Value: "db"},
+		{Key: "app.name", Value: "x"},
+	}, withoutPositions(pairs))
+}
+
+func TestDotenvParser_Positions(t *testing.T) {
+	content := "# header\nAPP_NAME=myapp\n  export APP_ENV=prod\nAPP_CERT=\"a\nb\"\nAPP_PORT=80\nAPP_NAME=again\n"
+
+	pairs, err := parseDotenv(t, &DotenvParser{}, content)
+	require.NoError(t, err)
+	require.Len(t, pairs, 4)
+	assertPosition(t, 7, 1, pairs[0].Pos)
+	assertPosition(t, 3, 3, pairs[1].Pos)
+	assertPosition(t, 4, 1, pairs[2].Pos)
+	assertPosition(t, 6, 1, pairs[3].Pos)
+}
+
+func TestDotenvParser_ErrorPosition(t *testing.T) {
+	_, err := parseDotenv(t, &DotenvParser{}, "APP_NAME=ok\n   BROKEN\n")
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assertPosition(t, 2, 4, parseErr.Pos)
+}
+
+func TestDotenvParser_FileNotFound(t *testing.T) {
+	parser := &DotenvParser{}
+	_, err := parser.Parse(context.Background(), "/nonexistent/.env")
+	assert.Error(t, err)
+}
+
+func TestDotenvParser_ContextCanceled(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(tmpFile, []byte("APP_NAME=myapp\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	parser := &DotenvParser{}
+	_, err := parser.Parse(ctx, tmpFile)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func parseDotenv(t *testing.T, parser *DotenvParser, content string) ([]*models.ConfigPair, error) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0644))
+	return parser.Parse(context.Background(), tmpFile)
+}
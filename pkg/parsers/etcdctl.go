@@ -3,7 +3,7 @@ package parsers
 import (
 	"bufio"
 	"context"
-	"os"
+	"io"
 	"strings"
 
 	"github.com/kazuma-desu/etu/pkg/models"
@@ -27,15 +27,16 @@ func (p *EtcdctlParser) FormatName() string {
 
 // Parse reads and parses an etcdctl format file with cancellation support
 func (p *EtcdctlParser) Parse(ctx context.Context, path string) ([]*models.ConfigPair, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+	return parseFile(ctx, path, p.ParseReader)
+}
 
+// ParseReader parses etcdctl format from a stream with cancellation support
+func (p *EtcdctlParser) ParseReader(ctx context.Context, r io.Reader) ([]*models.ConfigPair, error) {
 	var pairs []*models.ConfigPair
 	var currentKey string
+	var currentPos models.Position
 	var currentValueLines []string
+	lineNum := 0
 
 	flushCurrent := func() {
 		if currentKey != "" {
@@ -43,19 +44,21 @@ func (p *EtcdctlParser) Parse(ctx context.Context, path string) ([]*models.Confi
 			pairs = append(pairs, &models.ConfigPair{
 				Key:   currentKey,
 				Value: value,
+				Pos:   currentPos,
 			})
 		}
 		currentKey = ""
 		currentValueLines = nil
 	}
 
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		// Check for cancellation
 		if err := ctx.Err(); err != nil {
 			return nil, err
 		}
 
+		lineNum++
 		line := scanner.Text()
 		trimmed := strings.TrimSpace(line)
 
@@ -71,6 +74,7 @@ func (p *EtcdctlParser) Parse(ctx context.Context, path string) ([]*models.Confi
 		if strings.HasPrefix(trimmed, "/") {
 			flushCurrent()
 			currentKey = trimmed
+			currentPos = models.Position{Line: lineNum, Column: strings.Index(line, "/") + 1}
 			continue
 		}
 
@@ -196,6 +196,20 @@ This is a long description with multiple words`,
 	}
 }
 
+func TestEtcdctlParser_Positions(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "dump.txt")
+	content := "# dump\n/app/name\nmyapp\n\n  /app/motd\nline 1\nline 2\n"
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0644))
+
+	parser := &EtcdctlParser{}
+	pairs, err := parser.Parse(context.Background(), tmpFile)
+	require.NoError(t, err)
+
+	require.Len(t, pairs, 2)
+	assert.Equal(t, models.Position{File: tmpFile, Line: 2, Column: 1}, pairs[0].Pos)
+	assert.Equal(t, models.Position{File: tmpFile, Line: 5, Column: 3}, pairs[1].Pos)
+}
+
 func TestEtcdctlParser_ParseNonExistentFile(t *testing.T) {
 	parser := &EtcdctlParser{}
 	_, err := parser.Parse(context.Background(), "/nonexistent/file/that/does/not/exist.txt")
@@ -1,60 +1,274 @@
 package parsers
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"strconv"
+	"strings"
 
 	"github.com/kazuma-desu/etu/pkg/logger"
 	"github.com/kazuma-desu/etu/pkg/models"
 )
 
+// DirectoryValueKey is the reserved map key that holds the value of a key
+// which also has children, e.g. /app/db alongside /app/db/host:
+//
+//	app:
+//	  db:
+//	    "": primary
+//	    host: localhost
+const DirectoryValueKey = ""
+
+// ArrayMode selects how arrays are stored when a nested map is flattened
+type ArrayMode string
+
+const (
+	// ArrayJSON stores an array as a single JSON-encoded value (default)
+	ArrayJSON ArrayMode = "json"
+
+	// ArrayIndexed stores every element under its index: /list/0, /list/1
+	ArrayIndexed ArrayMode = "indexed"
+
+	// ArrayCSV stores a list of scalars as one comma-separated value, quoted
+	// like encoding/csv. Lists holding maps, arrays or nulls fall back to
+	// JSON. As a CSV value alone cannot tell a one-element list or a string
+	// holding a comma from a list, this mode records value types like
+	// FlattenOptions.PreserveTypes does.
+	ArrayCSV ArrayMode = "csv"
+)
+
+// IsValid checks if the array mode is known. The empty mode means ArrayJSON.
+func (m ArrayMode) IsValid() bool {
+	switch m {
+	case "", ArrayJSON, ArrayIndexed, ArrayCSV:
+		return true
+	default:
+		return false
+	}
+}
+
+// FlattenOptions controls how FlattenMapWithOptions handles arrays and nulls
+type FlattenOptions struct {
+	// Arrays selects how arrays are stored. Empty means ArrayJSON.
+	Arrays ArrayMode
+
+	// KeepEmptyArrays stores empty arrays as empty values instead of
+	// skipping them.
+	KeepEmptyArrays bool
+
+	// KeepNulls stores null values as empty values instead of skipping them.
+	KeepNulls bool
+
+	// PreserveTypes records the type of every scalar and of arrays stored as
+	// JSON in ConfigPair.Type. JSON numbers keep their exact text.
+	PreserveTypes bool
+
+	// Layout joins nested keys. The zero value gives /app/db/host.
+	Layout models.KeyLayout
+
+	// DotenvKeys and PropertiesKeys override DefaultDotenvKeyMapping and
+	// DefaultPropertiesKeyMapping when set. They only apply to the registry
+	// built by NewRegistryWithFlatten.
+	DotenvKeys     *KeyMapping
+	PropertiesKeys *KeyMapping
+}
+
 // FlattenMap recursively flattens a nested map into etcd key-value pairs.
 // Keys are constructed as paths with "/" delimiter (e.g., /app/db/host).
 // Arrays are serialized as JSON strings.
-// Null values are skipped.
+// Null values and empty arrays are skipped; empty strings are kept.
 func FlattenMap(data map[string]any) []*models.ConfigPair {
-	var pairs []*models.ConfigPair
-	flattenRecursive("", data, &pairs)
-	return pairs
+	return FlattenMapWithOptions(data, nil)
 }
 
-func flattenRecursive(prefix string, data map[string]any, pairs *[]*models.ConfigPair) {
+// FlattenMapWithOptions flattens a nested map like FlattenMap, storing arrays
+// and nulls and joining keys as opts describes.
+func FlattenMapWithOptions(data map[string]any, opts *FlattenOptions) []*models.ConfigPair {
+	if opts == nil {
+		opts = &FlattenOptions{}
+	}
+	f := flattener{opts: opts}
+	f.flattenRecursive("", data)
+	return f.pairs
+}
+
+// layout returns the key layout of opts, which may be nil
+func (o *FlattenOptions) layout() models.KeyLayout {
+	if o == nil {
+		return models.KeyLayout{}
+	}
+	return o.Layout
+}
+
+// keyMappings returns the dotenv and properties key mappings of opts, which
+// may be nil
+func (o *FlattenOptions) keyMappings() (dotenv, properties *KeyMapping) {
+	if o == nil {
+		return nil, nil
+	}
+	return o.DotenvKeys, o.PropertiesKeys
+}
+
+type flattener struct {
+	opts  *FlattenOptions
+	pairs []*models.ConfigPair
+}
+
+func (f *flattener) add(key, value string, valueType models.ValueType) {
+	pair := &models.ConfigPair{Key: key, Value: value}
+	if f.opts.PreserveTypes || f.opts.Arrays == ArrayCSV {
+		pair.Type = valueType
+	}
+	f.pairs = append(f.pairs, pair)
+}
+
+func (f *flattener) flattenRecursive(prefix string, data map[string]any) {
 	for key, value := range data {
-		fullKey := prefix + "/" + key
-		flattenValue(fullKey, value, pairs)
+		if key == DirectoryValueKey {
+			f.flattenDirectoryValue(prefix, value)
+			continue
+		}
+		f.flattenValue(f.opts.Layout.Join(prefix, key), value)
 	}
 }
 
-func flattenValue(key string, value any, pairs *[]*models.ConfigPair) {
+// flattenDirectoryValue stores the value held under DirectoryValueKey at the
+// path of the enclosing map itself.
+func (f *flattener) flattenDirectoryValue(prefix string, value any) {
+	key := prefix
+	if key == "" {
+		key = f.opts.Layout.Resolved().Root
+	}
+	if key == "" {
+		logger.Log.Warn("ignoring directory value at the top level; the key layout has no root")
+		return
+	}
+	if _, isMap := value.(map[string]any); isMap {
+		logger.Log.Warn("ignoring nested map under directory value key", "key", key)
+		return
+	}
+	f.flattenValue(key, value)
+}
+
+func (f *flattener) flattenValue(key string, value any) {
 	if value == nil {
+		if f.opts.KeepNulls {
+			f.add(key, "", models.TypeNull)
+		}
 		return
 	}
 
 	switch v := value.(type) {
 	case map[string]any:
-		flattenRecursive(key, v, pairs)
+		f.flattenRecursive(key, v)
 
 	case []any:
-		if len(v) == 0 {
+		f.flattenArray(key, v)
+
+	case string:
+		f.add(key, v, models.TypeString)
+
+	default:
+		formatted := models.FormatValue(v)
+		if formatted == "" {
 			return
 		}
-		serialized, err := json.Marshal(v)
-		if err != nil {
-			logger.Log.Warn("failed to marshal array", "key", key, "error", err)
+		f.add(key, formatted, scalarType(v))
+	}
+}
+
+func (f *flattener) flattenArray(key string, items []any) {
+	if len(items) == 0 {
+		if f.opts.KeepEmptyArrays {
+			valueType := models.TypeArray
+			if f.opts.Arrays == ArrayCSV {
+				valueType = models.TypeList
+			}
+			f.add(key, "", valueType)
+		}
+		return
+	}
+
+	switch f.opts.Arrays {
+	case ArrayIndexed:
+		for i, item := range items {
+			f.flattenValue(f.opts.Layout.Join(key, strconv.Itoa(i)), item)
+		}
+		return
+
+	case ArrayCSV:
+		// CSV fields are strings, so with types preserved other lists are
+		// stored as JSON to keep their element types
+		if value, ok := encodeCSV(items, f.opts.PreserveTypes); ok {
+			f.add(key, value, models.TypeList)
 			return
 		}
-		*pairs = append(*pairs, &models.ConfigPair{
-			Key:   key,
-			Value: string(serialized),
-		})
+	}
+
+	serialized, err := json.Marshal(items)
+	if err != nil {
+		logger.Log.Warn("failed to marshal array", "key", key, "error", err)
+		return
+	}
+	f.add(key, string(serialized), models.TypeArray)
+}
 
+// scalarType returns the type of a decoded non-string scalar, or "" when it
+// has none of the tracked types.
+func scalarType(value any) models.ValueType {
+	switch v := value.(type) {
+	case bool:
+		return models.TypeBool
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return models.TypeInt
+	case float32, float64:
+		return models.TypeFloat
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return models.TypeFloat
+		}
+		return models.TypeInt
 	default:
-		formatted := models.FormatValue(v)
-		if formatted == "" {
-			return
+		return ""
+	}
+}
+
+// encodeCSV joins a list of scalars into a single CSV record. It reports
+// false if any element is a map, an array or null, or with stringsOnly set
+// if any element is not a string.
+func encodeCSV(items []any, stringsOnly bool) (string, bool) {
+	record := make([]string, len(items))
+	for i, item := range items {
+		switch v := item.(type) {
+		case map[string]any, []any, nil:
+			return "", false
+		case string:
+			record[i] = v
+		default:
+			if stringsOnly {
+				return "", false
+			}
+			record[i] = models.FormatValue(v)
 		}
-		*pairs = append(*pairs, &models.ConfigPair{
-			Key:   key,
-			Value: formatted,
-		})
 	}
+	return formatCSVRecord(record)
+}
+
+// formatCSVRecord writes record as one CSV line without the line ending. A
+// record holding one empty field is written as "" so that it differs from
+// an empty list.
+func formatCSVRecord(record []string) (string, bool) {
+	if len(record) == 1 && record[0] == "" {
+		return `""`, true
+	}
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write(record); err != nil {
+		return "", false
+	}
+	w.Flush()
+	if w.Error() != nil {
+		return "", false
+	}
+	return strings.TrimSuffix(b.String(), "\n"), true
 }
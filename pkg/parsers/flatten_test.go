@@ -113,7 +113,7 @@ func TestFlattenMap_NullValueSkipped(t *testing.T) {
 	assertPair(t, pairs, "/present", "value")
 }
 
-func TestFlattenMap_EmptyStringSkipped(t *testing.T) {
+func TestFlattenMap_EmptyStringKept(t *testing.T) {
 	input := map[string]any{
 		"present": "value",
 		"empty":   "",
@@ -121,8 +121,33 @@ func TestFlattenMap_EmptyStringSkipped(t *testing.T) {
 
 	pairs := FlattenMap(input)
 
-	assert.Len(t, pairs, 1)
+	assert.Len(t, pairs, 2)
 	assertPair(t, pairs, "/present", "value")
+	assertPair(t, pairs, "/empty", "")
+}
+
+func TestFlattenMap_DirectoryValue(t *testing.T) {
+	input := map[string]any{
+		"app": map[string]any{
+			"db": map[string]any{
+				DirectoryValueKey: "primary",
+				"host":            "localhost",
+			},
+		},
+	}
+
+	pairs := FlattenMap(input)
+
+	assert.Len(t, pairs, 2)
+	assertPair(t, pairs, "/app/db", "primary")
+	assertPair(t, pairs, "/app/db/host", "localhost")
+}
+
+func TestFlattenMap_RootDirectoryValue(t *testing.T) {
+	pairs := FlattenMap(map[string]any{DirectoryValueKey: "root"})
+
+	assert.Len(t, pairs, 1)
+	assertPair(t, pairs, "/", "root")
 }
 
 func TestFlattenMap_ArrayOfScalars(t *testing.T) {
@@ -160,6 +185,153 @@ func TestFlattenMap_EmptyArraySkipped(t *testing.T) {
 	assert.Len(t, pairs, 0)
 }
 
+func TestFlattenMapWithOptions_ArrayModes(t *testing.T) {
+	input := map[string]any{
+		"tags": []any{"dev", "a,b", 3, true},
+		"servers": []any{
+			map[string]any{"host": "server1"},
+			map[string]any{"host": "server2"},
+		},
+	}
+
+	tests := []struct {
+		mode     ArrayMode
+		expected []*models.ConfigPair
+	}{
+		{ArrayJSON, []*models.ConfigPair{
+			{Key: "/tags", Value: `["dev","a,b",3,true]`},
+			{Key: "/servers", Value: `[{"host":"server1"},{"host":"server2"}]`},
+		}},
+		{ArrayIndexed, []*models.ConfigPair{
+			{Key: "/tags/0", Value: "dev"},
+			{Key: "/tags/1", Value: "a,b"},
+			{Key: "/tags/2", Value: "3"},
+			{Key: "/tags/3", Value: "true"},
+			{Key: "/servers/0/host", Value: "server1"},
+			{Key: "/servers/1/host", Value: "server2"},
+		}},
+		{ArrayCSV, []*models.ConfigPair{
+			{Key: "/tags", Value: `dev,"a,b",3,true`, Type: models.TypeList},
+			// Lists of maps fall back to JSON
+			{Key: "/servers", Value: `[{"host":"server1"},{"host":"server2"}]`, Type: models.TypeArray},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			pairs := FlattenMapWithOptions(input, &FlattenOptions{Arrays: tt.mode})
+			assert.ElementsMatch(t, tt.expected, pairs)
+		})
+	}
+}
+
+func TestFlattenMapWithOptions_IndexedNestedArrays(t *testing.T) {
+	input := map[string]any{
+		"matrix": []any{[]any{1, 2}, []any{3}},
+		"db":     map[string]any{"": []any{"a", "b"}},
+	}
+
+	pairs := FlattenMapWithOptions(input, &FlattenOptions{Arrays: ArrayIndexed})
+
+	assert.ElementsMatch(t, []*models.ConfigPair{
+		{Key: "/matrix/0/0", Value: "1"},
+		{Key: "/matrix/0/1", Value: "2"},
+		{Key: "/matrix/1/0", Value: "3"},
+		{Key: "/db/0", Value: "a"},
+		{Key: "/db/1", Value: "b"},
+	}, pairs)
+}
+
+func TestFlattenMapWithOptions_KeepEmptyArraysAndNulls(t *testing.T) {
+	input := map[string]any{
+		"items": []any{},
+		"unset": nil,
+		"list":  []any{"a", nil},
+	}
+
+	for _, mode := range []ArrayMode{ArrayJSON, ArrayIndexed, ArrayCSV} {
+		t.Run(string(mode), func(t *testing.T) {
+			pairs := FlattenMapWithOptions(input, &FlattenOptions{Arrays: mode})
+			assert.NotContains(t, pairKeys(pairs), "/items")
+			assert.NotContains(t, pairKeys(pairs), "/unset")
+
+			pairs = FlattenMapWithOptions(input, &FlattenOptions{Arrays: mode, KeepEmptyArrays: true, KeepNulls: true})
+			assertPair(t, pairs, "/items", "")
+			assertPair(t, pairs, "/unset", "")
+
+			// Kept empty arrays are typed so they don't come back as ""
+			pairs = FlattenMapWithOptions(input, &FlattenOptions{Arrays: mode, KeepEmptyArrays: true, PreserveTypes: true})
+			for _, pair := range pairs {
+				if pair.Key == "/items" {
+					assert.Contains(t, []models.ValueType{models.TypeArray, models.TypeList}, pair.Type)
+				}
+			}
+		})
+	}
+
+	pairs := FlattenMapWithOptions(input, &FlattenOptions{Arrays: ArrayIndexed})
+	assert.NotContains(t, pairKeys(pairs), "/list/1")
+	pairs = FlattenMapWithOptions(input, &FlattenOptions{Arrays: ArrayIndexed, KeepNulls: true})
+	assertPair(t, pairs, "/list/1", "")
+}
+
+func TestFlattenMapWithOptions_Layout(t *testing.T) {
+	input := map[string]any{
+		"app": map[string]any{
+			DirectoryValueKey: "primary",
+			"db":              map[string]any{"host": "localhost"},
+			"hosts":           []any{"a", "b"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		layout   models.KeyLayout
+		expected []*models.ConfigPair
+	}{
+		{"dots", models.KeyLayout{Separator: "."}, []*models.ConfigPair{
+			{Key: "app", Value: "primary"},
+			{Key: "app.db.host", Value: "localhost"},
+			{Key: "app.hosts.0", Value: "a"},
+			{Key: "app.hosts.1", Value: "b"},
+		}},
+		{"colons with root", models.KeyLayout{Separator: ":", Root: "config:"}, []*models.ConfigPair{
+			{Key: "config:app", Value: "primary"},
+			{Key: "config:app:db:host", Value: "localhost"},
+			{Key: "config:app:hosts:0", Value: "a"},
+			{Key: "config:app:hosts:1", Value: "b"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs := FlattenMapWithOptions(input, &FlattenOptions{Arrays: ArrayIndexed, Layout: tt.layout})
+			assert.ElementsMatch(t, tt.expected, pairs)
+		})
+	}
+}
+
+func TestFlattenMapWithOptions_LayoutRootDirectoryValue(t *testing.T) {
+	input := map[string]any{DirectoryValueKey: "root", "a": "1"}
+
+	pairs := FlattenMapWithOptions(input, &FlattenOptions{Layout: models.KeyLayout{Separator: ":", Root: "config:"}})
+	assert.ElementsMatch(t, []*models.ConfigPair{
+		{Key: "config:", Value: "root"},
+		{Key: "config:a", Value: "1"},
+	}, pairs)
+
+	// Without a root there is no key to hold the value
+	pairs = FlattenMapWithOptions(input, &FlattenOptions{Layout: models.KeyLayout{Separator: "."}})
+	assert.Equal(t, []*models.ConfigPair{{Key: "a", Value: "1"}}, pairs)
+}
+
+func TestArrayMode_IsValid(t *testing.T) {
+	for _, mode := range []ArrayMode{"", ArrayJSON, ArrayIndexed, ArrayCSV} {
+		assert.True(t, mode.IsValid(), mode)
+	}
+	assert.False(t, ArrayMode("yaml").IsValid())
+}
+
 func TestFlattenMap_MixedTypes(t *testing.T) {
 	input := map[string]any{
 		"app": map[string]any{
@@ -269,3 +441,11 @@ func assertPair(t *testing.T, pairs []*models.ConfigPair, key string, expectedVa
 	}
 	t.Errorf("Key %s not found in pairs", key)
 }
+
+func pairKeys(pairs []*models.ConfigPair) []string {
+	keys := make([]string, len(pairs))
+	for i, pair := range pairs {
+		keys[i] = pair.Key
+	}
+	return keys
+}
@@ -1,30 +1,39 @@
 package parsers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"os"
+	"io"
 
 	"github.com/kazuma-desu/etu/pkg/models"
 )
 
 var ErrRootNotObject = errors.New("JSON root must be an object, not an array or scalar")
 
-type JSONParser struct{}
+type JSONParser struct {
+	// Flatten controls how arrays and nulls are flattened and how keys are
+	// joined. Nil keeps the FlattenMap defaults.
+	Flatten *FlattenOptions
+}
 
 func (p *JSONParser) FormatName() string {
 	return "json"
 }
 
 func (p *JSONParser) Parse(ctx context.Context, path string) ([]*models.ConfigPair, error) {
-	// Check for cancellation before reading file
+	return parseFile(ctx, path, p.ParseReader)
+}
+
+func (p *JSONParser) ParseReader(ctx context.Context, r io.Reader) ([]*models.ConfigPair, error) {
+	// Check for cancellation before reading
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
 
-	data, err := os.ReadFile(path)
+	data, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
@@ -40,13 +49,127 @@ func (p *JSONParser) Parse(ctx context.Context, path string) ([]*models.ConfigPa
 
 	var root any
 	if err := json.Unmarshal(data, &root); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		return nil, jsonParseError(data, err)
+	}
+
+	// Decode again keeping numbers as written when types are tracked
+	if p.Flatten != nil && p.Flatten.PreserveTypes {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&root); err != nil {
+			return nil, jsonParseError(data, err)
+		}
 	}
 
 	rootMap, ok := root.(map[string]any)
 	if !ok {
-		return nil, ErrRootNotObject
+		start := skipJSONSeparators(data, 0)
+		return nil, &ParseError{Pos: offsetToPosition(data, start), Msg: ErrRootNotObject.Error(), Err: ErrRootNotObject}
+	}
+
+	pairs := FlattenMapWithOptions(rootMap, p.Flatten)
+	positions := keyPositions{}
+	recordJSONPositions(positions, data)
+	positions.apply(pairs, p.Flatten.layout())
+	return pairs, nil
+}
+
+// recordJSONPositions records the position of every object key in data,
+// which must already be known to be valid JSON with an object root.
+func recordJSONPositions(positions keyPositions, data []byte) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := decoder.Token(); err != nil || tok != json.Delim('{') {
+		return
+	}
+	_ = walkJSONObject(positions, decoder, data, "")
+}
+
+// walkJSONObject records the keys of the object whose opening brace was
+// just read, recursing into nested objects.
+func walkJSONObject(positions keyPositions, decoder *json.Decoder, data []byte, prefix string) error {
+	for decoder.More() {
+		start := skipJSONSeparators(data, decoder.InputOffset())
+		tok, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		name, _ := tok.(string)
+		key := joinKey(prefix, name)
+		positions.record(key, offsetToPosition(data, start))
+
+		tok, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			if name == DirectoryValueKey {
+				err = skipJSONValue(decoder)
+			} else {
+				err = walkJSONObject(positions, decoder, data, key)
+			}
+		case json.Delim('['):
+			err = skipJSONValue(decoder)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// Consume the closing brace
+	_, err := decoder.Token()
+	return err
+}
+
+// skipJSONValue consumes tokens up to the end of the object or array whose
+// opening delimiter was just read.
+func skipJSONValue(decoder *json.Decoder) error {
+	for depth := 1; depth > 0; {
+		tok, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
 	}
+	return nil
+}
 
-	return FlattenMap(rootMap), nil
+// skipJSONSeparators returns the offset of the next token at or after
+// offset, skipping whitespace, commas and colons.
+func skipJSONSeparators(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) {
+		switch data[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+	return offset
+}
+
+// jsonParseError adds the error position to decoder errors that carry a
+// byte offset.
+func jsonParseError(data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset is just past the offending byte
+		offset = max(syntaxErr.Offset-1, 0)
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return &ParseError{
+		Pos: offsetToPosition(data, offset),
+		Msg: "failed to parse JSON: " + err.Error(),
+		Err: err,
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kazuma-desu/etu/pkg/models"
@@ -114,7 +115,7 @@ func TestJSONParser_NullValueSkipped(t *testing.T) {
 	assertJSONPair(t, pairs, "/present", "value")
 }
 
-func TestJSONParser_EmptyStringSkipped(t *testing.T) {
+func TestJSONParser_EmptyStringKept(t *testing.T) {
 	content := `{
 	"present": "value",
 	"empty": ""
@@ -122,8 +123,9 @@ func TestJSONParser_EmptyStringSkipped(t *testing.T) {
 
 	pairs := parseJSON(t, content)
 
-	assert.Len(t, pairs, 1)
+	assert.Len(t, pairs, 2)
 	assertJSONPair(t, pairs, "/present", "value")
+	assertJSONPair(t, pairs, "/empty", "")
 }
 
 func TestJSONParser_ArrayOfScalars(t *testing.T) {
@@ -359,6 +361,52 @@ func TestJSONParser_NestedEmptyMaps(t *testing.T) {
 	assert.Len(t, pairs, 0)
 }
 
+func TestJSONParser_Positions(t *testing.T) {
+	content := `{
+  "app": {
+    "name": "myapp",
+    "tags": ["a", {"x": 1}],
+    "db": {"": "primary", "host": "localhost"}
+  },
+  "port": 8080
+}`
+	pairs := parseJSON(t, content)
+
+	require.Len(t, pairs, 5)
+	assert.Equal(t, "/app/name", pairs[0].Key)
+	assertPosition(t, 3, 5, pairs[0].Pos)
+	assert.Equal(t, "/app/tags", pairs[1].Key)
+	assertPosition(t, 4, 5, pairs[1].Pos)
+	assert.Equal(t, "/app/db", pairs[2].Key)
+	// Directory values point at the parent key
+	assertPosition(t, 5, 5, pairs[2].Pos)
+	assert.Equal(t, "/app/db/host", pairs[3].Key)
+	assertPosition(t, 5, 27, pairs[3].Pos)
+	assert.Equal(t, "/port", pairs[4].Key)
+	assertPosition(t, 7, 3, pairs[4].Pos)
+}
+
+func TestJSONParser_ErrorPosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		column  int
+	}{
+		{"invalid character", "{\n  \"a\": 1,\n  \"b\": x\n}", 3, 8},
+		{"root not an object", "\n  [1, 2]", 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&JSONParser{}).ParseReader(context.Background(), strings.NewReader(tt.content))
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assertPosition(t, tt.line, tt.column, parseErr.Pos)
+		})
+	}
+}
+
 func parseJSON(t *testing.T, content string) []*models.ConfigPair {
 	t.Helper()
 
@@ -384,3 +432,18 @@ func assertJSONPair(t *testing.T, pairs []*models.ConfigPair, key string, expect
 	}
 	t.Errorf("Key %s not found in pairs", key)
 }
+
+func TestJSONParser_Layout(t *testing.T) {
+	content := "{\n  \"app\": {\n    \"name\": \"api\",\n    \"tags\": [\"a\"]\n  }\n}\n"
+
+	parser := &JSONParser{Flatten: &FlattenOptions{Layout: models.KeyLayout{Separator: "::"}}}
+	pairs, err := parser.ParseReader(context.Background(), strings.NewReader(content))
+	require.NoError(t, err)
+
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "app::name", Value: "api"},
+		{Key: "app::tags", Value: `["a"]`},
+	}, withoutPositions(pairs))
+	assertPosition(t, 3, 5, pairs[0].Pos)
+	assertPosition(t, 4, 5, pairs[1].Pos)
+}
@@ -0,0 +1,97 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+// KeyMapping describes how a flat key from a dotenv or properties file, such
+// as APP_DB_HOST or app.db.host, becomes an etcd path like /app/db/host.
+type KeyMapping struct {
+	// Separator splits a key into path segments. An empty separator keeps the
+	// whole key as a single segment.
+	Separator string
+
+	// StripPrefix is removed from the start of a key before it is split,
+	// e.g. "MYAPP_" to drop a shared variable prefix.
+	StripPrefix string
+
+	// Prefix is prepended to every mapped path. Empty means the root "/".
+	Prefix string
+
+	// Lowercase converts every segment to lower case.
+	Lowercase bool
+}
+
+var (
+	// DefaultDotenvKeyMapping maps APP_DB_HOST to /app/db/host.
+	DefaultDotenvKeyMapping = KeyMapping{Separator: "_", Lowercase: true}
+
+	// DefaultPropertiesKeyMapping maps app.db.host to /app/db/host.
+	DefaultPropertiesKeyMapping = KeyMapping{Separator: "."}
+)
+
+// MapKey converts a flat key to an etcd path. Empty segments produced by
+// repeated or trailing separators are dropped. It returns "" when nothing is
+// left of the key.
+func (m KeyMapping) MapKey(key string) string {
+	key = strings.TrimPrefix(key, m.StripPrefix)
+
+	var segments []string
+	if m.Separator == "" {
+		segments = []string{key}
+	} else {
+		segments = strings.Split(key, m.Separator)
+	}
+
+	var path strings.Builder
+	if prefix := strings.Trim(m.Prefix, "/"); prefix != "" {
+		path.WriteString("/" + prefix)
+	}
+	written := false
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		if m.Lowercase {
+			segment = strings.ToLower(segment)
+		}
+		path.WriteString("/")
+		path.WriteString(segment)
+		written = true
+	}
+
+	if !written {
+		return ""
+	}
+	return path.String()
+}
+
+// mappedPairs collects mapped key/value pairs in file order. A key assigned
+// more than once keeps its first place in the order but takes the value and
+// position of its last assignment, matching how dotenv loaders and
+// java.util.Properties resolve duplicates.
+type mappedPairs struct {
+	pairs []*models.ConfigPair
+	index map[string]int
+}
+
+func (m *mappedPairs) set(key, value string, pos models.Position) {
+	if m.index == nil {
+		m.index = make(map[string]int)
+	}
+	if i, ok := m.index[key]; ok {
+		m.pairs[i].Value = value
+		m.pairs[i].Pos = pos
+		return
+	}
+	m.index[key] = len(m.pairs)
+	m.pairs = append(m.pairs, &models.ConfigPair{Key: key, Value: value, Pos: pos})
+}
+
+// lineError returns a *ParseError for a problem on a line of input
+func lineError(pos models.Position, format string, args ...any) error {
+	return &ParseError{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,33 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyMapping_MapKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapping  KeyMapping
+		key      string
+		expected string
+	}{
+		{"dotenv default", DefaultDotenvKeyMapping, "APP_DB_HOST", "/app/db/host"},
+		{"properties default", DefaultPropertiesKeyMapping, "app.db.host", "/app/db/host"},
+		{"properties keeps case", DefaultPropertiesKeyMapping, "app.maxPoolSize", "/app/maxPoolSize"},
+		{"empty segments dropped", DefaultDotenvKeyMapping, "_APP__HOST_", "/app/host"},
+		{"no separator", KeyMapping{}, "APP_DB_HOST", "/APP_DB_HOST"},
+		{"strip prefix", KeyMapping{Separator: "_", StripPrefix: "MYAPP_"}, "MYAPP_DB_HOST", "/DB/HOST"},
+		{"prefix", KeyMapping{Separator: ".", Prefix: "/svc/api/"}, "db.host", "/svc/api/db/host"},
+		{"prefix without slash", KeyMapping{Separator: ".", Prefix: "svc"}, "db", "/svc/db"},
+		{"multi-character separator", KeyMapping{Separator: "__", Lowercase: true}, "DB__MAX_CONN", "/db/max_conn"},
+		{"nothing left", KeyMapping{Separator: "_", StripPrefix: "APP"}, "APP", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.mapping.MapKey(tt.key))
+		})
+	}
+}
@@ -2,10 +2,15 @@ package parsers
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/kazuma-desu/etu/pkg/models"
@@ -18,10 +23,27 @@ type Parser interface {
 	// This enables cancellation of long-running parsing operations for large files or slow filesystems
 	Parse(ctx context.Context, path string) ([]*models.ConfigPair, error)
 
+	// ParseReader parses configuration from a stream such as stdin or an
+	// in-memory buffer
+	ParseReader(ctx context.Context, r io.Reader) ([]*models.ConfigPair, error)
+
 	// FormatName returns the name of the format this parser handles
 	FormatName() string
 }
 
+var (
+	// tomlTableHeader matches [table] and [[array.of.tables]] headers with
+	// bare or quoted keys.
+	tomlTableHeader = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_\-."' ]+\s*\]\]?\s*(#.*)?$`)
+
+	// tomlKeyValue matches a key assignment whose value starts like a TOML
+	// string, number, boolean, array or inline table.
+	tomlKeyValue = regexp.MustCompile(`^[A-Za-z0-9_\-."']+\s*=\s*("|'|\[|\{|[+-]?[0-9]|true\b|false\b|[+-]?inf\b|[+-]?nan\b)`)
+
+	// dotenvExport matches a shell-style "export NAME=" assignment.
+	dotenvExport = regexp.MustCompile(`^export\s+[A-Za-z_][A-Za-z0-9_.\-]*\s*=`)
+)
+
 // Registry maintains a mapping of format types to their parsers
 type Registry struct {
 	parsers map[models.FormatType]Parser
@@ -29,13 +51,24 @@ type Registry struct {
 
 // NewRegistry creates a new parser registry with default parsers
 func NewRegistry() *Registry {
+	return NewRegistryWithFlatten(nil)
+}
+
+// NewRegistryWithFlatten creates a registry whose YAML, JSON and TOML
+// parsers flatten arrays and nulls as opts describes. The key layout and key
+// mappings of opts apply to dotenv and properties files.
+func NewRegistryWithFlatten(opts *FlattenOptions) *Registry {
 	r := &Registry{
 		parsers: make(map[models.FormatType]Parser),
 	}
+	dotenvKeys, propertiesKeys := opts.keyMappings()
 
 	r.Register(models.FormatEtcdctl, &EtcdctlParser{})
-	r.Register(models.FormatYAML, &YAMLParser{})
-	r.Register(models.FormatJSON, &JSONParser{})
+	r.Register(models.FormatYAML, &YAMLParser{Flatten: opts})
+	r.Register(models.FormatJSON, &JSONParser{Flatten: opts})
+	r.Register(models.FormatTOML, &TOMLParser{Flatten: opts})
+	r.Register(models.FormatDotenv, &DotenvParser{KeyMapping: dotenvKeys, Layout: opts.layout()})
+	r.Register(models.FormatProperties, &PropertiesParser{KeyMapping: propertiesKeys, Layout: opts.layout()})
 
 	return r
 }
@@ -69,17 +102,16 @@ func (r *Registry) GetParserWithDeprecationCheck(format models.FormatType) (Pars
 // Priority: extension > content signature > default (yaml)
 func (r *Registry) DetectFormat(path string) (models.FormatType, error) {
 	// 1. Extension-based detection (fastest)
-	format := r.detectByExtension(path)
-	if format != models.FormatAuto {
-		if _, err := r.GetParser(format); err == nil {
-			return format, nil
-		}
+	if format, ok := r.registeredFormat(r.detectByExtension(path)); ok {
+		return format, nil
 	}
 
 	// 2. Content-based detection (for extensionless files or unregistered parsers)
-	format = r.detectByContent(path)
-	if format != models.FormatAuto {
-		if _, err := r.GetParser(format); err == nil {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		format, _, _ := r.detectByContent(file)
+		if format, ok := r.registeredFormat(format); ok {
 			return format, nil
 		}
 	}
@@ -88,14 +120,58 @@ func (r *Registry) DetectFormat(path string) (models.FormatType, error) {
 	return models.FormatYAML, nil
 }
 
+// DetectFormatReader detects the format of a stream. name is used for
+// extension-based detection and may be empty (e.g. for stdin). The returned
+// reader replays any content consumed while sniffing and must be used in
+// place of rd.
+func (r *Registry) DetectFormatReader(name string, rd io.Reader) (models.FormatType, io.Reader, error) {
+	if name != "" {
+		if format, ok := r.registeredFormat(r.detectByExtension(name)); ok {
+			return format, rd, nil
+		}
+	}
+
+	format, replay, err := r.detectByContent(rd)
+	if err != nil {
+		return "", replay, err
+	}
+	if format, ok := r.registeredFormat(format); ok {
+		return format, replay, nil
+	}
+	return models.FormatYAML, replay, nil
+}
+
+// registeredFormat reports whether format was detected and has a parser.
+func (r *Registry) registeredFormat(format models.FormatType) (models.FormatType, bool) {
+	if format == models.FormatAuto {
+		return format, false
+	}
+	if _, err := r.GetParser(format); err != nil {
+		return format, false
+	}
+	return format, true
+}
+
 // detectByExtension returns format based on file extension
 func (r *Registry) detectByExtension(path string) models.FormatType {
+	// .env, .env.local, .env.production, ...
+	base := strings.ToLower(filepath.Base(path))
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return models.FormatDotenv
+	}
+
 	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
 	case ".yaml", ".yml":
 		return models.FormatYAML
 	case ".json":
 		return models.FormatJSON
+	case ".toml":
+		return models.FormatTOML
+	case ".env":
+		return models.FormatDotenv
+	case ".properties":
+		return models.FormatProperties
 	case ".txt":
 		return models.FormatAuto
 	default:
@@ -103,38 +179,49 @@ func (r *Registry) detectByExtension(path string) models.FormatType {
 	}
 }
 
-// detectByContent peeks at file content to detect format
-func (r *Registry) detectByContent(path string) models.FormatType {
-	file, err := os.Open(path)
-	if err != nil {
-		return models.FormatAuto
-	}
-	defer file.Close()
+// detectByContent peeks at the first meaningful line of rd to detect the
+// format. It returns a reader that yields the whole stream, including the
+// lines consumed while peeking.
+func (r *Registry) detectByContent(rd io.Reader) (models.FormatType, io.Reader, error) {
+	// Give up after 10MB of blank lines and comments
+	const maxSniffSize = 10 * 1024 * 1024 // 10MB
 
-	scanner := bufio.NewScanner(file)
-	// Increase buffer size to handle long lines (default 64K may be too small)
-	// Use 1MB initial capacity, 10MB max token size
-	const (
-		initBufSize  = 1024 * 1024      // 1MB
-		maxTokenSize = 10 * 1024 * 1024 // 10MB
-	)
-	scanner.Buffer(make([]byte, initBufSize), maxTokenSize)
+	var consumed bytes.Buffer
+	br := bufio.NewReader(rd)
 	var firstNonEmptyLine string
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" && !strings.HasPrefix(line, "#") {
-			firstNonEmptyLine = line
+	for consumed.Len() < maxSniffSize {
+		line, err := br.ReadString('\n')
+		consumed.WriteString(line)
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			firstNonEmptyLine = trimmed
 			break
 		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return models.FormatAuto, io.MultiReader(&consumed, br), err
+		}
 	}
-	if scanner.Err() != nil {
-		return models.FormatAuto
-	}
 
+	replay := io.MultiReader(&consumed, br)
+	return detectByFirstLine(firstNonEmptyLine), replay, nil
+}
+
+// detectByFirstLine guesses the format from the first line that is neither
+// blank nor a # comment.
+func detectByFirstLine(firstNonEmptyLine string) models.FormatType {
 	if firstNonEmptyLine == "" {
 		return models.FormatAuto
 	}
 
+	// TOML detection: a table header like [server] or [[servers]], which
+	// must be checked before a JSON array. One-line JSON arrays such as
+	// ["a"] or [1] also look like headers, so valid JSON is left to JSON.
+	if tomlTableHeader.MatchString(firstNonEmptyLine) && !json.Valid([]byte(firstNonEmptyLine)) {
+		return models.FormatTOML
+	}
+
 	// JSON detection: starts with { or [
 	if strings.HasPrefix(firstNonEmptyLine, "{") || strings.HasPrefix(firstNonEmptyLine, "[") {
 		return models.FormatJSON
@@ -153,5 +240,34 @@ func (r *Registry) detectByContent(path string) models.FormatType {
 		return models.FormatEtcdctl
 	}
 
+	// dotenv detection: export KEY=value
+	if dotenvExport.MatchString(firstNonEmptyLine) {
+		return models.FormatDotenv
+	}
+
+	// TOML detection: key = value where the value is a TOML literal
+	if tomlKeyValue.MatchString(firstNonEmptyLine) {
+		return models.FormatTOML
+	}
+
 	return models.FormatAuto
 }
+
+// parseFile opens path and hands it to parse, so that each parser only has
+// to implement reading from a stream. Positions are attributed to path.
+func parseFile(ctx context.Context, path string, parse func(context.Context, io.Reader) ([]*models.ConfigPair, error)) ([]*models.ConfigPair, error) {
+	// Check for cancellation before opening file
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	pairs, err := parse(ctx, file)
+	SetSource(path, pairs, err)
+	return pairs, err
+}
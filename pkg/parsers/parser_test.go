@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kazuma-desu/etu/pkg/models"
@@ -33,6 +34,18 @@ func TestNewRegistry(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, jsonParser)
 	assert.Equal(t, "json", jsonParser.FormatName())
+
+	tomlParser, err := r.GetParser(models.FormatTOML)
+	assert.NoError(t, err)
+	assert.Equal(t, "toml", tomlParser.FormatName())
+
+	dotenvParser, err := r.GetParser(models.FormatDotenv)
+	assert.NoError(t, err)
+	assert.Equal(t, "dotenv", dotenvParser.FormatName())
+
+	propertiesParser, err := r.GetParser(models.FormatProperties)
+	assert.NoError(t, err)
+	assert.Equal(t, "properties", propertiesParser.FormatName())
 }
 
 func TestRegistry_Register(t *testing.T) {
@@ -57,7 +70,7 @@ func TestRegistry_GetParser(t *testing.T) {
 	})
 
 	t.Run("unregistered parser", func(t *testing.T) {
-		_, err := r.GetParser(models.FormatType("toml"))
+		_, err := r.GetParser(models.FormatType("xml"))
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "no parser registered for format")
 	})
@@ -88,6 +101,36 @@ func TestDetectFormat_ByExtension(t *testing.T) {
 			content:  `{"key": "value"}`,
 			expected: models.FormatJSON,
 		},
+		{
+			name:     "toml extension",
+			filename: "config.toml",
+			content:  "key = \"value\"",
+			expected: models.FormatTOML,
+		},
+		{
+			name:     "dotenv file",
+			filename: ".env",
+			content:  "APP_NAME=myapp",
+			expected: models.FormatDotenv,
+		},
+		{
+			name:     "dotenv environment file",
+			filename: ".env.production",
+			content:  "PORT=8080",
+			expected: models.FormatDotenv,
+		},
+		{
+			name:     "env extension",
+			filename: "service.env",
+			content:  "APP_NAME=myapp",
+			expected: models.FormatDotenv,
+		},
+		{
+			name:     "properties extension",
+			filename: "application.properties",
+			content:  "app.name=myapp",
+			expected: models.FormatProperties,
+		},
 		{
 			name:     "txt extension",
 			filename: "config.txt",
@@ -181,6 +224,16 @@ func TestDetectFormat_ByContent(t *testing.T) {
 			content:  `[{"key": "value"}]`,
 			expected: models.FormatJSON,
 		},
+		{
+			name:     "one-line json array of strings",
+			content:  `["a"]`,
+			expected: models.FormatJSON,
+		},
+		{
+			name:     "one-line json array of numbers",
+			content:  "[1]\n",
+			expected: models.FormatJSON,
+		},
 		{
 			name:     "json with leading whitespace",
 			content:  `   {"key": "value"}`,
@@ -211,6 +264,36 @@ func TestDetectFormat_ByContent(t *testing.T) {
 			content:  "# comment\n/app/key\nvalue",
 			expected: models.FormatEtcdctl,
 		},
+		{
+			name:     "toml table header",
+			content:  "[database]\nhost = \"db\"",
+			expected: models.FormatTOML,
+		},
+		{
+			name:     "toml array of tables",
+			content:  "# servers\n[[servers]]\nname = \"a\"",
+			expected: models.FormatTOML,
+		},
+		{
+			name:     "toml dotted table header",
+			content:  "[app.\"db-main\"]  # primary\nhost = \"db\"",
+			expected: models.FormatTOML,
+		},
+		{
+			name:     "toml key-value",
+			content:  "title = \"demo\"\n[owner]\nname = \"x\"",
+			expected: models.FormatTOML,
+		},
+		{
+			name:     "toml number value",
+			content:  "port = 8080",
+			expected: models.FormatTOML,
+		},
+		{
+			name:     "dotenv export",
+			content:  "# generated\nexport APP_NAME=myapp",
+			expected: models.FormatDotenv,
+		},
 	}
 
 	for _, tt := range tests {
@@ -504,6 +587,120 @@ func TestDetectFormat_YAMLKeyValueNotStartingWithSlash(t *testing.T) {
 	}
 }
 
+func TestDetectFormatReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		expected models.FormatType
+	}{
+		{"json from content", "", "\n# comment\n{\"app\": {\"name\": \"x\"}}", models.FormatJSON},
+		{"etcdctl from content", "", "/app/name\nx\n", models.FormatEtcdctl},
+		{"extension wins over content", "app.properties", "/app/name=x\n", models.FormatProperties},
+		{"empty stream defaults to yaml", "", "", models.FormatYAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			format, replay, err := r.DetectFormatReader(tt.fileName, strings.NewReader(tt.content))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, format)
+
+			// The returned reader must yield the whole stream
+			data, err := io.ReadAll(replay)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, string(data))
+		})
+	}
+}
+
+func TestParseReader_AllFormats(t *testing.T) {
+	tests := []struct {
+		format  models.FormatType
+		content string
+		line    int
+		column  int
+	}{
+		{models.FormatEtcdctl, "/app/name\nmyapp\n", 1, 1},
+		{models.FormatYAML, "app:\n  name: myapp\n", 2, 3},
+		{models.FormatJSON, `{"app": {"name": "myapp"}}`, 1, 10},
+		{models.FormatTOML, "[app]\nname = \"myapp\"\n", 2, 1},
+		{models.FormatDotenv, "APP_NAME=myapp\n", 1, 1},
+		{models.FormatProperties, "app.name=myapp\n", 1, 1},
+	}
+
+	r := NewRegistry()
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			parser, err := r.GetParser(tt.format)
+			require.NoError(t, err)
+
+			pairs, err := parser.ParseReader(context.Background(), strings.NewReader(tt.content))
+			require.NoError(t, err)
+			require.Len(t, pairs, 1)
+			assert.Equal(t, "/app/name", pairs[0].Key)
+			assert.Equal(t, "myapp", pairs[0].Value)
+			assert.Equal(t, models.Position{Line: tt.line, Column: tt.column}, pairs[0].Pos)
+		})
+	}
+}
+
+func TestNewRegistryWithFlatten_KeyMappings(t *testing.T) {
+	r := NewRegistryWithFlatten(&FlattenOptions{
+		DotenvKeys:     &KeyMapping{Separator: "__", StripPrefix: "APP_", Lowercase: true},
+		PropertiesKeys: &KeyMapping{Separator: ".", StripPrefix: "spring."},
+	})
+
+	tests := []struct {
+		format  models.FormatType
+		content string
+		key     string
+	}{
+		{models.FormatDotenv, "APP_DB__MAX_CONNS=10\n", "/db/max_conns"},
+		{models.FormatProperties, "spring.db.host=10\n", "/db/host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			parser, err := r.GetParser(tt.format)
+			require.NoError(t, err)
+
+			pairs, err := parser.ParseReader(context.Background(), strings.NewReader(tt.content))
+			require.NoError(t, err)
+			require.Len(t, pairs, 1)
+			assert.Equal(t, tt.key, pairs[0].Key)
+		})
+	}
+
+	// Without mappings the defaults apply
+	parser, err := NewRegistryWithFlatten(nil).GetParser(models.FormatDotenv)
+	require.NoError(t, err)
+	pairs, err := parser.ParseReader(context.Background(), strings.NewReader("APP_DB__MAX_CONNS=10\n"))
+	require.NoError(t, err)
+	require.Len(t, pairs, 1)
+	assert.Equal(t, "/app/db/max/conns", pairs[0].Key)
+}
+
+func TestParseReader_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	r := NewRegistry()
+	for _, format := range []models.FormatType{
+		models.FormatEtcdctl, models.FormatYAML, models.FormatJSON,
+		models.FormatTOML, models.FormatDotenv, models.FormatProperties,
+	} {
+		t.Run(string(format), func(t *testing.T) {
+			parser, err := r.GetParser(format)
+			require.NoError(t, err)
+
+			_, err = parser.ParseReader(ctx, strings.NewReader("/app/name\nx\n"))
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+	}
+}
+
 type mockParser struct {
 	name string
 }
@@ -512,6 +709,10 @@ func (m *mockParser) Parse(_ context.Context, _ string) ([]*models.ConfigPair, e
 	return nil, nil
 }
 
+func (m *mockParser) ParseReader(_ context.Context, _ io.Reader) ([]*models.ConfigPair, error) {
+	return nil, nil
+}
+
 func (m *mockParser) FormatName() string {
 	return m.name
 }
@@ -0,0 +1,117 @@
+package parsers
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+// ParseError is a parse failure at a known position in the input
+type ParseError struct {
+	Pos models.Position
+	Msg string
+	Err error // underlying error, if any
+}
+
+func (e *ParseError) Error() string {
+	if loc := e.Pos.String(); loc != "" {
+		return loc + ": " + e.Msg
+	}
+	return e.Msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// SetSource records file as the source of pairs and of a *ParseError in
+// err, for input parsed with ParseReader.
+func SetSource(file string, pairs []*models.ConfigPair, err error) {
+	for _, pair := range pairs {
+		if pair != nil {
+			pair.Pos.File = file
+		}
+	}
+	if parseErr, ok := err.(*ParseError); ok {
+		parseErr.Pos.File = file
+	}
+}
+
+// keyPositions maps flattened keys to the position of their definition
+type keyPositions map[string]models.Position
+
+// record stores the first position seen for key
+func (kp keyPositions) record(key string, pos models.Position) {
+	if _, ok := kp[key]; !ok {
+		kp[key] = pos
+	}
+}
+
+// apply sets the position of each pair from its own key or, failing that,
+// its closest recorded ancestor (e.g. for values inside an inline table),
+// then orders the pairs as they appear in the file. Positions are recorded
+// under default layout keys; layout is the one the pairs were built with.
+func (kp keyPositions) apply(pairs []*models.ConfigPair, layout models.KeyLayout) {
+	for _, pair := range pairs {
+		key := layout.Path(pair.Key)
+		for {
+			if pos, ok := kp[key]; ok {
+				pair.Pos = pos
+				break
+			}
+			i := strings.LastIndex(key, "/")
+			if i <= 0 {
+				break
+			}
+			key = key[:i]
+		}
+	}
+	sortByPosition(pairs)
+}
+
+// sortByPosition orders pairs by line, column and then key. Pairs without a
+// position keep their relative order after the positioned ones.
+func sortByPosition(pairs []*models.ConfigPair) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		a, b := pairs[i].Pos, pairs[j].Pos
+		if a.IsValid() != b.IsValid() {
+			return a.IsValid()
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return pairs[i].Key < pairs[j].Key
+	})
+}
+
+// joinKey builds a flattened key the way FlattenMap does, including the
+// DirectoryValueKey convention.
+func joinKey(prefix, key string) string {
+	if key == DirectoryValueKey {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+	return prefix + "/" + key
+}
+
+// offsetToPosition converts a byte offset in data to a 1-based line and
+// column.
+func offsetToPosition(data []byte, offset int64) models.Position {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return models.Position{Line: line, Column: column}
+}
@@ -0,0 +1,85 @@
+package parsers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+func TestParseError(t *testing.T) {
+	cause := errors.New("boom")
+	err := &ParseError{Pos: models.Position{File: "app.yaml", Line: 3, Column: 5}, Msg: "bad value", Err: cause}
+
+	assert.Equal(t, "app.yaml:3:5: bad value", err.Error())
+	assert.ErrorIs(t, err, cause)
+
+	noPos := &ParseError{Msg: "bad value"}
+	assert.Equal(t, "bad value", noPos.Error())
+}
+
+func TestSetSource(t *testing.T) {
+	pairs := []*models.ConfigPair{{Key: "/a", Pos: models.Position{Line: 1, Column: 1}}, nil}
+	err := &ParseError{Pos: models.Position{Line: 2}, Msg: "bad"}
+
+	SetSource("<stdin>", pairs, err)
+	assert.Equal(t, "<stdin>:1:1", pairs[0].Pos.String())
+	assert.Equal(t, "<stdin>:2: bad", err.Error())
+
+	// Other errors are left alone
+	SetSource("x", nil, errors.New("plain"))
+}
+
+func TestKeyPositions_Apply(t *testing.T) {
+	positions := keyPositions{}
+	positions.record("/app", models.Position{Line: 1, Column: 1})
+	positions.record("/app/name", models.Position{Line: 2, Column: 3})
+	positions.record("/app/name", models.Position{Line: 9, Column: 9})
+	positions.record("/db", models.Position{Line: 4, Column: 1})
+
+	pairs := []*models.ConfigPair{
+		{Key: "/unknown"},
+		{Key: "/db/opts/ssl"},
+		{Key: "/app/name"},
+	}
+	positions.apply(pairs, models.KeyLayout{})
+
+	assert.Equal(t, "/app/name", pairs[0].Key)
+	assertPosition(t, 2, 3, pairs[0].Pos)
+	assert.Equal(t, "/db/opts/ssl", pairs[1].Key)
+	assertPosition(t, 4, 1, pairs[1].Pos)
+	assert.Equal(t, "/unknown", pairs[2].Key)
+	assert.False(t, pairs[2].Pos.IsValid())
+}
+
+func TestOffsetToPosition(t *testing.T) {
+	data := []byte("ab\ncde\n\nf")
+
+	assertPosition(t, 1, 1, offsetToPosition(data, 0))
+	assertPosition(t, 1, 3, offsetToPosition(data, 2))
+	assertPosition(t, 2, 1, offsetToPosition(data, 3))
+	assertPosition(t, 2, 3, offsetToPosition(data, 5))
+	assertPosition(t, 4, 1, offsetToPosition(data, 8))
+	assertPosition(t, 4, 2, offsetToPosition(data, 100))
+}
+
+// assertPosition checks line and column, ignoring the source file
+func assertPosition(t *testing.T, line, column int, pos models.Position) {
+	t.Helper()
+	assert.Equal(t, line, pos.Line, "line")
+	assert.Equal(t, column, pos.Column, "column")
+}
+
+// withoutPositions returns copies of pairs with positions cleared, for
+// tests that only check keys and values
+func withoutPositions(pairs []*models.ConfigPair) []*models.ConfigPair {
+	stripped := make([]*models.ConfigPair, len(pairs))
+	for i, pair := range pairs {
+		copied := *pair
+		copied.Pos = models.Position{}
+		stripped[i] = &copied
+	}
+	return stripped
+}
@@ -0,0 +1,194 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+// PropertiesParser parses Java .properties files
+// Format:
+//
+//	# comment
+//	! also a comment
+//	app.name = myapp
+//	app.db.host: db.example.com
+//	app.motd = first line \
+//	           continued
+//
+// Keys are converted to etcd paths with KeyMapping.
+type PropertiesParser struct {
+	// KeyMapping overrides DefaultPropertiesKeyMapping when set.
+	KeyMapping *KeyMapping
+
+	// Layout joins the mapped path segments. The zero value gives
+	// /app/db/host.
+	Layout models.KeyLayout
+}
+
+// FormatName returns the name of this format
+func (p *PropertiesParser) FormatName() string {
+	return "properties"
+}
+
+// Parse reads a properties file following java.util.Properties rules: a key
+// ends at the first unescaped '=', ':' or whitespace, a trailing backslash
+// continues the line, and \t, \n, \r, \f and \uXXXX escapes are decoded.
+// When a key is assigned more than once the last value wins.
+func (p *PropertiesParser) Parse(ctx context.Context, path string) ([]*models.ConfigPair, error) {
+	return parseFile(ctx, path, p.ParseReader)
+}
+
+// ParseReader parses properties content from a stream
+func (p *PropertiesParser) ParseReader(ctx context.Context, r io.Reader) ([]*models.ConfigPair, error) {
+	mapping := DefaultPropertiesKeyMapping
+	if p.KeyMapping != nil {
+		mapping = *p.KeyMapping
+	}
+
+	var result mappedPairs
+	lines := newLineReader(r)
+	for lines.next() {
+		// Check for cancellation
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		line := strings.TrimLeft(lines.text, " \t\f")
+		pos := models.Position{Line: lines.num, Column: len(lines.text) - len(line) + 1}
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// Join continuation lines, dropping their leading whitespace
+		for endsWithContinuation(line) {
+			line = line[:len(line)-1]
+			if !lines.next() {
+				break
+			}
+			line += strings.TrimLeft(lines.text, " \t\f")
+		}
+
+		rawKey, rawValue := splitProperty(line)
+		name, err := unescapeProperty(rawKey)
+		if err != nil {
+			return nil, lineError(pos, "%v", err)
+		}
+		value, err := unescapeProperty(rawValue)
+		if err != nil {
+			return nil, lineError(pos, "%v", err)
+		}
+
+		key := mapping.MapKey(name)
+		if key == "" {
+			return nil, lineError(pos, "property %q maps to an empty key", name)
+		}
+		result.set(p.Layout.FromPath(key), value, pos)
+	}
+
+	if err := lines.err(); err != nil {
+		return nil, err
+	}
+
+	return result.pairs, nil
+}
+
+// endsWithContinuation reports whether line ends with an odd number of
+// backslashes, i.e. an unescaped line continuation.
+func endsWithContinuation(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// splitProperty splits a logical line into its raw key and value. The key
+// ends at the first unescaped '=', ':' or whitespace; whitespace and at most
+// one '=' or ':' separate it from the value.
+func splitProperty(line string) (key, value string) {
+	end := len(line)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			end = i
+			break
+		}
+	}
+
+	key = line[:end]
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	return key, rest
+}
+
+// unescapeProperty decodes properties escapes. A backslash before any other
+// character yields that character.
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 >= len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, ok := parseUnicodeEscape(s, i+1)
+			if !ok {
+				return "", fmt.Errorf("malformed \\u escape in %q", s)
+			}
+			i += 4
+			// Surrogate pairs are written as two consecutive escapes
+			if utf16.IsSurrogate(r) {
+				if low, ok := parseUnicodeEscape(s, i+3); ok && strings.HasPrefix(s[i+1:], "\\u") {
+					if combined := utf16.DecodeRune(r, low); combined != unicode.ReplacementChar {
+						r = combined
+						i += 6
+					}
+				}
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// parseUnicodeEscape decodes the four hex digits starting at s[start].
+func parseUnicodeEscape(s string, start int) (rune, bool) {
+	if start+4 > len(s) {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(s[start:start+4], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(code), true
+}
@@ -0,0 +1,170 @@
+package parsers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+func TestPropertiesParser_FormatName(t *testing.T) {
+	parser := &PropertiesParser{}
+	assert.Equal(t, "properties", parser.FormatName())
+}
+
+func TestPropertiesParser_Parse(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []*models.ConfigPair
+	}{
+		{
+			name:    "separators",
+			content: "app.name=myapp\napp.db.host: db.example.com\napp.db.port 5432\napp.env = prod\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/name", Value: "myapp"},
+				{Key: "/app/db/host", Value: "db.example.com"},
+				{Key: "/app/db/port", Value: "5432"},
+				{Key: "/app/env", Value: "prod"},
+			},
+		},
+		{
+			name:    "comments and blank lines",
+			content: "# comment\n! bang comment\n\n   \napp.name=myapp # not a comment\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/name", Value: "myapp # not a comment"},
+			},
+		},
+		{
+			name:    "line continuation",
+			content: "app.hosts = a, \\\n           b, \\\n           c\napp.name=x\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/hosts", Value: "a, b, c"},
+				{Key: "/app/name", Value: "x"},
+			},
+		},
+		{
+			name:    "escaped backslash is not a continuation",
+			content: "app.path=C:\\\\temp\\\\\napp.name=x\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/path", Value: `C:\temp\`},
+				{Key: "/app/name", Value: "x"},
+			},
+		},
+		{
+			name:    "escapes",
+			content: "app.motd=line1\\nline2\\ttab\napp.greeting=caf\\u00e9 \\uD83D\\uDE00\napp.ratio=100\\%\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/motd", Value: "line1\nline2\ttab"},
+				{Key: "/app/greeting", Value: "café 😀"},
+				{Key: "/app/ratio", Value: "100%"},
+			},
+		},
+		{
+			name:    "escaped separators in key",
+			content: "app.a\\=b\\:c\\ d = value\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/a=b:c d", Value: "value"},
+			},
+		},
+		{
+			name:    "only the first separator is consumed",
+			content: "app.url = = http://x:80\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/url", Value: "= http://x:80"},
+			},
+		},
+		{
+			name:    "key without value",
+			content: "app.flag\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/flag", Value: ""},
+			},
+		},
+		{
+			name:    "last assignment wins",
+			content: "app.name=first\napp.env=dev\napp.name=second\n",
+			expected: []*models.ConfigPair{
+				{Key: "/app/name", Value: "second"},
+				{Key: "/app/env", Value: "dev"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, err := parseProperties(t, &PropertiesParser{}, tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, withoutPositions(pairs))
+		})
+	}
+}
+
+func TestPropertiesParser_Errors(t *testing.T) {
+	t.Run("malformed unicode escape", func(t *testing.T) {
+		_, err := parseProperties(t, &PropertiesParser{}, "app.name=ok\napp.bad=\\u12\n")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "2:1: malformed \\u escape")
+	})
+
+	t.Run("key maps to empty path", func(t *testing.T) {
+		_, err := parseProperties(t, &PropertiesParser{}, "...=x\n")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `1:1: property "..." maps to an empty key`)
+	})
+}
+
+func TestPropertiesParser_CustomKeyMapping(t *testing.T) {
+	parser := &PropertiesParser{KeyMapping: &KeyMapping{
+		Separator:   ".",
+		StripPrefix: "spring.",
+		Lowercase:   true,
+	}}
+
+	pairs, err := parseProperties(t, parser, "spring.DataSource.URL=jdbc:postgresql://db/app\n")
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "/datasource/url", Value: "jdbc:postgresql://db/app"},
+	}, withoutPositions(pairs))
+}
+
+func TestPropertiesParser_Positions(t *testing.T) {
+	content := "# header\napp.name=myapp\n    app.hosts = a, \\\n        b\napp.env=prod\n"
+
+	pairs, err := parseProperties(t, &PropertiesParser{}, content)
+	require.NoError(t, err)
+	require.Len(t, pairs, 3)
+	assertPosition(t, 2, 1, pairs[0].Pos)
+	assertPosition(t, 3, 5, pairs[1].Pos)
+	assertPosition(t, 5, 1, pairs[2].Pos)
+}
+
+func TestPropertiesParser_FileNotFound(t *testing.T) {
+	parser := &PropertiesParser{}
+	_, err := parser.Parse(context.Background(), "/nonexistent/app.properties")
+	assert.Error(t, err)
+}
+
+func TestPropertiesParser_ContextCanceled(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "app.properties")
+	require.NoError(t, os.WriteFile(tmpFile, []byte("app.name=myapp\n"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	parser := &PropertiesParser{}
+	_, err := parser.Parse(ctx, tmpFile)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func parseProperties(t *testing.T, parser *PropertiesParser, content string) ([]*models.ConfigPair, error) {
+	t.Helper()
+	tmpFile := filepath.Join(t.TempDir(), "app.properties")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0644))
+	return parser.Parse(context.Background(), tmpFile)
+}
@@ -0,0 +1,190 @@
+package parsers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+// KeyRules rewrite the keys of parsed pairs so that one file can be deployed
+// to several locations. Rules run in order, each one seeing the key produced
+// by the rules before it. A rules file looks like:
+//
+//	rules:
+//	  - strip: /dev
+//	  - replace: /app
+//	    with: /services/api
+//	  - match: '^/(\w+)/password$'
+//	    to: /secrets/$1/password
+type KeyRules struct {
+	Rules []KeyRule `yaml:"rules"`
+}
+
+// KeyRule is a single rewrite. Exactly one of Strip, Replace and Match is set.
+// Strip and Replace only match whole key segments, so /dev matches /dev/app
+// but not /devices.
+type KeyRule struct {
+	// Strip removes a leading key prefix
+	Strip string `yaml:"strip,omitempty"`
+
+	// Replace swaps a leading key prefix for With
+	Replace string `yaml:"replace,omitempty"`
+	With    string `yaml:"with,omitempty"`
+
+	// Match is a regular expression; keys it matches are rewritten to To,
+	// which may refer to submatches as $1 or ${name}
+	Match string `yaml:"match,omitempty"`
+	To    string `yaml:"to,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// LoadKeyRules reads a rules file
+func LoadKeyRules(path string) (*KeyRules, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rules, err := ParseKeyRules(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseKeyRules reads rules from a YAML stream. Unknown fields are rejected.
+func ParseKeyRules(r io.Reader) (*KeyRules, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var rules KeyRules
+	if err := decoder.Decode(&rules); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	for i := range rules.Rules {
+		if err := rules.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return &rules, nil
+}
+
+func (r *KeyRule) compile() error {
+	set := 0
+	for _, field := range []string{r.Strip, r.Replace, r.Match} {
+		if field != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("exactly one of strip, replace or match is required")
+	}
+
+	switch {
+	case r.Strip != "":
+		if !strings.HasPrefix(r.Strip, "/") {
+			return fmt.Errorf("strip prefix must start with '/': %s", r.Strip)
+		}
+	case r.Replace != "":
+		if !strings.HasPrefix(r.Replace, "/") {
+			return fmt.Errorf("replace prefix must start with '/': %s", r.Replace)
+		}
+	case r.Match != "":
+		re, err := regexp.Compile(r.Match)
+		if err != nil {
+			return fmt.Errorf("invalid match pattern: %w", err)
+		}
+		r.re = re
+	}
+	return nil
+}
+
+// rewrite applies the rule to key, returning key unchanged when the rule
+// does not match.
+func (r *KeyRule) rewrite(key string) string {
+	switch {
+	case r.Strip != "":
+		if rest, ok := cutKeyPrefix(key, r.Strip); ok {
+			return rest
+		}
+	case r.Replace != "":
+		if rest, ok := cutKeyPrefix(key, r.Replace); ok {
+			return strings.TrimRight(r.With, "/") + rest
+		}
+	case r.re != nil:
+		if r.re.MatchString(key) {
+			return r.re.ReplaceAllString(key, r.To)
+		}
+	}
+	return key
+}
+
+// MapKey runs key through every rule. The result always starts with '/';
+// it is "" when the rules remove the whole key.
+func (r *KeyRules) MapKey(key string) string {
+	for i := range r.Rules {
+		key = r.Rules[i].rewrite(key)
+	}
+	if key == "" || key == "/" {
+		return ""
+	}
+	if !strings.HasPrefix(key, "/") {
+		key = "/" + key
+	}
+	return key
+}
+
+// Apply rewrites the key of every pair in place
+func (r *KeyRules) Apply(pairs []*models.ConfigPair) error {
+	for _, pair := range pairs {
+		key := r.MapKey(pair.Key)
+		if key == "" {
+			return &ParseError{Pos: pair.Pos, Msg: fmt.Sprintf("key map rewrites %s to an empty key", pair.Key)}
+		}
+		pair.Key = key
+	}
+	return nil
+}
+
+// MountPrefix moves every pair under prefix, so /db/host mounted at
+// /services/api becomes /services/api/db/host. Both are keys in layout,
+// so with a "." separator db.host mounted at services.api becomes
+// services.api.db.host. An empty prefix or the bare root leaves the keys
+// unchanged.
+func MountPrefix(pairs []*models.ConfigPair, prefix string, layout models.KeyLayout) {
+	r := layout.Resolved()
+	for strings.HasSuffix(prefix, r.Separator) {
+		prefix = strings.TrimSuffix(prefix, r.Separator)
+	}
+	if prefix == "" || prefix+r.Separator == r.Root {
+		return
+	}
+	for _, pair := range pairs {
+		pair.Key = prefix + r.Separator + strings.TrimPrefix(pair.Key, r.Root)
+	}
+}
+
+// cutKeyPrefix removes prefix from key when it covers whole segments of it
+func cutKeyPrefix(key, prefix string) (string, bool) {
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix == "" {
+		return key, true
+	}
+	if key == prefix {
+		return "", true
+	}
+	if rest, ok := strings.CutPrefix(key, prefix); ok && strings.HasPrefix(rest, "/") {
+		return rest, true
+	}
+	return "", false
+}
@@ -0,0 +1,141 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+func TestKeyRules_MapKey(t *testing.T) {
+	rules, err := ParseKeyRules(strings.NewReader(`rules:
+  - strip: /dev
+  - replace: /app/
+    with: /services/api/
+  - match: '^/(\w+)/password$'
+    to: /secrets/$1/password
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"/dev/app/name", "/services/api/name"},
+		{"/app/name", "/services/api/name"},
+		{"/application/name", "/application/name"},
+		{"/devices/x", "/devices/x"},
+		{"/dev/db/password", "/secrets/db/password"},
+		{"/dev", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rules.MapKey(tt.key))
+		})
+	}
+}
+
+func TestKeyRules_MapKey_AddsLeadingSlash(t *testing.T) {
+	rules, err := ParseKeyRules(strings.NewReader(`rules:
+  - match: '^/legacy/'
+    to: ''
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "/app/name", rules.MapKey("/legacy/app/name"))
+}
+
+func TestParseKeyRules_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{"no action", "rules:\n  - with: /x\n", "rule 1: exactly one of strip, replace or match is required"},
+		{"two actions", "rules:\n  - strip: /a\n    replace: /b\n", "rule 1: exactly one of"},
+		{"relative strip", "rules:\n  - strip: /ok\n  - strip: app\n", "rule 2: strip prefix must start with '/'"},
+		{"relative replace", "rules:\n  - replace: app\n    with: /b\n", "replace prefix must start with '/'"},
+		{"bad regex", "rules:\n  - match: '('\n    to: /x\n", "invalid match pattern"},
+		{"unknown field", "rules:\n  - strip: /a\n    prefix: /b\n", "field prefix not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKeyRules(strings.NewReader(tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestParseKeyRules_Empty(t *testing.T) {
+	rules, err := ParseKeyRules(strings.NewReader(""))
+	require.NoError(t, err)
+	assert.Empty(t, rules.Rules)
+	assert.Equal(t, "/app", rules.MapKey("/app"))
+}
+
+func TestKeyRules_Apply(t *testing.T) {
+	rules, err := ParseKeyRules(strings.NewReader("rules:\n  - strip: /app\n"))
+	require.NoError(t, err)
+
+	pairs := []*models.ConfigPair{{Key: "/app/name", Value: "x"}}
+	require.NoError(t, rules.Apply(pairs))
+	assert.Equal(t, "/name", pairs[0].Key)
+
+	pos := models.Position{File: "app.yaml", Line: 3, Column: 1}
+	err = rules.Apply([]*models.ConfigPair{{Key: "/app", Value: "x", Pos: pos}})
+	require.Error(t, err)
+	assert.Equal(t, "app.yaml:3:1: key map rewrites /app to an empty key", err.Error())
+}
+
+func TestLoadKeyRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - strip: /dev\n"), 0644))
+
+	rules, err := LoadKeyRules(path)
+	require.NoError(t, err)
+	assert.Equal(t, "/app", rules.MapKey("/dev/app"))
+
+	require.NoError(t, os.WriteFile(path, []byte("rules:\n  - strip: dev\n"), 0644))
+	_, err = LoadKeyRules(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path+": rule 1")
+
+	_, err = LoadKeyRules(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestMountPrefix(t *testing.T) {
+	dots := models.KeyLayout{Separator: "."}
+	colons := models.KeyLayout{Separator: ":", Root: "config:"}
+
+	tests := []struct {
+		name     string
+		layout   models.KeyLayout
+		key      string
+		prefix   string
+		expected string
+	}{
+		{"prefix", models.KeyLayout{}, "/db/host", "/services/api", "/services/api/db/host"},
+		{"trailing separator", models.KeyLayout{}, "/db/host", "/services/api/", "/services/api/db/host"},
+		{"root", models.KeyLayout{}, "/db/host", "/", "/db/host"},
+		{"empty", models.KeyLayout{}, "/db/host", "", "/db/host"},
+		{"dots", dots, "db.host", "services.api.", "services.api.db.host"},
+		{"colons with root", colons, "config:db:host", "config:services", "config:services:db:host"},
+		{"bare custom root", colons, "config:db:host", "config:", "config:db:host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs := []*models.ConfigPair{{Key: tt.key, Value: "localhost"}}
+			MountPrefix(pairs, tt.prefix, tt.layout)
+			assert.Equal(t, tt.expected, pairs[0].Key)
+		})
+	}
+}
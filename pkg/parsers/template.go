@@ -0,0 +1,144 @@
+package parsers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kazuma-desu/etu/pkg/logger"
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+var variableNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// IsValidVariableName reports whether name can be referenced as ${name}
+func IsValidVariableName(name string) bool {
+	return variableNameRE.MatchString(name)
+}
+
+// Template expands variable references in a configuration file before it
+// is parsed:
+//
+//	${NAME}             the value of NAME
+//	${NAME:-default}    default when NAME is unset or empty
+//	$${NAME}            a literal ${NAME}
+//
+// Vars are looked up first, then the environment.
+type Template struct {
+	// Vars are explicitly set variables, such as those of values files
+	Vars map[string]string
+
+	// LookupEnv reads environment variables. Nil means os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+
+	// Strict fails on references to undefined variables that have no
+	// default. Otherwise they expand to an empty string with a warning.
+	Strict bool
+}
+
+// Expand returns data with every variable reference replaced. Errors are
+// ParseErrors pointing at the reference.
+func (t *Template) Expand(data []byte) ([]byte, error) {
+	var out bytes.Buffer
+	out.Grow(len(data))
+
+	line, lineStart := 1, 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c == '\n' {
+			line, lineStart = line+1, i+1
+		}
+		if c != '$' || i+1 >= len(data) {
+			out.WriteByte(c)
+			continue
+		}
+
+		// $${ escapes a reference
+		if data[i+1] == '$' && i+2 < len(data) && data[i+2] == '{' {
+			out.WriteString("${")
+			i += 2
+			continue
+		}
+		if data[i+1] != '{' {
+			out.WriteByte(c)
+			continue
+		}
+
+		pos := models.Position{Line: line, Column: i - lineStart + 1}
+		end := bytes.IndexAny(data[i+2:], "}\n")
+		if end < 0 || data[i+2+end] != '}' {
+			return nil, lineError(pos, "unterminated variable reference")
+		}
+
+		value, err := t.resolve(string(data[i+2:i+2+end]), pos)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString(value)
+		i += 2 + end
+	}
+
+	return out.Bytes(), nil
+}
+
+// resolve returns the value of the reference expr, the text between the
+// braces of ${expr}
+func (t *Template) resolve(expr string, pos models.Position) (string, error) {
+	name, def, hasDefault := strings.Cut(expr, ":-")
+	if !IsValidVariableName(name) {
+		return "", lineError(pos, "invalid variable name %q", name)
+	}
+
+	value, ok := t.lookup(name)
+	if hasDefault && value == "" {
+		return def, nil
+	}
+	if !ok {
+		if t.Strict {
+			return "", lineError(pos, "undefined variable %s", name)
+		}
+		logger.Log.Warn("undefined variable expands to an empty string", "name", name, "position", pos.String())
+	}
+	return value, nil
+}
+
+func (t *Template) lookup(name string) (string, bool) {
+	if value, ok := t.Vars[name]; ok {
+		return value, true
+	}
+	lookupEnv := t.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	return lookupEnv(name)
+}
+
+// LoadTemplateValues reads variables from a YAML values file. Nested maps
+// are joined with dots, so db: {host: x} defines db.host; arrays are
+// stored as JSON like FlattenMap does.
+func LoadTemplateValues(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		err = yamlParseError(err)
+		if _, ok := err.(*ParseError); !ok {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		SetSource(path, nil, err)
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	for _, pair := range FlattenMapWithOptions(raw, &FlattenOptions{Layout: models.KeyLayout{Separator: "."}}) {
+		values[pair.Key] = pair.Value
+	}
+	return values, nil
+}
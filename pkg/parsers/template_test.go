@@ -0,0 +1,114 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubEnv(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestTemplate_Expand(t *testing.T) {
+	tmpl := &Template{
+		Vars:      map[string]string{"db.host": "db.internal", "PORT": "6543"},
+		LookupEnv: stubEnv(map[string]string{"PORT": "5432", "ENV": "prod", "EMPTY": ""}),
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"environment", "env: ${ENV}", "env: prod"},
+		{"vars override environment", "port: ${PORT}", "port: 6543"},
+		{"dotted name", "host: ${db.host}", "host: db.internal"},
+		{"default when unset", "level: ${LEVEL:-info}", "level: info"},
+		{"default when empty", "x: ${EMPTY:-fallback}", "x: fallback"},
+		{"default ignored when set", "env: ${ENV:-dev}", "env: prod"},
+		{"empty default", "x: '${LEVEL:-}'", "x: ''"},
+		{"several references", "url: ${ENV}-${PORT}", "url: prod-6543"},
+		{"escape", "literal: $${ENV}", "literal: ${ENV}"},
+		{"lone dollar", "price: $5 and $", "price: $5 and $"},
+		{"no references", "a: b\n", "a: b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tmpl.Expand([]byte(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(out))
+		})
+	}
+}
+
+func TestTemplate_ExpandUndefined(t *testing.T) {
+	tmpl := &Template{LookupEnv: stubEnv(nil)}
+	out, err := tmpl.Expand([]byte("host: ${HOST}"))
+	require.NoError(t, err)
+	assert.Equal(t, "host: ", string(out))
+
+	tmpl.Strict = true
+	_, err = tmpl.Expand([]byte("app:\n  host: ${HOST}"))
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.EqualError(t, err, "2:9: undefined variable HOST")
+
+	out, err = tmpl.Expand([]byte("host: ${HOST:-localhost}"))
+	require.NoError(t, err)
+	assert.Equal(t, "host: localhost", string(out))
+}
+
+func TestTemplate_ExpandErrors(t *testing.T) {
+	tmpl := &Template{LookupEnv: stubEnv(nil)}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"unterminated at end", "a: ${HOST", "1:4: unterminated variable reference"},
+		{"unterminated at newline", "a: b\nc: ${HOST\n}", "2:4: unterminated variable reference"},
+		{"invalid name", "a: ${1HOST}", `1:4: invalid variable name "1HOST"`},
+		{"empty name", "a: ${}", `1:4: invalid variable name ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tmpl.Expand([]byte(tt.input))
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestLoadTemplateValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("env: prod\nreplicas: 3\ndb:\n  host: db.internal\n  ports: [1, 2]\n"), 0o600))
+
+	values, err := LoadTemplateValues(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"env":      "prod",
+		"replicas": "3",
+		"db.host":  "db.internal",
+		"db.ports": "[1,2]",
+	}, values)
+}
+
+func TestLoadTemplateValues_Errors(t *testing.T) {
+	_, err := LoadTemplateValues(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("a: b\n  c: : d\n"), 0o600))
+	_, err = LoadTemplateValues(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path+":")
+}
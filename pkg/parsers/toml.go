@@ -0,0 +1,291 @@
+package parsers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+type TOMLParser struct {
+	// Flatten controls how arrays and nulls are flattened and how keys are
+	// joined. Nil keeps the FlattenMap defaults.
+	Flatten *FlattenOptions
+}
+
+func (p *TOMLParser) FormatName() string {
+	return "toml"
+}
+
+// Parse reads a TOML document. Tables become path segments like YAML maps,
+// arrays (including arrays of tables) are stored as JSON, and datetimes are
+// stored in their TOML/RFC 3339 form.
+func (p *TOMLParser) Parse(ctx context.Context, path string) ([]*models.ConfigPair, error) {
+	return parseFile(ctx, path, p.ParseReader)
+}
+
+func (p *TOMLParser) ParseReader(ctx context.Context, r io.Reader) ([]*models.ConfigPair, error) {
+	// Check for cancellation before reading
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	// Check for cancellation before decoding
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var root map[string]any
+	if _, err := toml.Decode(string(data), &root); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &ParseError{
+				Pos: models.Position{Line: parseErr.Position.Line, Column: parseErr.Position.Col},
+				Msg: "failed to parse TOML: " + parseErr.Message,
+				Err: err,
+			}
+		}
+		return nil, fmt.Errorf("failed to parse TOML: %w", err)
+	}
+
+	normalized, _ := normalizeTOML(root).(map[string]any)
+	pairs := FlattenMapWithOptions(normalized, p.Flatten)
+	positions := keyPositions{}
+	recordTOMLPositions(positions, string(data))
+	positions.apply(pairs, p.Flatten.layout())
+	return pairs, nil
+}
+
+// normalizeTOML converts decoded TOML values to the types FlattenMap and
+// JSON serialization understand: arrays of tables become []any and
+// datetimes become strings.
+func normalizeTOML(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeTOML(item)
+		}
+		return v
+	case []map[string]any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = normalizeTOML(item)
+		}
+		return items
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeTOML(item)
+		}
+		return v
+	case time.Time:
+		return formatTOMLTime(v)
+	default:
+		return v
+	}
+}
+
+// formatTOMLTime formats a decoded datetime the way it was written. The
+// decoder marks local dates, times and datetimes with named zones; offset
+// datetimes use RFC 3339.
+func formatTOMLTime(t time.Time) string {
+	switch t.Location().String() {
+	case "datetime-local":
+		return t.Format("2006-01-02T15:04:05.999999999")
+	case "date-local":
+		return t.Format(time.DateOnly)
+	case "time-local":
+		return t.Format("15:04:05.999999999")
+	default:
+		return t.Format(time.RFC3339Nano)
+	}
+}
+
+// recordTOMLPositions records where tables, arrays of tables and keys are
+// defined. The document has already been decoded, so this only needs to
+// find key positions, not validate syntax. Keys inside arrays of tables and
+// inline tables take the position of the enclosing array or key.
+func recordTOMLPositions(positions keyPositions, data string) {
+	var (
+		scan        tomlScanState
+		table       string
+		inArray     bool
+		arrayTables []string
+	)
+
+	for i, line := range strings.Split(data, "\n") {
+		if scan.open() {
+			scan.scan(line)
+			continue
+		}
+
+		trimmed := strings.TrimLeft(line, " \t")
+		pos := models.Position{Line: i + 1, Column: len(line) - len(trimmed) + 1}
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+
+		if trimmed[0] == '[' {
+			isArray := strings.HasPrefix(trimmed, "[[")
+			header := strings.TrimLeft(trimmed, "[")
+			end := strings.Index(header, "]")
+			if end < 0 {
+				continue
+			}
+			table = "/" + strings.Join(splitTOMLKey(header[:end]), "/")
+			inArray = isArray || withinAny(table, arrayTables)
+			if isArray {
+				if !withinAny(table, arrayTables) {
+					positions.record(table, pos)
+				}
+				arrayTables = append(arrayTables, table)
+			} else if !inArray {
+				positions.record(table, pos)
+			}
+			continue
+		}
+
+		eq := tomlKeyEnd(trimmed)
+		if eq < 0 {
+			continue
+		}
+		if !inArray {
+			key := strings.TrimSuffix(table, "/") + "/" + strings.Join(splitTOMLKey(trimmed[:eq]), "/")
+			positions.record(key, pos)
+		}
+		scan.scan(trimmed[eq+1:])
+	}
+}
+
+// withinAny reports whether key is one of prefixes or nested below one
+func withinAny(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if key == prefix || strings.HasPrefix(key, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// tomlKeyEnd returns the index of the '=' that ends the key, skipping quoted
+// key segments, or -1.
+func tomlKeyEnd(line string) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// splitTOMLKey splits a dotted key into unquoted segments
+func splitTOMLKey(key string) []string {
+	var segments []string
+	var current strings.Builder
+	var quote byte
+	flush := func() {
+		segment := strings.TrimSpace(current.String())
+		if unquoted, err := strconv.Unquote(segment); err == nil && strings.HasPrefix(segment, `"`) {
+			segment = unquoted
+		} else if len(segment) >= 2 && segment[0] == '\'' && segment[len(segment)-1] == '\'' {
+			segment = segment[1 : len(segment)-1]
+		}
+		segments = append(segments, segment)
+		current.Reset()
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' && i+1 < len(key) {
+				current.WriteByte(c)
+				i++
+				c = key[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			flush()
+			continue
+		}
+		current.WriteByte(c)
+	}
+	flush()
+	return segments
+}
+
+// tomlScanState tracks multi-line strings and arrays or inline tables that
+// continue past the end of a line.
+type tomlScanState struct {
+	multiline string // """ or ''' while inside a multi-line string
+	depth     int    // open [ and { brackets
+}
+
+func (s *tomlScanState) open() bool {
+	return s.multiline != "" || s.depth > 0
+}
+
+// scan advances the state over one line of value text
+func (s *tomlScanState) scan(text string) {
+	for i := 0; i < len(text); i++ {
+		if s.multiline != "" {
+			if strings.HasPrefix(text[i:], s.multiline) {
+				i += len(s.multiline) - 1
+				s.multiline = ""
+			} else if text[i] == '\\' && s.multiline == `"""` {
+				i++
+			}
+			continue
+		}
+
+		switch c := text[i]; c {
+		case '#':
+			return
+		case '[', '{':
+			s.depth++
+		case ']', '}':
+			s.depth--
+		case '"', '\'':
+			delim := text[i : i+1]
+			if strings.HasPrefix(text[i:], strings.Repeat(delim, 3)) {
+				s.multiline = strings.Repeat(delim, 3)
+				i += 2
+				continue
+			}
+			// Single-line string: skip to the closing quote
+			for i++; i < len(text) && text[i] != c; i++ {
+				if c == '"' && text[i] == '\\' {
+					i++
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package parsers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+func TestTOMLParser_FormatName(t *testing.T) {
+	parser := &TOMLParser{}
+	assert.Equal(t, "toml", parser.FormatName())
+}
+
+func TestTOMLParser_Tables(t *testing.T) {
+	content := `title = "demo"
+
+[database]
+host = "localhost"
+port = 5432
+ratio = 0.75
+enabled = true
+
+[database.replica]
+host = "replica"
+
+[app."db-main"]
+pool = 10
+`
+
+	pairs := parseTOML(t, content)
+
+	assert.Len(t, pairs, 7)
+	assertJSONPair(t, pairs, "/title", "demo")
+	assertJSONPair(t, pairs, "/database/host", "localhost")
+	assertJSONPair(t, pairs, "/database/port", "5432")
+	assertJSONPair(t, pairs, "/database/ratio", "0.75")
+	assertJSONPair(t, pairs, "/database/enabled", "true")
+	assertJSONPair(t, pairs, "/database/replica/host", "replica")
+	assertJSONPair(t, pairs, "/app/db-main/pool", "10")
+}
+
+func TestTOMLParser_InlineTablesAndDottedKeys(t *testing.T) {
+	content := `server = { host = "web", port = 80 }
+log.level = "debug"
+`
+
+	pairs := parseTOML(t, content)
+
+	assert.Len(t, pairs, 3)
+	assertJSONPair(t, pairs, "/server/host", "web")
+	assertJSONPair(t, pairs, "/server/port", "80")
+	assertJSONPair(t, pairs, "/log/level", "debug")
+}
+
+func TestTOMLParser_Arrays(t *testing.T) {
+	content := `tags = ["a", "b"]
+ports = [80, 443]
+empty = []
+
+[[servers]]
+name = "alpha"
+since = 2024-01-02
+
+[[servers]]
+name = "beta"
+`
+
+	pairs := parseTOML(t, content)
+
+	assert.Len(t, pairs, 3, "empty arrays are skipped like in YAML and JSON")
+	assertJSONPair(t, pairs, "/tags", `["a","b"]`)
+	assertJSONPair(t, pairs, "/ports", `[80,443]`)
+	assertJSONPair(t, pairs, "/servers", `[{"name":"alpha","since":"2024-01-02"},{"name":"beta"}]`)
+}
+
+func TestTOMLParser_Datetimes(t *testing.T) {
+	content := `offset = 1979-05-27T07:32:00-08:00
+utc = 1979-05-27T07:32:00Z
+local = 1979-05-27T07:32:00.5
+date = 1979-05-27
+time = 07:32:00
+`
+
+	pairs := parseTOML(t, content)
+
+	assert.Len(t, pairs, 5)
+	assertJSONPair(t, pairs, "/offset", "1979-05-27T07:32:00-08:00")
+	assertJSONPair(t, pairs, "/utc", "1979-05-27T07:32:00Z")
+	assertJSONPair(t, pairs, "/local", "1979-05-27T07:32:00.5")
+	assertJSONPair(t, pairs, "/date", "1979-05-27")
+	assertJSONPair(t, pairs, "/time", "07:32:00")
+}
+
+func TestTOMLParser_EmptyFile(t *testing.T) {
+	pairs := parseTOML(t, "")
+	assert.Empty(t, pairs)
+}
+
+func TestTOMLParser_InvalidTOML(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "bad.toml")
+	require.NoError(t, os.WriteFile(tmpFile, []byte("key = \n[broken"), 0644))
+
+	parser := &TOMLParser{}
+	_, err := parser.Parse(context.Background(), tmpFile)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse TOML")
+}
+
+func TestTOMLParser_FileNotFound(t *testing.T) {
+	parser := &TOMLParser{}
+	_, err := parser.Parse(context.Background(), "/nonexistent/config.toml")
+	assert.Error(t, err)
+}
+
+func TestTOMLParser_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	parser := &TOMLParser{}
+	_, err := parser.Parse(ctx, "/any.toml")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTOMLParser_Positions(t *testing.T) {
+	content := `title = "demo"
+
+[database]
+  host = "localhost"
+  motd = """
+fake = "not a key"
+"""
+  opts = { ssl = true }
+  ports = [
+    80,
+  ]
+
+[app."db-main"]
+pool = 10
+
+[[servers]]
+name = "a"
+
+[[servers]]
+name = "b"
+`
+	pairs := parseTOML(t, content)
+
+	require.Len(t, pairs, 7)
+	expected := []struct {
+		key    string
+		line   int
+		column int
+	}{
+		{"/title", 1, 1},
+		{"/database/host", 4, 3},
+		{"/database/motd", 5, 3},
+		{"/database/opts/ssl", 8, 3},
+		{"/database/ports", 9, 3},
+		{"/app/db-main/pool", 14, 1},
+		{"/servers", 16, 1},
+	}
+	for i, want := range expected {
+		assert.Equal(t, want.key, pairs[i].Key)
+		assertPosition(t, want.line, want.column, pairs[i].Pos)
+	}
+}
+
+func TestTOMLParser_ErrorPosition(t *testing.T) {
+	_, err := (&TOMLParser{}).ParseReader(context.Background(), strings.NewReader("a = 1\nb = \n"))
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 2, parseErr.Pos.Line)
+	assert.Positive(t, parseErr.Pos.Column)
+}
+
+func parseTOML(t *testing.T, content string) []*models.ConfigPair {
+	t.Helper()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.toml")
+	err := os.WriteFile(tmpFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	parser := &TOMLParser{}
+	pairs, err := parser.Parse(context.Background(), tmpFile)
+	require.NoError(t, err)
+
+	return pairs
+}
@@ -0,0 +1,187 @@
+package parsers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+// TypesPrefix is the etcd prefix under which value types are recorded, one
+// sidecar key per typed key: the type of /app/port is stored at
+// .etu/types/app/port, and that of app.port at .etu/types/app.port. It has no
+// leading slash so that listings, backups and diffs of "/" do not see it.
+const TypesPrefix = ".etu/types"
+
+// TypeKey returns the sidecar key that records the type of key
+func TypeKey(key string) string {
+	if !strings.HasPrefix(key, "/") {
+		return TypesPrefix + "/" + key
+	}
+	return TypesPrefix + key
+}
+
+// IsTypeKey reports whether key is a type sidecar key
+func IsTypeKey(key string) bool {
+	return strings.HasPrefix(key, TypesPrefix+"/")
+}
+
+// TypeSidecars returns the sidecar pairs recording the types of pairs.
+// Pairs without a type are skipped. Each sidecar holds the type and a
+// fingerprint of the value it describes, such as int:1a2b3c4d5e6f7a8b, so
+// that it no longer applies once the key is written with another value.
+func TypeSidecars(pairs []*models.ConfigPair) []*models.ConfigPair {
+	var sidecars []*models.ConfigPair
+	for _, pair := range pairs {
+		if pair.Type == "" {
+			continue
+		}
+		sidecars = append(sidecars, &models.ConfigPair{
+			Key:     TypeKey(pair.Key),
+			Value:   string(pair.Type) + ":" + valueFingerprint(pair.Value),
+			Context: pair.Context,
+		})
+	}
+	return sidecars
+}
+
+// ApplyTypes sets the type of every pair that has an entry in types, which
+// maps sidecar keys to their values. Unknown types and sidecars recorded for
+// another value are ignored.
+func ApplyTypes(pairs []*models.ConfigPair, types map[string]string) {
+	for _, pair := range pairs {
+		name, fingerprint, ok := strings.Cut(types[TypeKey(pair.Key)], ":")
+		if !ok || fingerprint != valueFingerprint(pair.Value) {
+			continue
+		}
+		if t := models.ValueType(name); t.IsValid() {
+			pair.Type = t
+		}
+	}
+}
+
+// valueFingerprint identifies value in a type sidecar
+func valueFingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:8])
+}
+
+// TypedValue is a value with the type it had in its source file. It
+// marshals to JSON and YAML as that type. A value that does not fit its
+// type, such as a stale int type on "abc", is written as a plain string.
+type TypedValue struct {
+	Value string
+	Type  models.ValueType
+}
+
+// MarshalJSON implements json.Marshaler
+func (v TypedValue) MarshalJSON() ([]byte, error) {
+	switch {
+	case v.Type == models.TypeNull:
+		return []byte("null"), nil
+	case v.Type == models.TypeList && v.fits():
+		items, _ := splitCSV(v.Value)
+		return json.Marshal(items)
+	case v.Type == models.TypeArray && v.Value == "":
+		// A kept empty array is stored as the empty value
+		return []byte("[]"), nil
+	case v.Type != models.TypeString && v.fits():
+		return []byte(v.Value), nil
+	default:
+		return json.Marshal(v.Value)
+	}
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (v TypedValue) MarshalYAML() (any, error) {
+	if !v.fits() && v.Type != models.TypeNull {
+		return v.Value, nil
+	}
+
+	switch v.Type {
+	case models.TypeString:
+		// Tagged so that "8080" and "true" stay quoted strings
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v.Value}, nil
+	case models.TypeInt:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: v.Value}, nil
+	case models.TypeFloat:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: v.Value}, nil
+	case models.TypeBool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: v.Value}, nil
+	case models.TypeNull:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case models.TypeArray:
+		if v.Value == "" {
+			return []any{}, nil
+		}
+		decoder := json.NewDecoder(strings.NewReader(v.Value))
+		decoder.UseNumber()
+		var items []any
+		if err := decoder.Decode(&items); err != nil {
+			return v.Value, nil
+		}
+		return typedJSON(items), nil
+	case models.TypeList:
+		items, _ := splitCSV(v.Value)
+		return items, nil
+	default:
+		return v.Value, nil
+	}
+}
+
+// fits reports whether the value can be written as its type
+func (v TypedValue) fits() bool {
+	switch v.Type {
+	case models.TypeString:
+		return true
+	case models.TypeInt:
+		return isJSONNumber(v.Value) && !strings.ContainsAny(v.Value, ".eE")
+	case models.TypeFloat:
+		return isJSONNumber(v.Value)
+	case models.TypeBool:
+		return v.Value == "true" || v.Value == "false"
+	case models.TypeArray:
+		trimmed := strings.TrimSpace(v.Value)
+		return v.Value == "" || strings.HasPrefix(trimmed, "[") && json.Valid([]byte(trimmed))
+	case models.TypeList:
+		_, ok := splitCSV(v.Value)
+		return ok
+	default:
+		return false
+	}
+}
+
+// isJSONNumber reports whether s is a number literal valid in both JSON and
+// YAML
+func isJSONNumber(s string) bool {
+	var n json.Number
+	if err := json.Unmarshal([]byte(s), &n); err != nil {
+		return false
+	}
+	return n.String() == s
+}
+
+// typedJSON replaces the numbers of a decoded JSON value with TypedValues
+// so that they keep their exact text in YAML.
+func typedJSON(value any) any {
+	switch v := value.(type) {
+	case []any:
+		for i, item := range v {
+			v[i] = typedJSON(item)
+		}
+		return v
+	case map[string]any:
+		for key, item := range v {
+			v[key] = typedJSON(item)
+		}
+		return v
+	case json.Number:
+		return TypedValue{Value: v.String(), Type: scalarType(v)}
+	default:
+		return v
+	}
+}
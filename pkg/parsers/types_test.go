@@ -0,0 +1,154 @@
+package parsers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kazuma-desu/etu/pkg/models"
+)
+
+func TestFlattenMapWithOptions_PreserveTypes(t *testing.T) {
+	input := map[string]any{
+		"port":   8080,
+		"quoted": "8080",
+		"ratio":  1.5,
+		"debug":  true,
+		"unset":  nil,
+		"tags":   []any{"a", "b"},
+		"exact":  json.Number("1.50"),
+	}
+
+	pairs := FlattenMapWithOptions(input, &FlattenOptions{PreserveTypes: true, KeepNulls: true})
+
+	assert.ElementsMatch(t, []*models.ConfigPair{
+		{Key: "/port", Value: "8080", Type: models.TypeInt},
+		{Key: "/quoted", Value: "8080", Type: models.TypeString},
+		{Key: "/ratio", Value: "1.5", Type: models.TypeFloat},
+		{Key: "/debug", Value: "true", Type: models.TypeBool},
+		{Key: "/unset", Value: "", Type: models.TypeNull},
+		{Key: "/tags", Value: `["a","b"]`, Type: models.TypeArray},
+		{Key: "/exact", Value: "1.50", Type: models.TypeFloat},
+	}, pairs)
+
+	// Types are only tracked on request
+	for _, pair := range FlattenMap(input) {
+		assert.Empty(t, pair.Type, pair.Key)
+	}
+}
+
+func TestJSONParser_PreserveTypes(t *testing.T) {
+	content := `{"id": 12345678901234567890, "ratio": 1.50, "big": 1.5e10, "name": "42"}`
+
+	parser := &JSONParser{Flatten: &FlattenOptions{PreserveTypes: true}}
+	pairs, err := parser.ParseReader(context.Background(), strings.NewReader(content))
+	require.NoError(t, err)
+
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "/id", Value: "12345678901234567890", Type: models.TypeInt},
+		{Key: "/ratio", Value: "1.50", Type: models.TypeFloat},
+		{Key: "/big", Value: "1.5e10", Type: models.TypeFloat},
+		{Key: "/name", Value: "42", Type: models.TypeString},
+	}, withoutPositions(pairs))
+}
+
+func TestTypedValue_Marshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    TypedValue
+		jsonText string
+		yamlText string
+	}{
+		{"string that looks like a number", TypedValue{"8080", models.TypeString}, `"8080"`, `"8080"`},
+		{"int", TypedValue{"8080", models.TypeInt}, `8080`, `8080`},
+		{"big int", TypedValue{"12345678901234567890", models.TypeInt}, `12345678901234567890`, `12345678901234567890`},
+		{"float keeps text", TypedValue{"1.50", models.TypeFloat}, `1.50`, `1.50`},
+		{"bool", TypedValue{"true", models.TypeBool}, `true`, `true`},
+		{"string that looks like a bool", TypedValue{"true", models.TypeString}, `"true"`, `"true"`},
+		{"null", TypedValue{"", models.TypeNull}, `null`, `null`},
+		{"array", TypedValue{`[1,"a",2.50]`, models.TypeArray}, `[1,"a",2.50]`, "- 1\n- a\n- 2.50"},
+		{"stale int type", TypedValue{"abc", models.TypeInt}, `"abc"`, `abc`},
+		{"int with leading zero", TypedValue{"08", models.TypeInt}, `"08"`, `"08"`},
+		{"bad array", TypedValue{"[1,", models.TypeArray}, `"[1,"`, `'[1,'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonData, err := json.Marshal(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.jsonText, string(jsonData))
+
+			yamlData, err := yaml.Marshal(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.yamlText, strings.TrimSuffix(string(yamlData), "\n"))
+		})
+	}
+}
+
+func TestTypeSidecars(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/app/port", Value: "8080", Type: models.TypeInt},
+		{Key: "/app/name", Value: "api"},
+		{Key: "/app/debug", Value: "true", Type: models.TypeBool, Context: "prod"},
+	}
+
+	sidecars := TypeSidecars(pairs)
+	require.Len(t, sidecars, 2)
+	assert.Equal(t, ".etu/types/app/port", sidecars[0].Key)
+	assert.Equal(t, "int:"+valueFingerprint("8080"), sidecars[0].Value)
+	assert.Equal(t, ".etu/types/app/debug", sidecars[1].Key)
+	assert.Equal(t, "prod", sidecars[1].Context)
+
+	assert.Equal(t, ".etu/types/app.port", TypeKey("app.port"))
+	assert.True(t, IsTypeKey(".etu/types/app/port"))
+	assert.False(t, IsTypeKey("/app/port"))
+	// Sidecars are not under "/"
+	assert.False(t, strings.HasPrefix(TypeKey("/app/port"), "/"))
+}
+
+func TestApplyTypes(t *testing.T) {
+	typed := []*models.ConfigPair{
+		{Key: "/app/port", Value: "8080", Type: models.TypeInt},
+		{Key: "/app/debug", Value: "true", Type: models.TypeBool},
+	}
+	types := make(map[string]string)
+	for _, sidecar := range TypeSidecars(typed) {
+		types[sidecar.Key] = sidecar.Value
+	}
+	types[TypeKey("/app/odd")] = "decimal:" + valueFingerprint("x")
+	types[TypeKey("/app/bare")] = "int"
+
+	pairs := []*models.ConfigPair{
+		{Key: "/app/port", Value: "8080"},
+		// Written with another value since its type was recorded
+		{Key: "/app/debug", Value: "9090"},
+		{Key: "/app/name", Value: "api"},
+		{Key: "/app/odd", Value: "x"},
+		{Key: "/app/bare", Value: "1"},
+	}
+	ApplyTypes(pairs, types)
+
+	assert.Equal(t, models.TypeInt, pairs[0].Type)
+	for _, pair := range pairs[1:] {
+		assert.Empty(t, pair.Type, pair.Key)
+	}
+}
+
+func TestUnflattenMapWithOptions_Types(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/app/port", Value: "8080", Type: models.TypeInt},
+		{Key: "/app/name", Value: "8080"},
+	}
+
+	nested, err := UnflattenMapWithOptions(pairs, &UnflattenOptions{Types: true})
+	require.NoError(t, err)
+
+	app := nested["app"].(map[string]any)
+	assert.Equal(t, TypedValue{Value: "8080", Type: models.TypeInt}, app["port"])
+	assert.Equal(t, "8080", app["name"])
+}
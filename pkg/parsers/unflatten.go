@@ -1,58 +1,197 @@
 package parsers
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 
 	"github.com/kazuma-desu/etu/pkg/models"
 )
 
+// UnflattenOptions controls how UnflattenMapWithOptions builds the nested map.
+type UnflattenOptions struct {
+	// KeepEmpty keeps keys with empty values instead of skipping them.
+	KeepEmpty bool
+
+	// DirectoryValues stores the value of a key that also has children under
+	// DirectoryValueKey instead of returning a collision error.
+	DirectoryValues bool
+
+	// Arrays reverses the FlattenOptions array mode of the same name.
+	// ArrayIndexed turns maps keyed 0..n-1 into slices and ArrayCSV splits
+	// comma-separated values into slices when they re-encode to the same
+	// text. ArrayJSON and the empty mode keep values as strings.
+	Arrays ArrayMode
+
+	// Types stores the value of every pair with a Type as a TypedValue, so
+	// that it is written back as that type.
+	Types bool
+
+	// Layout splits keys into path segments. The zero value splits
+	// /app/db/host.
+	Layout models.KeyLayout
+}
+
 // UnflattenMap converts a list of ConfigPairs back into a nested map structure.
 // It is the reverse operation of FlattenMap.
 //
 // Rules:
-// 1. Keys are split by "/" to create nested structure
+// 1. Keys are split by "/" to create nested structure (see UnflattenOptions.Layout)
 // 2. Empty string values are skipped
 // 3. Numeric keys are preserved as strings (no array conversion)
 // 4. Map values (JSON strings) are preserved as strings
 // 5. Collisions between values and directories return an error
 func UnflattenMap(pairs []*models.ConfigPair) (map[string]any, error) {
+	return UnflattenMapWithOptions(pairs, nil)
+}
+
+// UnflattenMapWithOptions converts ConfigPairs into a nested map like UnflattenMap.
+// With DirectoryValues and KeepEmpty set the result flattens back to exactly
+// the same pairs, as long as keys contain no empty path segments.
+func UnflattenMapWithOptions(pairs []*models.ConfigPair, opts *UnflattenOptions) (map[string]any, error) {
+	if opts == nil {
+		opts = &UnflattenOptions{}
+	}
 	result := make(map[string]any)
 
 	for _, pair := range pairs {
-		parts, shouldProcess := preparePair(pair)
+		parts, shouldProcess := preparePair(pair, opts)
 		if !shouldProcess {
 			continue
 		}
 
-		if err := insertPath(result, parts, pair); err != nil {
+		if len(parts) == 0 {
+			// The root key, such as "/", can only be stored as a directory value
+			result[DirectoryValueKey] = leafValue(pair, opts)
+			continue
+		}
+
+		if err := insertPath(result, parts, pair.Key, leafValue(pair, opts), opts.DirectoryValues); err != nil {
 			return nil, err
 		}
 	}
 
+	if opts.Arrays == ArrayIndexed {
+		restoreIndexedArrays(result)
+	}
+
 	return result, nil
 }
 
+// restoreIndexedArrays replaces the maps of m keyed 0..n-1 with slices, in
+// place.
+func restoreIndexedArrays(m map[string]any) {
+	for key, value := range m {
+		nested, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		restoreIndexedArrays(nested)
+		if items, ok := indexedItems(nested); ok {
+			m[key] = items
+		}
+	}
+}
+
+// leafValue returns the value stored in the nested map for pair
+func leafValue(pair *models.ConfigPair, opts *UnflattenOptions) any {
+	if opts.Types && pair.Type != "" {
+		return TypedValue{Value: pair.Value, Type: pair.Type}
+	}
+	if opts.Arrays == ArrayCSV {
+		switch pair.Type {
+		case models.TypeList:
+			if items, ok := splitCSV(pair.Value); ok {
+				return items
+			}
+		case "":
+			if items, ok := decodeCSV(pair.Value); ok {
+				return items
+			}
+		}
+	}
+	return pair.Value
+}
+
+// indexedItems returns the values of m in index order when its keys are
+// exactly "0" to "n-1".
+func indexedItems(m map[string]any) ([]any, bool) {
+	if len(m) == 0 {
+		return nil, false
+	}
+	items := make([]any, len(m))
+	for key, value := range m {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(m) || strconv.Itoa(i) != key {
+			return nil, false
+		}
+		items[i] = value
+	}
+	return items, true
+}
+
+// decodeCSV splits a comma-separated value of unknown type into its fields.
+// Values without a comma, or that would not be written back identically,
+// are left alone so that flattening the result gives the same text.
+func decodeCSV(value string) ([]any, bool) {
+	if !strings.Contains(value, ",") {
+		return nil, false
+	}
+	return splitCSV(value)
+}
+
+// splitCSV splits a value known to be a list, as stored by ArrayCSV, into
+// its fields. The empty value is the empty list. It reports false if the
+// value is not a CSV record that is written back identically.
+func splitCSV(value string) ([]any, bool) {
+	if value == "" {
+		return []any{}, true
+	}
+	reader := csv.NewReader(strings.NewReader(value))
+	reader.FieldsPerRecord = -1
+	record, err := reader.Read()
+	if err != nil {
+		return nil, false
+	}
+	if _, err := reader.Read(); !errors.Is(err, io.EOF) {
+		return nil, false
+	}
+	if encoded, ok := formatCSVRecord(record); !ok || encoded != value {
+		return nil, false
+	}
+
+	items := make([]any, len(record))
+	for i, field := range record {
+		items[i] = field
+	}
+	return items, true
+}
+
 // preparePair handles filtering and path splitting logic.
 // It returns the parts of the key and a boolean indicating whether to proceed.
-func preparePair(pair *models.ConfigPair) ([]string, bool) {
+func preparePair(pair *models.ConfigPair, opts *UnflattenOptions) ([]string, bool) {
 	if pair == nil {
 		return nil, false
 	}
 
 	// Skip empty string values
-	if pair.Value == "" {
+	if pair.Value == "" && !opts.KeepEmpty {
 		return nil, false
 	}
 
-	// Remove leading slash to handle absolute paths
-	key := strings.TrimPrefix(pair.Key, "/")
-	if key == "" {
+	// Remove the root to handle absolute paths
+	parts := opts.Layout.Split(pair.Key)
+	if len(parts) == 0 {
+		root := opts.Layout.Resolved().Root
+		if opts.DirectoryValues && root != "" && pair.Key == root {
+			return nil, true
+		}
 		return nil, false
 	}
 
-	parts := strings.Split(key, "/")
-
 	// Filter empty parts (handles consecutive slashes like /a//b)
 	filtered := parts[:0]
 	for _, p := range parts {
@@ -69,14 +208,14 @@ func preparePair(pair *models.ConfigPair) ([]string, bool) {
 }
 
 // insertPath navigates the map structure and inserts the value at the leaf.
-func insertPath(root map[string]any, parts []string, pair *models.ConfigPair) error {
+func insertPath(root map[string]any, parts []string, key string, value any, dirValues bool) error {
 	current := root
 	for i, part := range parts {
 		if i == len(parts)-1 {
-			return setLeafValue(current, part, pair)
+			return setLeafValue(current, part, key, value, dirValues)
 		}
 
-		nextMap, err := navigateToNextMap(current, part, pair.Key, parts[i+1])
+		nextMap, err := navigateToNextMap(current, part, key, parts[i+1], dirValues)
 		if err != nil {
 			return err
 		}
@@ -85,17 +224,21 @@ func insertPath(root map[string]any, parts []string, pair *models.ConfigPair) er
 	return nil
 }
 
-func setLeafValue(current map[string]any, part string, pair *models.ConfigPair) error {
+func setLeafValue(current map[string]any, part, key string, value any, dirValues bool) error {
 	if existing, exists := current[part]; exists {
-		if _, isMap := existing.(map[string]any); isMap {
-			return fmt.Errorf("key collision: '%s' is implicitly a directory (has children), cannot set as value", pair.Key)
+		if asMap, isMap := existing.(map[string]any); isMap {
+			if dirValues {
+				asMap[DirectoryValueKey] = value
+				return nil
+			}
+			return fmt.Errorf("key collision: '%s' is implicitly a directory (has children), cannot set as value", key)
 		}
 	}
-	current[part] = pair.Value
+	current[part] = value
 	return nil
 }
 
-func navigateToNextMap(current map[string]any, part, originalKey, nextPart string) (map[string]any, error) {
+func navigateToNextMap(current map[string]any, part, originalKey, nextPart string, dirValues bool) (map[string]any, error) {
 	existing, exists := current[part]
 
 	if !exists {
@@ -108,6 +251,13 @@ func navigateToNextMap(current map[string]any, part, originalKey, nextPart strin
 		return asMap, nil
 	}
 
+	if dirValues {
+		// Turn the value into a directory that keeps it under DirectoryValueKey
+		newMap := map[string]any{DirectoryValueKey: existing}
+		current[part] = newMap
+		return newMap, nil
+	}
+
 	return nil, fmt.Errorf("key collision: '%s' is already a value, cannot append '%s'",
 		originalKey, nextPart)
 }
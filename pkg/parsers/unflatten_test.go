@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
 
 	"github.com/kazuma-desu/etu/pkg/models"
 )
@@ -225,3 +226,226 @@ func TestUnflattenMap_OnlySlashes(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, result)
 }
+
+func TestUnflattenMapWithOptions_DirectoryValues(t *testing.T) {
+	orders := [][]*models.ConfigPair{
+		{{Key: "/app/db", Value: "primary"}, {Key: "/app/db/host", Value: "localhost"}},
+		{{Key: "/app/db/host", Value: "localhost"}, {Key: "/app/db", Value: "primary"}},
+	}
+
+	for _, pairs := range orders {
+		result, err := UnflattenMapWithOptions(pairs, &UnflattenOptions{DirectoryValues: true})
+		require.NoError(t, err)
+
+		app, ok := result["app"].(map[string]any)
+		require.True(t, ok)
+		db, ok := app["db"].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, "primary", db[DirectoryValueKey])
+		assert.Equal(t, "localhost", db["host"])
+	}
+}
+
+func TestUnflattenMapWithOptions_KeepEmpty(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/present", Value: "value"},
+		{Key: "/empty", Value: ""},
+	}
+
+	result, err := UnflattenMapWithOptions(pairs, &UnflattenOptions{KeepEmpty: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"present": "value", "empty": ""}, result)
+}
+
+func TestUnflattenMapWithOptions_RootKey(t *testing.T) {
+	pairs := []*models.ConfigPair{{Key: "/", Value: "root"}, {Key: "/a", Value: "1"}}
+
+	result, err := UnflattenMapWithOptions(pairs, &UnflattenOptions{DirectoryValues: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{DirectoryValueKey: "root", "a": "1"}, result)
+
+	result, err = UnflattenMap(pairs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": "1"}, result)
+}
+
+func TestUnflattenMapWithOptions_RoundTrip(t *testing.T) {
+	original := []*models.ConfigPair{
+		{Key: "/", Value: "root"},
+		{Key: "/app", Value: ""},
+		{Key: "/app/db", Value: "primary"},
+		{Key: "/app/db/host", Value: "localhost"},
+		{Key: "/app/empty", Value: ""},
+	}
+
+	nested, err := UnflattenMapWithOptions(original, &UnflattenOptions{KeepEmpty: true, DirectoryValues: true})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, original, FlattenMap(nested))
+}
+
+func TestUnflattenMapWithOptions_Layout(t *testing.T) {
+	layout := models.KeyLayout{Separator: ":", Root: "config:"}
+	original := []*models.ConfigPair{
+		{Key: "config:", Value: "root"},
+		{Key: "config:app", Value: "primary"},
+		{Key: "config:app:db:host", Value: "localhost"},
+		{Key: "config:app:url", Value: "http://x/y"},
+	}
+
+	nested, err := UnflattenMapWithOptions(original, &UnflattenOptions{DirectoryValues: true, Layout: layout})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		DirectoryValueKey: "root",
+		"app": map[string]any{
+			DirectoryValueKey: "primary",
+			"db":              map[string]any{"host": "localhost"},
+			"url":             "http://x/y",
+		},
+	}, nested)
+
+	assert.ElementsMatch(t, original, FlattenMapWithOptions(nested, &FlattenOptions{Layout: layout}))
+}
+
+func TestUnflattenMapWithOptions_IndexedArrays(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/tags/0", Value: "dev"},
+		{Key: "/tags/1", Value: "prod"},
+		{Key: "/servers/0/host", Value: "server1"},
+		{Key: "/servers/1/host", Value: "server2"},
+		{Key: "/sparse/0", Value: "a"},
+		{Key: "/sparse/2", Value: "c"},
+		{Key: "/padded/00", Value: "x"},
+	}
+
+	nested, err := UnflattenMapWithOptions(pairs, &UnflattenOptions{Arrays: ArrayIndexed})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"tags": []any{"dev", "prod"},
+		"servers": []any{
+			map[string]any{"host": "server1"},
+			map[string]any{"host": "server2"},
+		},
+		"sparse": map[string]any{"0": "a", "2": "c"},
+		"padded": map[string]any{"00": "x"},
+	}, nested)
+}
+
+func TestUnflattenMapWithOptions_CSVArrays(t *testing.T) {
+	pairs := []*models.ConfigPair{
+		{Key: "/tags", Value: `dev,"a,b",3`},
+		{Key: "/single", Value: "dev"},
+		{Key: "/spaced", Value: "a, b"},
+		{Key: "/json", Value: `["a","b"]`},
+	}
+
+	nested, err := UnflattenMapWithOptions(pairs, &UnflattenOptions{Arrays: ArrayCSV})
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"dev", "a,b", "3"}, nested["tags"])
+	assert.Equal(t, "dev", nested["single"])
+	// Would be written back as a," b", so it stays a string
+	assert.Equal(t, "a, b", nested["spaced"])
+	assert.Equal(t, `["a","b"]`, nested["json"])
+}
+
+func TestUnflattenMapWithOptions_CSVTypedRoundTrip(t *testing.T) {
+	input := map[string]any{
+		"list":   []any{"only"},
+		"blank":  []any{""},
+		"tags":   []any{"a", "b,c"},
+		"name":   "a,b",
+		"unset":  nil,
+		"empty":  []any{},
+		"sparse": []any{"a", nil},
+	}
+	opts := &FlattenOptions{Arrays: ArrayCSV, KeepNulls: true, KeepEmptyArrays: true}
+	pairs := FlattenMapWithOptions(input, opts)
+	assertPair(t, pairs, "/blank", `""`)
+	// Lists holding nulls fall back to JSON
+	assertPair(t, pairs, "/sparse", `["a",null]`)
+
+	nested, err := UnflattenMapWithOptions(pairs, &UnflattenOptions{KeepEmpty: true, Arrays: ArrayCSV, Types: true})
+	require.NoError(t, err)
+
+	data, err := yaml.Marshal(nested)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, yaml.Unmarshal(data, &decoded))
+	assert.Equal(t, input, decoded, "marshaled:\n%s", data)
+
+	// A recorded list type is honored without the other types
+	nested, err = UnflattenMapWithOptions(pairs, &UnflattenOptions{KeepEmpty: true, Arrays: ArrayCSV})
+	require.NoError(t, err)
+	assert.Equal(t, []any{"only"}, nested["list"])
+	assert.Equal(t, []any{}, nested["empty"])
+	assert.Equal(t, "a,b", nested["name"])
+}
+
+func TestUnflattenMapWithOptions_PreserveTypesRoundTrip(t *testing.T) {
+	input := map[string]any{
+		"empty": []any{},
+		"ports": []any{80, 443},
+		"tags":  []any{"a", "b,c"},
+		"mixed": []any{"a", 1, true},
+	}
+
+	for _, mode := range []ArrayMode{ArrayJSON, ArrayIndexed, ArrayCSV} {
+		t.Run(string(mode), func(t *testing.T) {
+			pairs := FlattenMapWithOptions(input, &FlattenOptions{Arrays: mode, KeepEmptyArrays: true, PreserveTypes: true})
+			nested, err := UnflattenMapWithOptions(pairs, &UnflattenOptions{KeepEmpty: true, Arrays: mode, Types: true})
+			require.NoError(t, err)
+
+			data, err := yaml.Marshal(nested)
+			require.NoError(t, err)
+			var decoded map[string]any
+			require.NoError(t, yaml.Unmarshal(data, &decoded))
+			assert.Equal(t, input, decoded, "marshaled:\n%s", data)
+		})
+	}
+
+	// Only string lists are stored as CSV when types are preserved
+	pairs := FlattenMapWithOptions(input, &FlattenOptions{Arrays: ArrayCSV, PreserveTypes: true})
+	assertPair(t, pairs, "/ports", "[80,443]")
+	assertPair(t, pairs, "/tags", `a,"b,c"`)
+}
+
+func TestUnflattenMapWithOptions_ArrayRoundTrip(t *testing.T) {
+	tests := []struct {
+		mode  ArrayMode
+		pairs []*models.ConfigPair
+	}{
+		{ArrayIndexed, []*models.ConfigPair{
+			{Key: "/tags/0", Value: "dev"},
+			{Key: "/tags/1", Value: ""},
+			{Key: "/matrix/0/0", Value: "1"},
+			{Key: "/servers/0/host", Value: "server1"},
+			{Key: "/sparse/1", Value: "b"},
+		}},
+		{ArrayCSV, []*models.ConfigPair{
+			{Key: "/tags", Value: `dev,"a,b",,3`},
+			{Key: "/spaced", Value: "a, b"},
+			{Key: "/quoted", Value: `"a",b`},
+			{Key: "/empty", Value: ""},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			nested, err := UnflattenMapWithOptions(tt.pairs, &UnflattenOptions{
+				KeepEmpty:       true,
+				DirectoryValues: true,
+				Arrays:          tt.mode,
+			})
+			require.NoError(t, err)
+
+			flattened := FlattenMapWithOptions(nested, &FlattenOptions{Arrays: tt.mode, KeepNulls: true, KeepEmptyArrays: true})
+			for _, pair := range flattened {
+				pair.Type = ""
+			}
+			assert.ElementsMatch(t, tt.pairs, flattened)
+		})
+	}
+}
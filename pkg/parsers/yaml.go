@@ -5,7 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"regexp"
+	"strconv"
 
 	"github.com/kazuma-desu/etu/pkg/models"
 
@@ -14,28 +15,52 @@ import (
 
 var ErrRootNotMap = errors.New("YAML root must be a map, not an array or scalar")
 
-type YAMLParser struct{}
+// yamlLineError matches the "line N: message" form of yaml.v3 errors
+var yamlLineError = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// DocumentHeaderKey is a reserved top-level key holding settings for the
+// YAML document it appears in rather than configuration:
+//
+//	$etu:
+//	  prefix: /services/api
+//	  context: production
+//
+// The prefix is prepended to every key of the document and the context
+// selects the etcd context the document's pairs are applied to.
+const DocumentHeaderKey = "$etu"
+
+// DocumentHeader holds the settings read from a DocumentHeaderKey entry
+type DocumentHeader struct {
+	Prefix  string
+	Context string
+}
+
+type YAMLParser struct {
+	// Flatten controls how arrays and nulls are flattened. Nil keeps the
+	// FlattenMap defaults.
+	Flatten *FlattenOptions
+}
 
 func (p *YAMLParser) FormatName() string {
 	return "yaml"
 }
 
 func (p *YAMLParser) Parse(ctx context.Context, path string) ([]*models.ConfigPair, error) {
-	// Check for cancellation before opening file
-	if err := ctx.Err(); err != nil {
-		return nil, err
-	}
+	return parseFile(ctx, path, p.ParseReader)
+}
 
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+// ParseReader parses YAML content from a stream. Every document in the stream
+// is parsed and the results merged; a key defined by more than one document
+// naming the same context is an error. Documents without a context are only
+// compared with each other, as the context they target is chosen by the
+// command.
+func (p *YAMLParser) ParseReader(ctx context.Context, r io.Reader) ([]*models.ConfigPair, error) {
+	decoder := yaml.NewDecoder(r)
 
-	decoder := yaml.NewDecoder(file)
+	type contextKey struct{ context, key string }
 
 	var pairs []*models.ConfigPair
-	docCount := 0
+	seen := make(map[contextKey]models.Position)
 
 	for {
 		// Check for cancellation before each decode
@@ -43,27 +68,171 @@ func (p *YAMLParser) Parse(ctx context.Context, path string) ([]*models.ConfigPa
 			return nil, err
 		}
 
-		var raw any
-		err := decoder.Decode(&raw)
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
 		if errors.Is(err, io.EOF) {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+			return nil, yamlParseError(err)
+		}
+
+		docPairs, err := parseYAMLDocument(&doc, p.Flatten)
+		if err != nil {
+			return nil, err
 		}
 
-		docCount++
-		switch docCount {
-		case 1:
-			data, ok := raw.(map[string]any)
-			if !ok {
-				return nil, ErrRootNotMap
+		for _, pair := range docPairs {
+			id := contextKey{pair.Context, pair.Key}
+			if first, ok := seen[id]; ok {
+				return nil, &ParseError{
+					Pos: pair.Pos,
+					Msg: fmt.Sprintf("duplicate key %s (first defined at line %d)", pair.Key, first.Line),
+				}
 			}
-			pairs = FlattenMap(data)
-		case 2:
-			fmt.Fprintf(os.Stderr, "Warning: YAML file contains multiple documents, only the first document is parsed\n")
+			seen[id] = pair.Pos
 		}
+		pairs = append(pairs, docPairs...)
+	}
+
+	return pairs, nil
+}
+
+// parseYAMLDocument flattens a single document, applying its header
+func parseYAMLDocument(doc *yaml.Node, flatten *FlattenOptions) ([]*models.ConfigPair, error) {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	// Empty documents, such as one after a trailing "---", hold no pairs
+	if root.Kind == yaml.DocumentNode || (root.Kind == yaml.ScalarNode && root.Tag == "!!null") {
+		return nil, nil
+	}
+
+	header, err := takeYAMLHeader(root, flatten.layout())
+	if err != nil {
+		return nil, err
+	}
+
+	var raw any
+	if err := root.Decode(&raw); err != nil {
+		return nil, yamlParseError(err)
+	}
+	data, ok := raw.(map[string]any)
+	if !ok {
+		return nil, &ParseError{Pos: yamlNodePosition(root), Msg: ErrRootNotMap.Error(), Err: ErrRootNotMap}
+	}
+	pairs := FlattenMapWithOptions(data, flatten)
+
+	positions := keyPositions{}
+	recordYAMLPositions(positions, "", root)
+	positions.apply(pairs, flatten.layout())
+
+	MountPrefix(pairs, header.Prefix, flatten.layout())
+	for _, pair := range pairs {
+		pair.Context = header.Context
 	}
 
 	return pairs, nil
 }
+
+// takeYAMLHeader removes the DocumentHeaderKey entry from the root mapping
+// and returns the header it declares. The prefix must be a key in layout.
+func takeYAMLHeader(root *yaml.Node, layout models.KeyLayout) (DocumentHeader, error) {
+	if root.Kind != yaml.MappingNode {
+		return DocumentHeader{}, nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != DocumentHeaderKey {
+			continue
+		}
+		value := root.Content[i+1]
+		root.Content = append(root.Content[:i:i], root.Content[i+2:]...)
+		return parseYAMLHeader(value, layout)
+	}
+	return DocumentHeader{}, nil
+}
+
+func parseYAMLHeader(node *yaml.Node, layout models.KeyLayout) (DocumentHeader, error) {
+	var header DocumentHeader
+	if node.Kind != yaml.MappingNode {
+		return header, &ParseError{Pos: yamlNodePosition(node), Msg: DocumentHeaderKey + " must be a map"}
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if valueNode.Kind != yaml.ScalarNode {
+			return header, &ParseError{
+				Pos: yamlNodePosition(valueNode),
+				Msg: fmt.Sprintf("%s.%s must be a string", DocumentHeaderKey, keyNode.Value),
+			}
+		}
+
+		switch keyNode.Value {
+		case "prefix":
+			if valueNode.Value != "" && !layout.HasRoot(valueNode.Value) {
+				return header, &ParseError{
+					Pos: yamlNodePosition(valueNode),
+					Msg: fmt.Sprintf("%s.prefix must start with '%s'", DocumentHeaderKey, layout.Resolved().Root),
+				}
+			}
+			header.Prefix = valueNode.Value
+		case "context":
+			header.Context = valueNode.Value
+		default:
+			return header, &ParseError{
+				Pos: yamlNodePosition(keyNode),
+				Msg: fmt.Sprintf("unknown %s setting %q", DocumentHeaderKey, keyNode.Value),
+			}
+		}
+	}
+
+	return header, nil
+}
+
+// recordYAMLPositions records the position of every mapping key under node
+func recordYAMLPositions(positions keyPositions, prefix string, node *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+		if keyNode.Tag == "!!merge" {
+			continue
+		}
+		key := joinKey(prefix, keyNode.Value)
+		positions.record(key, yamlNodePosition(keyNode))
+		if keyNode.Value != DirectoryValueKey {
+			recordYAMLPositions(positions, key, valueNode)
+		}
+	}
+}
+
+func yamlNodePosition(node *yaml.Node) models.Position {
+	return models.Position{Line: node.Line, Column: node.Column}
+}
+
+// yamlParseError converts a yaml.v3 error to a *ParseError when it names a
+// line. Only the first of several unmarshal errors is reported.
+func yamlParseError(err error) error {
+	msg := err.Error()
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		msg = typeErr.Errors[0]
+		if more := len(typeErr.Errors) - 1; more > 0 {
+			msg += fmt.Sprintf(" (and %d more)", more)
+		}
+	}
+
+	m := yamlLineError.FindStringSubmatch(msg)
+	if m == nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &ParseError{
+		Pos: models.Position{Line: line},
+		Msg: "failed to parse YAML: " + m[2],
+		Err: err,
+	}
+}
@@ -1,10 +1,10 @@
 package parsers
 
 import (
-	"bytes"
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kazuma-desu/etu/pkg/models"
@@ -197,30 +197,134 @@ name: myapp
 	assertYAMLPair(t, pairs, "/name", "myapp")
 }
 
-func TestYAMLParser_MultiDocument_ParsesFirstOnly(t *testing.T) {
+func TestYAMLParser_MultiDocument(t *testing.T) {
 	content := `---
 first: doc1
 ---
 second: doc2
+---
 `
-	var stderr bytes.Buffer
-	oldStderr := os.Stderr
 
-	r, w, err := os.Pipe()
-	require.NoError(t, err, "failed to create pipe")
+	pairs := parseYAML(t, content)
 
-	os.Stderr = w
-	t.Cleanup(func() { os.Stderr = oldStderr })
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "/first", Value: "doc1"},
+		{Key: "/second", Value: "doc2"},
+	}, withoutPositions(pairs))
+}
+
+func TestYAMLParser_MultiDocument_Header(t *testing.T) {
+	content := `$etu:
+  prefix: /services/api/
+name: api
+db:
+  host: localhost
+---
+$etu:
+  prefix: /services/api
+  context: production
+name: api
+---
+$etu:
+  context: staging
+debug: "true"
+`
 
 	pairs := parseYAML(t, content)
 
-	w.Close()
-	stderr.ReadFrom(r)
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "/services/api/name", Value: "api"},
+		{Key: "/services/api/db/host", Value: "localhost"},
+		{Key: "/services/api/name", Value: "api", Context: "production"},
+		{Key: "/debug", Value: "true", Context: "staging"},
+	}, withoutPositions(pairs))
 
-	assert.Len(t, pairs, 1)
-	assertYAMLPair(t, pairs, "/first", "doc1")
-	assert.Contains(t, stderr.String(), "Warning")
-	assert.Contains(t, stderr.String(), "multiple documents")
+	assertPosition(t, 3, 1, pairs[0].Pos)
+	assertPosition(t, 10, 1, pairs[2].Pos)
+	assertPosition(t, 14, 1, pairs[3].Pos)
+}
+
+func TestYAMLParser_MultiDocument_DuplicateKey(t *testing.T) {
+	content := `app:
+  name: one
+---
+$etu:
+  prefix: /app
+name: two
+`
+
+	_, err := (&YAMLParser{}).ParseReader(context.Background(), strings.NewReader(content))
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Contains(t, err.Error(), "duplicate key /app/name (first defined at line 2)")
+	assertPosition(t, 6, 1, parseErr.Pos)
+}
+
+func TestYAMLParser_HeaderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errMsg  string
+	}{
+		{"not a map", "$etu: /app\nname: x\n", "1:7: $etu must be a map"},
+		{"relative prefix", "$etu:\n  prefix: app\n", "2:11: $etu.prefix must start with '/'"},
+		{"non-string value", "$etu:\n  context: [a, b]\n", "2:12: $etu.context must be a string"},
+		{"unknown setting", "$etu:\n  target: prod\n", `2:3: unknown $etu setting "target"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&YAMLParser{}).ParseReader(context.Background(), strings.NewReader(tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestYAMLParser_FlattenOptions(t *testing.T) {
+	content := `hosts:
+  - a.example.com
+  - b.example.com
+empty: []
+unset: null
+`
+	parser := &YAMLParser{Flatten: &FlattenOptions{Arrays: ArrayIndexed, KeepEmptyArrays: true, KeepNulls: true}}
+	pairs, err := parser.ParseReader(context.Background(), strings.NewReader(content))
+	require.NoError(t, err)
+
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "/hosts/0", Value: "a.example.com"},
+		{Key: "/hosts/1", Value: "b.example.com"},
+		{Key: "/empty", Value: ""},
+		{Key: "/unset", Value: ""},
+	}, withoutPositions(pairs))
+	// Elements take the position of their array's key
+	assertPosition(t, 1, 1, pairs[1].Pos)
+}
+
+func TestYAMLParser_Layout(t *testing.T) {
+	content := `$etu:
+  prefix: services.api
+app:
+  name: api
+  db:
+    host: localhost
+`
+	parser := &YAMLParser{Flatten: &FlattenOptions{Layout: models.KeyLayout{Separator: "."}}}
+	pairs, err := parser.ParseReader(context.Background(), strings.NewReader(content))
+	require.NoError(t, err)
+
+	assert.Equal(t, []*models.ConfigPair{
+		{Key: "services.api.app.name", Value: "api"},
+		{Key: "services.api.app.db.host", Value: "localhost"},
+	}, withoutPositions(pairs))
+	assertPosition(t, 4, 3, pairs[0].Pos)
+	assertPosition(t, 6, 5, pairs[1].Pos)
+
+	parser.Flatten.Layout.Root = "config."
+	_, err = parser.ParseReader(context.Background(), strings.NewReader(content))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2:11: $etu.prefix must start with 'config.'")
 }
 
 func TestYAMLParser_RootArrayError(t *testing.T) {
@@ -427,6 +531,53 @@ parent:
 	assertYAMLPair(t, pairs, "/parent/present", "value")
 }
 
+func TestYAMLParser_Positions(t *testing.T) {
+	content := `# service config
+app:
+  name: myapp
+  db:
+    "": primary
+    host: localhost
+ports: [80, 443]
+`
+	pairs := parseYAML(t, content)
+
+	require.Len(t, pairs, 4)
+	assert.Equal(t, "/app/name", pairs[0].Key)
+	assertPosition(t, 3, 3, pairs[0].Pos)
+	assert.Equal(t, "/app/db", pairs[1].Key)
+	// Directory values point at the parent key
+	assertPosition(t, 4, 3, pairs[1].Pos)
+	assert.Equal(t, "/app/db/host", pairs[2].Key)
+	assertPosition(t, 6, 5, pairs[2].Pos)
+	assert.Equal(t, "/ports", pairs[3].Key)
+	assertPosition(t, 7, 1, pairs[3].Pos)
+	assert.Equal(t, "test.yaml", filepath.Base(pairs[0].Pos.File))
+}
+
+func TestYAMLParser_ErrorPosition(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		message string
+	}{
+		{"syntax error", "app:\n  name: x\n  bad: : y\n", 3, "mapping values are not allowed"},
+		{"duplicate key", "app: 1\ndb: 2\napp: 3\n", 3, `mapping key "app" already defined at line 1`},
+		{"root not a map", "\n- a\n- b\n", 2, ErrRootNotMap.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&YAMLParser{}).ParseReader(context.Background(), strings.NewReader(tt.content))
+			var parseErr *ParseError
+			require.ErrorAs(t, err, &parseErr)
+			assert.Equal(t, tt.line, parseErr.Pos.Line)
+			assert.Contains(t, parseErr.Error(), tt.message)
+		})
+	}
+}
+
 func parseYAML(t *testing.T, content string) []*models.ConfigPair {
 	t.Helper()
 
@@ -0,0 +1,167 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+)
+
+// maxSearchResults bounds the number of keys a search returns.
+const maxSearchResults = 1000
+
+// errSearchLimit stops a search once maxSearchResults keys were found.
+var errSearchLimit = errors.New("search result limit reached")
+
+// childrenMsg carries the listing of dir. selectPath, if set, is the path
+// of the child to place the cursor on.
+type childrenMsg struct {
+	err        error
+	dir        string
+	selectPath string
+	children   []client.Child
+}
+
+// valueMsg carries the current value of a key.
+type valueMsg struct {
+	err error
+	kv  *client.KeyValue
+	key string
+}
+
+// savedMsg reports the result of saving an edited value.
+type savedMsg struct {
+	err      error
+	key      string
+	revision int64
+}
+
+// deletedMsg reports the result of deleting a key.
+type deletedMsg struct {
+	err error
+	key string
+}
+
+// searchMsg carries the keys under dir that contain query.
+type searchMsg struct {
+	err       error
+	query     string
+	keys      []string
+	truncated bool
+}
+
+// connectedMsg carries a new connection after a context switch.
+type connectedMsg struct {
+	err     error
+	client  client.EtcdClient
+	cleanup func()
+	context string
+}
+
+// watchMsg carries one watch response. gen identifies the watch it belongs
+// to, so responses from a watch that was replaced are ignored.
+type watchMsg struct {
+	resp   client.WatchResponse
+	gen    int
+	closed bool
+}
+
+func loadChildren(c client.EtcdClient, timeout time.Duration, dir, selectPath string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		children, err := client.ListChildren(ctx, c, dir, &client.PageOptions{KeysOnly: true})
+		return childrenMsg{dir: dir, children: children, selectPath: selectPath, err: err}
+	}
+}
+
+func loadValue(c client.EtcdClient, timeout time.Duration, key string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		resp, err := c.GetWithOptions(ctx, key, nil)
+		if err != nil {
+			return valueMsg{key: key, err: err}
+		}
+		if len(resp.Kvs) == 0 {
+			return valueMsg{key: key}
+		}
+		return valueMsg{key: key, kv: resp.Kvs[0]}
+	}
+}
+
+// saveValue writes value only if key still has modRevision, so an edit
+// never overwrites a change made since the value was loaded.
+func saveValue(c client.EtcdClient, timeout time.Duration, key, value string, modRevision int64) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		rev, err := c.Txn(ctx,
+			[]client.TxnGuard{{Key: key, ModRevision: modRevision}},
+			[]client.TxnOp{{Type: client.TxnOpPut, Key: key, Value: value}})
+		return savedMsg{key: key, revision: rev, err: err}
+	}
+}
+
+// deleteKey deletes key only if it still has modRevision.
+func deleteKey(c client.EtcdClient, timeout time.Duration, key string, modRevision int64) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		_, err := c.Txn(ctx,
+			[]client.TxnGuard{{Key: key, ModRevision: modRevision}},
+			[]client.TxnOp{{Type: client.TxnOpDelete, Key: key}})
+		return deletedMsg{key: key, err: err}
+	}
+}
+
+// searchKeys finds the keys under dir whose name contains query, ignoring
+// case. Only key names are read.
+func searchKeys(c client.EtcdClient, timeout time.Duration, dir, query string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		needle := strings.ToLower(query)
+		msg := searchMsg{query: query}
+		_, err := client.ForEachPage(ctx, c, dir, &client.PageOptions{KeysOnly: true}, func(kvs []*client.KeyValue) error {
+			for _, kv := range kvs {
+				if !strings.Contains(strings.ToLower(kv.Key), needle) {
+					continue
+				}
+				if len(msg.keys) == maxSearchResults {
+					msg.truncated = true
+					return errSearchLimit
+				}
+				msg.keys = append(msg.keys, kv.Key)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errSearchLimit) {
+			msg.err = err
+		}
+		return msg
+	}
+}
+
+func connect(connectFn func(string) (client.EtcdClient, func(), error), name string) tea.Cmd {
+	return func() tea.Msg {
+		c, cleanup, err := connectFn(name)
+		return connectedMsg{client: c, cleanup: cleanup, context: name, err: err}
+	}
+}
+
+func waitForWatch(ch client.WatchChan, gen int) tea.Cmd {
+	return func() tea.Msg {
+		resp, ok := <-ch
+		return watchMsg{resp: resp, gen: gen, closed: !ok}
+	}
+}
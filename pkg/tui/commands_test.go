@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+)
+
+func TestLoadValue(t *testing.T) {
+	mock := sampleStore()
+
+	msg := loadValue(mock, DefaultTimeout, "/app/name")().(valueMsg)
+	require.NoError(t, msg.err)
+	require.NotNil(t, msg.kv)
+	assert.Equal(t, "demo", msg.kv.Value)
+
+	msg = loadValue(mock, DefaultTimeout, "/missing")().(valueMsg)
+	require.NoError(t, msg.err)
+	assert.Nil(t, msg.kv)
+	assert.Equal(t, "/missing", msg.key)
+}
+
+func TestLoadValue_Error(t *testing.T) {
+	mock := &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, _ string, _ *client.GetOptions) (*client.GetResponse, error) {
+			return nil, errors.New("unavailable")
+		},
+	}
+
+	msg := loadValue(mock, DefaultTimeout, "/a")().(valueMsg)
+	assert.EqualError(t, msg.err, "unavailable")
+}
+
+func TestSaveValue_ReportsConflict(t *testing.T) {
+	mock := &client.MockClient{
+		TxnFunc: func(_ context.Context, _ []client.TxnGuard, _ []client.TxnOp) (int64, error) {
+			return 0, client.ErrTxnConflict
+		},
+	}
+
+	msg := saveValue(mock, DefaultTimeout, "/a", "v", 3)().(savedMsg)
+	assert.ErrorIs(t, msg.err, client.ErrTxnConflict)
+	assert.Equal(t, []client.TxnGuard{{Key: "/a", ModRevision: 3}}, mock.TxnCalls[0].Guards)
+}
+
+func TestSearchKeys(t *testing.T) {
+	msg := searchKeys(sampleStore(), DefaultTimeout, "/app/", "CONFIG")().(searchMsg)
+
+	require.NoError(t, msg.err)
+	assert.Equal(t, []string{"/app/config/db", "/app/config/port"}, msg.keys)
+	assert.False(t, msg.truncated)
+}
+
+func TestSearchKeys_Truncates(t *testing.T) {
+	data := make(map[string]string, maxSearchResults+5)
+	for i := 0; i < maxSearchResults+5; i++ {
+		data[fmt.Sprintf("/k/%05d", i)] = "v"
+	}
+
+	msg := searchKeys(storeMock(data), DefaultTimeout, "/", "k")().(searchMsg)
+
+	require.NoError(t, msg.err)
+	assert.Len(t, msg.keys, maxSearchResults)
+	assert.True(t, msg.truncated)
+}
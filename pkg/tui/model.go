@@ -0,0 +1,791 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+// mode is what keyboard input currently drives.
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeEdit
+	modeConfirmDelete
+	modeSearch
+	modeResults
+	modeContexts
+)
+
+// Layout constants, in terminal cells.
+const (
+	minTreeWidth  = 28
+	minValueWidth = 20
+	headerHeight  = 1
+	footerHeight  = 2
+	paneChrome    = 2 // top and bottom border
+)
+
+type model struct {
+	client  client.EtcdClient
+	cleanup func()
+
+	// value is the selected key as last loaded, nil for directories or
+	// while loading. valueKey is the key the value pane belongs to.
+	value    *client.KeyValue
+	valueKey string
+
+	// target is the key being edited or deleted, as it was displayed when
+	// the action started. Its revision guards the write.
+	target *client.KeyValue
+
+	watchCancel context.CancelFunc
+	watchCh     client.WatchChan
+
+	context     string
+	dir         string
+	status      string
+	valueFormat string
+
+	opts     Options
+	children []client.Child
+	results  []string
+
+	valueView viewport.Model
+	editor    textarea.Model
+	search    textinput.Model
+
+	cursor        int
+	resultCursor  int
+	contextCursor int
+	watchGen      int
+	width         int
+	height        int
+	mode          mode
+
+	statusErr bool
+	live      bool
+}
+
+func newModel(opts Options) *model {
+	if opts.Root == "" {
+		opts.Root = "/"
+	}
+	if !strings.HasSuffix(opts.Root, "/") {
+		opts.Root += "/"
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+
+	editor := textarea.New()
+	editor.ShowLineNumbers = true
+	editor.CharLimit = 0
+
+	search := textinput.New()
+	search.Prompt = "/ "
+	search.Placeholder = "key contains..."
+
+	return &model{
+		opts:      opts,
+		client:    opts.Client,
+		cleanup:   opts.Cleanup,
+		context:   opts.Context,
+		dir:       opts.Root,
+		valueView: viewport.New(0, 0),
+		editor:    editor,
+		search:    search,
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return loadChildren(m.client, m.opts.Timeout, m.dir, "")
+}
+
+// close stops the live watch and releases the connection.
+func (m *model) close() {
+	m.stopWatch()
+	if m.cleanup != nil {
+		m.cleanup()
+		m.cleanup = nil
+	}
+}
+
+func (m *model) selected() *client.Child {
+	if m.cursor < 0 || m.cursor >= len(m.children) {
+		return nil
+	}
+	return &m.children[m.cursor]
+}
+
+func (m *model) setStatus(format string, args ...any) {
+	m.status = fmt.Sprintf(format, args...)
+	m.statusErr = false
+}
+
+func (m *model) setError(format string, args ...any) {
+	m.status = fmt.Sprintf(format, args...)
+	m.statusErr = true
+}
+
+// selectionChanged updates the value pane for the child under the cursor.
+func (m *model) selectionChanged() tea.Cmd {
+	m.value = nil
+	m.valueKey = ""
+	m.valueView.SetContent("")
+
+	child := m.selected()
+	if child == nil || child.Dir {
+		return nil
+	}
+	m.valueKey = child.Path(m.dir)
+	return loadValue(m.client, m.opts.Timeout, m.valueKey)
+}
+
+// openDir lists dir and selects selectPath in it once loaded.
+func (m *model) openDir(dir, selectPath string) tea.Cmd {
+	m.dir = dir
+	m.children = nil
+	m.cursor = 0
+	m.value = nil
+	m.valueKey = ""
+	m.mode = modeBrowse
+
+	cmds := []tea.Cmd{loadChildren(m.client, m.opts.Timeout, dir, selectPath)}
+	if m.live {
+		cmds = append(cmds, m.startWatch())
+	}
+	return tea.Batch(cmds...)
+}
+
+// reload lists the current directory again, keeping the selection.
+func (m *model) reload() tea.Cmd {
+	selectPath := ""
+	if child := m.selected(); child != nil {
+		selectPath = child.Path(m.dir)
+	}
+	return loadChildren(m.client, m.opts.Timeout, m.dir, selectPath)
+}
+
+func (m *model) startWatch() tea.Cmd {
+	m.stopWatch()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.watchCancel = cancel
+	m.watchCh = m.client.Watch(ctx, m.dir, &client.WatchOptions{Prefix: true})
+	return waitForWatch(m.watchCh, m.watchGen)
+}
+
+func (m *model) stopWatch() {
+	if m.watchCancel != nil {
+		m.watchCancel()
+		m.watchCancel = nil
+	}
+	// Responses still in flight from the old watch are ignored
+	m.watchGen++
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.resize()
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case childrenMsg:
+		return m, m.handleChildren(msg)
+
+	case valueMsg:
+		m.handleValue(msg)
+		return m, nil
+
+	case savedMsg:
+		return m, m.handleSaved(msg)
+
+	case deletedMsg:
+		return m, m.handleDeleted(msg)
+
+	case searchMsg:
+		m.handleSearch(msg)
+		return m, nil
+
+	case connectedMsg:
+		return m, m.handleConnected(msg)
+
+	case watchMsg:
+		return m, m.handleWatch(msg)
+	}
+
+	// Other messages, such as cursor blinks, go to the focused input
+	var cmd tea.Cmd
+	switch m.mode {
+	case modeEdit:
+		m.editor, cmd = m.editor.Update(msg)
+	case modeSearch:
+		m.search, cmd = m.search.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m *model) handleChildren(msg childrenMsg) tea.Cmd {
+	if msg.dir != m.dir {
+		return nil
+	}
+	if msg.err != nil {
+		m.setError("✗ failed to list %s: %v", msg.dir, msg.err)
+		return nil
+	}
+
+	m.children = msg.children
+	if msg.selectPath != "" {
+		for i, c := range m.children {
+			if c.Path(m.dir) == msg.selectPath {
+				m.cursor = i
+				break
+			}
+		}
+	}
+	m.cursor = max(min(m.cursor, len(m.children)-1), 0)
+
+	// Keep an edit or confirmation in progress; writes are guarded by
+	// the target's revision anyway
+	if m.mode == modeEdit || m.mode == modeConfirmDelete {
+		return nil
+	}
+	// A refresh that keeps the same key selected leaves its value alone;
+	// callers that need a fresh value load it explicitly
+	if child := m.selected(); child != nil && !child.Dir && m.value != nil && child.Path(m.dir) == m.valueKey {
+		return nil
+	}
+	return m.selectionChanged()
+}
+
+func (m *model) handleValue(msg valueMsg) {
+	if msg.key != m.valueKey {
+		return
+	}
+	if msg.err != nil {
+		m.setError("✗ failed to read %s: %v", msg.key, msg.err)
+		return
+	}
+	if msg.kv == nil {
+		m.value = nil
+		m.valueView.SetContent(mutedStyle.Render("(key no longer exists)"))
+		return
+	}
+
+	// Keep the scroll position when the same key is reloaded
+	if m.value == nil || m.value.Key != msg.kv.Key {
+		m.valueView.GotoTop()
+	}
+	m.value = msg.kv
+	text, format := prettyValue(msg.kv.Value)
+	m.valueFormat = format
+	m.valueView.SetContent(text)
+}
+
+func (m *model) handleSaved(msg savedMsg) tea.Cmd {
+	if msg.err != nil {
+		if errors.Is(msg.err, client.ErrTxnConflict) {
+			m.setError("✗ %s was modified since it was loaded; esc to discard your edit, then reload", msg.key)
+		} else {
+			m.setError("✗ failed to save %s: %v", msg.key, msg.err)
+		}
+		return nil
+	}
+
+	m.mode = modeBrowse
+	m.editor.Blur()
+	m.setStatus("✓ Saved %s at revision %d", msg.key, msg.revision)
+	return tea.Batch(loadValue(m.client, m.opts.Timeout, msg.key), m.reload())
+}
+
+func (m *model) handleDeleted(msg deletedMsg) tea.Cmd {
+	if msg.err != nil {
+		if errors.Is(msg.err, client.ErrTxnConflict) {
+			m.setError("✗ %s was modified since it was loaded; not deleted", msg.key)
+		} else {
+			m.setError("✗ failed to delete %s: %v", msg.key, msg.err)
+		}
+		return nil
+	}
+
+	m.setStatus("✓ Deleted %s", msg.key)
+	return loadChildren(m.client, m.opts.Timeout, m.dir, "")
+}
+
+func (m *model) handleSearch(msg searchMsg) {
+	if msg.err != nil {
+		m.mode = modeBrowse
+		m.setError("✗ search failed: %v", msg.err)
+		return
+	}
+
+	m.results = msg.keys
+	m.resultCursor = 0
+	m.mode = modeResults
+	switch {
+	case msg.truncated:
+		m.setStatus("First %d keys containing %q", len(msg.keys), msg.query)
+	case len(msg.keys) == 0:
+		m.setStatus("No keys under %s contain %q", m.dir, msg.query)
+	default:
+		m.setStatus("%d keys contain %q", len(msg.keys), msg.query)
+	}
+}
+
+func (m *model) handleConnected(msg connectedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.setError("✗ failed to connect to %s: %v", msg.context, msg.err)
+		return nil
+	}
+
+	m.stopWatch()
+	if m.cleanup != nil {
+		m.cleanup()
+	}
+	m.client = msg.client
+	m.cleanup = msg.cleanup
+	m.context = msg.context
+	m.setStatus("✓ Switched to context %s", msg.context)
+	return m.openDir(m.opts.Root, "")
+}
+
+func (m *model) handleWatch(msg watchMsg) tea.Cmd {
+	if msg.gen != m.watchGen || !m.live {
+		return nil
+	}
+
+	switch {
+	case msg.closed:
+		m.live = false
+		m.setError("✗ live updates stopped: watch closed")
+		return nil
+	case msg.resp.Err != nil:
+		m.live = false
+		m.stopWatch()
+		m.setError("✗ live updates stopped: %v", msg.resp.Err)
+		return nil
+	case msg.resp.CompactRevision > 0:
+		m.live = false
+		m.stopWatch()
+		m.setError("✗ live updates stopped: revision %d was compacted", msg.resp.CompactRevision)
+		return nil
+	}
+
+	cmds := []tea.Cmd{m.reload(), waitForWatch(m.watchCh, m.watchGen)}
+	for _, event := range msg.resp.Events {
+		if event.Key != m.valueKey {
+			continue
+		}
+		if m.mode == modeEdit || m.mode == modeConfirmDelete {
+			m.setError("! %s changed on the server; saving will fail", event.Key)
+		} else {
+			cmds = append(cmds, loadValue(m.client, m.opts.Timeout, m.valueKey))
+		}
+		break
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	switch m.mode {
+	case modeEdit:
+		return m.handleEditKey(msg)
+	case modeConfirmDelete:
+		m.mode = modeBrowse
+		if msg.String() == "y" || msg.String() == "Y" {
+			m.setStatus("Deleting %s...", m.target.Key)
+			return m, deleteKey(m.client, m.opts.Timeout, m.target.Key, m.target.ModRevision)
+		}
+		m.setStatus("Deletion canceled")
+		return m, nil
+	case modeSearch:
+		return m.handleSearchKey(msg)
+	case modeResults:
+		return m, m.handleResultsKey(msg)
+	case modeContexts:
+		return m, m.handleContextsKey(msg)
+	}
+	return m.handleBrowseKey(msg)
+}
+
+func (m *model) handleEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = modeBrowse
+		m.editor.Blur()
+		m.setStatus("Edit canceled")
+		return m, nil
+	case "ctrl+s":
+		m.setStatus("Saving %s...", m.target.Key)
+		return m, saveValue(m.client, m.opts.Timeout, m.target.Key, m.editor.Value(), m.target.ModRevision)
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	return m, cmd
+}
+
+func (m *model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = modeBrowse
+		m.search.Blur()
+		return m, nil
+	case "enter":
+		m.search.Blur()
+		query := strings.TrimSpace(m.search.Value())
+		if query == "" {
+			m.mode = modeBrowse
+			return m, nil
+		}
+		m.setStatus("Searching %s...", m.dir)
+		return m, searchKeys(m.client, m.opts.Timeout, m.dir, query)
+	}
+
+	var cmd tea.Cmd
+	m.search, cmd = m.search.Update(msg)
+	return m, cmd
+}
+
+func (m *model) handleResultsKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		m.resultCursor = max(m.resultCursor-1, 0)
+	case "down", "j":
+		m.resultCursor = min(m.resultCursor+1, len(m.results)-1)
+	case "esc", "q":
+		m.mode = modeBrowse
+	case "enter":
+		if len(m.results) == 0 {
+			m.mode = modeBrowse
+			return nil
+		}
+		key := m.results[m.resultCursor]
+		return m.openDir(key[:strings.LastIndex(key, "/")+1], key)
+	}
+	return nil
+}
+
+func (m *model) handleContextsKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "up", "k":
+		m.contextCursor = max(m.contextCursor-1, 0)
+	case "down", "j":
+		m.contextCursor = min(m.contextCursor+1, len(m.opts.Contexts)-1)
+	case "esc", "q":
+		m.mode = modeBrowse
+	case "enter":
+		m.mode = modeBrowse
+		name := m.opts.Contexts[m.contextCursor]
+		if name == m.context {
+			return nil
+		}
+		m.setStatus("Connecting to %s...", name)
+		return connect(m.opts.Connect, name)
+	}
+	return nil
+}
+
+func (m *model) handleBrowseKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+			return m, m.selectionChanged()
+		}
+	case "down", "j":
+		if m.cursor < len(m.children)-1 {
+			m.cursor++
+			return m, m.selectionChanged()
+		}
+	case "home", "g":
+		m.cursor = 0
+		return m, m.selectionChanged()
+	case "end", "G":
+		m.cursor = max(len(m.children)-1, 0)
+		return m, m.selectionChanged()
+
+	case "enter", "right", "l":
+		if child := m.selected(); child != nil && child.Dir {
+			return m, m.openDir(child.Path(m.dir), "")
+		}
+	case "left", "h", "backspace":
+		if parent, ok := parentDir(m.dir); ok {
+			return m, m.openDir(parent, m.dir)
+		}
+
+	case "e":
+		switch {
+		case m.value == nil:
+			m.setError("✗ select a key to edit")
+		case m.valueFormat == formatBinary:
+			m.setError("✗ binary values cannot be edited here")
+		default:
+			m.mode = modeEdit
+			m.target = m.value
+			m.editor.SetValue(m.target.Value)
+			m.editor.Focus()
+			m.setStatus("Editing %s (revision %d)", m.target.Key, m.target.ModRevision)
+			return m, textarea.Blink
+		}
+	case "d":
+		if m.value == nil {
+			m.setError("✗ select a key to delete")
+			return m, nil
+		}
+		m.mode = modeConfirmDelete
+		m.target = m.value
+	case "/":
+		m.mode = modeSearch
+		m.search.SetValue("")
+		m.search.Focus()
+		return m, textinput.Blink
+	case "c":
+		if len(m.opts.Contexts) == 0 || m.opts.Connect == nil {
+			m.setError("✗ no other contexts configured; use 'etu login' to add one")
+			return m, nil
+		}
+		m.mode = modeContexts
+		for i, name := range m.opts.Contexts {
+			if name == m.context {
+				m.contextCursor = i
+			}
+		}
+	case "w":
+		if m.live {
+			m.live = false
+			m.stopWatch()
+			m.setStatus("Live updates off")
+			return m, nil
+		}
+		m.live = true
+		m.setStatus("Live updates on for %s", m.dir)
+		return m, m.startWatch()
+	case "r":
+		m.setStatus("Reloaded %s", m.dir)
+		if m.valueKey != "" {
+			return m, tea.Batch(m.reload(), loadValue(m.client, m.opts.Timeout, m.valueKey))
+		}
+		return m, m.reload()
+
+	case "pgup", "pgdown", "ctrl+u", "ctrl+d":
+		var cmd tea.Cmd
+		m.valueView, cmd = m.valueView.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// parentDir returns the directory above dir, or false at the top.
+func parentDir(dir string) (string, bool) {
+	trimmed := strings.TrimSuffix(dir, "/")
+	idx := strings.LastIndex(trimmed, "/")
+	if idx < 0 {
+		return "", false
+	}
+	return trimmed[:idx+1], true
+}
+
+// paneSizes returns the outer widths of the tree and value panes and the
+// inner height shared by both.
+func (m *model) paneSizes() (treeWidth, valueWidth, innerHeight int) {
+	treeWidth = max(m.width*2/5, minTreeWidth)
+	treeWidth = min(treeWidth, max(m.width-minValueWidth, 0))
+	valueWidth = max(m.width-treeWidth, 0)
+	innerHeight = max(m.height-headerHeight-footerHeight-paneChrome, 1)
+	return treeWidth, valueWidth, innerHeight
+}
+
+func (m *model) resize() {
+	_, valueWidth, innerHeight := m.paneSizes()
+	inner := max(valueWidth-4, 1)
+
+	// The value pane shows the key and its metadata above the value
+	m.valueView.Width = inner
+	m.valueView.Height = max(innerHeight-3, 1)
+	m.editor.SetWidth(inner)
+	m.editor.SetHeight(max(innerHeight-2, 1))
+	m.search.Width = max(m.width-4, 1)
+}
+
+func (m *model) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	treeWidth, valueWidth, innerHeight := m.paneSizes()
+
+	treePane, valuePane := activePaneStyle, paneStyle
+	if m.mode == modeEdit {
+		treePane, valuePane = paneStyle, activePaneStyle
+	}
+	tree := treePane.Width(max(treeWidth-2, 1)).Height(innerHeight).
+		Render(m.viewTree(max(treeWidth-4, 1), innerHeight))
+	value := valuePane.Width(max(valueWidth-2, 1)).Height(innerHeight).
+		Render(m.viewValue(max(valueWidth-4, 1)))
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.viewHeader(),
+		lipgloss.JoinHorizontal(lipgloss.Top, tree, value),
+		m.viewFooter(),
+	)
+}
+
+func (m *model) viewHeader() string {
+	parts := []string{titleStyle.Render("etu"), mutedStyle.Render("context:") + " " + m.context, dirStyle.Render(m.dir)}
+	if m.live {
+		parts = append(parts, successStyle.Render("● live"))
+	}
+	return clip(strings.Join(parts, "  "), m.width)
+}
+
+func (m *model) viewFooter() string {
+	var help string
+	switch m.mode {
+	case modeEdit:
+		help = "ctrl+s save · esc cancel"
+	case modeSearch:
+		help = m.search.View()
+	case modeResults:
+		help = "↑/↓ move · enter go to key · esc back"
+	case modeContexts:
+		help = "↑/↓ move · enter connect · esc back"
+	case modeConfirmDelete:
+		help = warningStyle.Render(fmt.Sprintf("Delete %s? [y/N]", m.target.Key))
+	default:
+		help = "↑/↓ move · enter open · ← up · e edit · d delete · / search · c context · w live · r reload · q quit"
+	}
+	if m.mode != modeSearch && m.mode != modeConfirmDelete {
+		help = mutedStyle.Render(help)
+	}
+
+	status := m.status
+	if m.statusErr {
+		status = errorStyle.Render(status)
+	}
+	return help + "\n" + status
+}
+
+// viewList renders items with the one at cursor highlighted, scrolled so
+// the cursor stays visible.
+func viewList(items []string, cursor, width, height int) string {
+	start := 0
+	if cursor >= height {
+		start = cursor - height + 1
+	}
+	end := min(start+height, len(items))
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		line := items[i]
+		if i == cursor {
+			line = selectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *model) viewTree(width, height int) string {
+	switch m.mode {
+	case modeResults:
+		items := make([]string, len(m.results))
+		for i, key := range m.results {
+			items[i] = clip(key, width)
+		}
+		return viewList(items, m.resultCursor, width, height)
+
+	case modeContexts:
+		items := make([]string, len(m.opts.Contexts))
+		for i, name := range m.opts.Contexts {
+			marker := "  "
+			if name == m.context {
+				marker = "* "
+			}
+			items[i] = clip(marker+name, width)
+		}
+		return viewList(items, m.contextCursor, width, height)
+	}
+
+	if len(m.children) == 0 {
+		return mutedStyle.Render("(empty)")
+	}
+
+	items := make([]string, len(m.children))
+	for i, c := range m.children {
+		if c.Dir {
+			count := fmt.Sprintf(" (%d)", c.Keys)
+			items[i] = dirStyle.Render(clip(c.Name+"/", width-len(count))) + mutedStyle.Render(count)
+			continue
+		}
+		name := c.Name
+		if name == "" {
+			name = "."
+		}
+		items[i] = clip(name, width)
+	}
+	return viewList(items, m.cursor, width, height)
+}
+
+func (m *model) viewValue(width int) string {
+	child := m.selected()
+	if m.mode == modeEdit {
+		return dirStyle.Render(clip(m.target.Key, width)) + "\n\n" + m.editor.View()
+	}
+	if child == nil {
+		return ""
+	}
+	if child.Dir {
+		return dirStyle.Render(clip(child.Path(m.dir), width)) + "\n" +
+			mutedStyle.Render(fmt.Sprintf("%d keys · enter to open", child.Keys))
+	}
+	if m.value == nil {
+		return dirStyle.Render(clip(m.valueKey, width)) + "\n" + mutedStyle.Render("loading...") + "\n\n" + m.valueView.View()
+	}
+
+	meta := fmt.Sprintf("%s · rev %d · version %d · %s",
+		m.valueFormat, m.value.ModRevision, m.value.Version, output.FormatBytes(int64(len(m.value.Value))))
+	if m.value.Lease != 0 {
+		meta += fmt.Sprintf(" · lease %x", m.value.Lease)
+	}
+	return dirStyle.Render(clip(m.value.Key, width)) + "\n" +
+		mutedStyle.Render(clip(meta, width)) + "\n\n" +
+		m.valueView.View()
+}
+
+// clip shortens s to width runes, marking the cut with an ellipsis.
+func clip(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:max(width-1, 0)]) + "…"
+}
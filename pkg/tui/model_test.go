@@ -0,0 +1,367 @@
+package tui
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+)
+
+// storeMock serves a fixed keyspace for range, count-only and exact reads.
+func storeMock(data map[string]string) *client.MockClient {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return &client.MockClient{
+		GetWithOptionsFunc: func(_ context.Context, key string, opts *client.GetOptions) (*client.GetResponse, error) {
+			resp := &client.GetResponse{Revision: 10}
+			if opts == nil || opts.RangeEnd == "" {
+				if v, ok := data[key]; ok {
+					resp.Kvs = []*client.KeyValue{{Key: key, Value: v, ModRevision: 5, Version: 1}}
+					resp.Count = 1
+				}
+				return resp, nil
+			}
+			for _, k := range keys {
+				if k < key || k >= opts.RangeEnd {
+					continue
+				}
+				if opts.CountOnly {
+					resp.Count++
+					continue
+				}
+				if opts.Limit > 0 && int64(len(resp.Kvs)) == opts.Limit {
+					resp.More = true
+					break
+				}
+				resp.Kvs = append(resp.Kvs, &client.KeyValue{Key: k, ModRevision: 5, Version: 1})
+			}
+			return resp, nil
+		},
+	}
+}
+
+func sampleStore() *client.MockClient {
+	return storeMock(map[string]string{
+		"/app/config/db":   `{"host":"db","port":5432}`,
+		"/app/config/port": "8080",
+		"/app/name":        "demo",
+		"/other/x":         "1",
+	})
+}
+
+func keyMsg(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "ctrl+s":
+		return tea.KeyMsg{Type: tea.KeyCtrlS}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// drain runs cmd and feeds the browser's own messages back into the model
+// until no more work is produced. Input widget messages such as cursor
+// blinks are dropped so timers never run.
+func drain(t *testing.T, m *model, cmd tea.Cmd) {
+	t.Helper()
+	queue := []tea.Cmd{cmd}
+	for steps := 0; len(queue) > 0; steps++ {
+		require.Less(t, steps, 100, "too many commands")
+		next := queue[0]
+		queue = queue[1:]
+		if next == nil {
+			continue
+		}
+
+		switch msg := next().(type) {
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		case childrenMsg, valueMsg, savedMsg, deletedMsg, searchMsg, connectedMsg, watchMsg:
+			_, follow := m.Update(msg)
+			queue = append(queue, follow)
+		}
+	}
+}
+
+func press(t *testing.T, m *model, keys ...string) {
+	t.Helper()
+	for _, k := range keys {
+		_, cmd := m.Update(keyMsg(k))
+		drain(t, m, cmd)
+	}
+}
+
+func startModel(t *testing.T, mock *client.MockClient, root string) *model {
+	t.Helper()
+	m := newModel(Options{Client: mock, Context: "dev", Root: root})
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	drain(t, m, m.Init())
+	return m
+}
+
+func TestModel_ListsRoot(t *testing.T) {
+	m := startModel(t, sampleStore(), "")
+
+	assert.Equal(t, "/", m.dir)
+	require.Len(t, m.children, 2)
+	assert.Equal(t, "app", m.children[0].Name)
+	assert.Equal(t, int64(3), m.children[0].Keys)
+	assert.Nil(t, m.value, "directories have no value")
+}
+
+func TestModel_OpenDirAndBack(t *testing.T) {
+	m := startModel(t, sampleStore(), "/")
+
+	press(t, m, "enter")
+	assert.Equal(t, "/app/", m.dir)
+	require.Len(t, m.children, 2)
+
+	press(t, m, "down")
+	require.NotNil(t, m.value)
+	assert.Equal(t, "/app/name", m.value.Key)
+	assert.Equal(t, "demo", m.value.Value)
+
+	press(t, m, "left")
+	assert.Equal(t, "/", m.dir)
+	assert.Equal(t, 0, m.cursor, "returning selects the directory we came from")
+}
+
+func TestModel_PrettyPrintsJSON(t *testing.T) {
+	m := startModel(t, sampleStore(), "/app/config")
+
+	require.NotNil(t, m.value)
+	assert.Equal(t, "/app/config/db", m.value.Key)
+	assert.Equal(t, formatJSON, m.valueFormat)
+	assert.Contains(t, m.View(), `"host": "db"`)
+}
+
+func TestModel_EditSavesWithRevisionGuard(t *testing.T) {
+	mock := sampleStore()
+	m := startModel(t, mock, "/app/config/")
+	press(t, m, "down")
+
+	press(t, m, "e")
+	assert.Equal(t, modeEdit, m.mode)
+	m.editor.SetValue("9090")
+	press(t, m, "ctrl+s")
+
+	require.Len(t, mock.TxnCalls, 1)
+	assert.Equal(t, []client.TxnGuard{{Key: "/app/config/port", ModRevision: 5}}, mock.TxnCalls[0].Guards)
+	assert.Equal(t, []client.TxnOp{{Type: client.TxnOpPut, Key: "/app/config/port", Value: "9090"}}, mock.TxnCalls[0].Ops)
+	assert.Equal(t, modeBrowse, m.mode)
+	assert.Contains(t, m.status, "Saved /app/config/port")
+}
+
+func TestModel_EditConflictKeepsEditor(t *testing.T) {
+	mock := sampleStore()
+	mock.TxnFunc = func(_ context.Context, _ []client.TxnGuard, _ []client.TxnOp) (int64, error) {
+		return 0, client.ErrTxnConflict
+	}
+	m := startModel(t, mock, "/app/config/")
+
+	press(t, m, "e", "ctrl+s")
+	assert.Equal(t, modeEdit, m.mode)
+	assert.True(t, m.statusErr)
+	assert.Contains(t, m.status, "was modified since it was loaded")
+
+	press(t, m, "esc")
+	assert.Equal(t, modeBrowse, m.mode)
+}
+
+func TestModel_DeleteAsksForConfirmation(t *testing.T) {
+	mock := sampleStore()
+	m := startModel(t, mock, "/app/config/")
+
+	press(t, m, "d")
+	assert.Equal(t, modeConfirmDelete, m.mode)
+	assert.Contains(t, m.View(), "Delete /app/config/db? [y/N]")
+
+	press(t, m, "n")
+	assert.Empty(t, mock.TxnCalls)
+	assert.Equal(t, "Deletion canceled", m.status)
+
+	press(t, m, "d", "y")
+	require.Len(t, mock.TxnCalls, 1)
+	assert.Equal(t, []client.TxnOp{{Type: client.TxnOpDelete, Key: "/app/config/db"}}, mock.TxnCalls[0].Ops)
+	assert.Contains(t, m.status, "Deleted /app/config/db")
+}
+
+func TestModel_DeleteRequiresKey(t *testing.T) {
+	m := startModel(t, sampleStore(), "/")
+
+	press(t, m, "d")
+	assert.Equal(t, modeBrowse, m.mode)
+	assert.Contains(t, m.status, "select a key to delete")
+}
+
+func TestModel_SearchJumpsToKey(t *testing.T) {
+	m := startModel(t, sampleStore(), "/")
+
+	press(t, m, "/")
+	assert.Equal(t, modeSearch, m.mode)
+	m.search.SetValue("PORT")
+	press(t, m, "enter")
+
+	assert.Equal(t, modeResults, m.mode)
+	assert.Equal(t, []string{"/app/config/port"}, m.results)
+
+	press(t, m, "enter")
+	assert.Equal(t, modeBrowse, m.mode)
+	assert.Equal(t, "/app/config/", m.dir)
+	require.NotNil(t, m.value)
+	assert.Equal(t, "/app/config/port", m.value.Key)
+}
+
+func TestModel_SwitchContext(t *testing.T) {
+	first := sampleStore()
+	second := storeMock(map[string]string{"/prod/key": "v"})
+	closed := false
+
+	m := newModel(Options{
+		Client:   first,
+		Cleanup:  func() { closed = true },
+		Context:  "dev",
+		Contexts: []string{"dev", "prod"},
+		Connect: func(name string) (client.EtcdClient, func(), error) {
+			assert.Equal(t, "prod", name)
+			return second, func() {}, nil
+		},
+	})
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	drain(t, m, m.Init())
+
+	press(t, m, "c")
+	assert.Equal(t, modeContexts, m.mode)
+	assert.Equal(t, 0, m.contextCursor)
+
+	press(t, m, "down", "enter")
+	assert.True(t, closed, "the previous connection is released")
+	assert.Equal(t, "prod", m.context)
+	require.Len(t, m.children, 1)
+	assert.Equal(t, "prod", m.children[0].Name)
+}
+
+func TestModel_SwitchContextWithoutContexts(t *testing.T) {
+	m := startModel(t, sampleStore(), "/")
+
+	press(t, m, "c")
+	assert.Equal(t, modeBrowse, m.mode)
+	assert.Contains(t, m.status, "no other contexts")
+}
+
+func TestModel_LiveUpdates(t *testing.T) {
+	mock := sampleStore()
+	events := make(chan client.WatchResponse, 1)
+	mock.WatchFunc = func(_ context.Context, _ string, _ *client.WatchOptions) client.WatchChan {
+		return events
+	}
+	m := startModel(t, mock, "/app/config/")
+
+	_, cmd := m.Update(keyMsg("w"))
+	assert.True(t, m.live)
+	require.Len(t, mock.WatchCalls, 1)
+	assert.Equal(t, "/app/config/", mock.WatchCalls[0].Key)
+	assert.True(t, mock.WatchCalls[0].Opts.Prefix)
+
+	// An event on the selected key reloads its value
+	reads := len(mock.GetWithOptionsCalls)
+	events <- client.WatchResponse{Events: []client.WatchEvent{{Type: client.WatchEventPut, Key: "/app/config/db"}}}
+	msg := cmd()
+	_, follow := m.Update(msg)
+	close(events) // lets the re-armed wait in the batch return
+	var exact int
+	for _, c := range follow().(tea.BatchMsg) {
+		if c == nil {
+			continue
+		}
+		if v, ok := c().(valueMsg); ok {
+			exact++
+			assert.Equal(t, "/app/config/db", v.key)
+		}
+	}
+	assert.Equal(t, 1, exact)
+	assert.Greater(t, len(mock.GetWithOptionsCalls), reads)
+
+	// Turning live updates off ignores responses from the old watch
+	press(t, m, "w")
+	assert.False(t, m.live)
+	_, follow = m.Update(watchMsg{gen: m.watchGen - 1})
+	assert.Nil(t, follow)
+}
+
+func TestModel_LiveUpdatesStopOnClose(t *testing.T) {
+	m := startModel(t, sampleStore(), "/")
+
+	press(t, m, "w")
+	assert.False(t, m.live)
+	assert.True(t, m.statusErr)
+	assert.Contains(t, m.status, "live updates stopped")
+}
+
+func TestModel_View(t *testing.T) {
+	m := startModel(t, sampleStore(), "/")
+	view := m.View()
+
+	assert.Contains(t, view, "context: dev")
+	assert.Contains(t, view, "app/")
+	assert.Contains(t, view, "(3)")
+	assert.Contains(t, view, "3 keys · enter to open")
+
+	for _, line := range strings.Split(view, "\n") {
+		assert.LessOrEqual(t, len([]rune(stripANSI(line))), 100)
+	}
+}
+
+func TestParentDir(t *testing.T) {
+	parent, ok := parentDir("/app/config/")
+	assert.True(t, ok)
+	assert.Equal(t, "/app/", parent)
+
+	parent, ok = parentDir("/app/")
+	assert.True(t, ok)
+	assert.Equal(t, "/", parent)
+
+	_, ok = parentDir("/")
+	assert.False(t, ok)
+}
+
+func TestClip(t *testing.T) {
+	assert.Equal(t, "short", clip("short", 10))
+	assert.Equal(t, "abcd…", clip("abcdefgh", 5))
+	assert.Equal(t, "", clip("abc", 0))
+}
+
+// stripANSI removes escape sequences so widths can be compared.
+func stripANSI(s string) string {
+	var b strings.Builder
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case r == '\x1b':
+			inEscape = true
+		case inEscape:
+			if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+				inEscape = false
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/kazuma-desu/etu/pkg/output"
+)
+
+// Value formats shown in the value pane.
+const (
+	formatJSON   = "json"
+	formatYAML   = "yaml"
+	formatText   = "text"
+	formatBinary = "binary"
+)
+
+// prettyValue formats JSON and YAML documents for display and reports the
+// detected format. Plain text is returned unchanged and binary values are
+// replaced by a short description.
+func prettyValue(value string) (string, string) {
+	if !utf8.ValidString(value) {
+		return fmt.Sprintf("(binary value, %s)", output.FormatBytes(int64(len(value)))), formatBinary
+	}
+
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return value, formatText
+	}
+
+	if (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(trimmed), "", "  "); err == nil {
+			return buf.String(), formatJSON
+		}
+	}
+
+	// Only mappings and sequences count as YAML; any scalar parses as YAML
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &node); err == nil && len(node.Content) == 1 {
+		if kind := node.Content[0].Kind; kind == yaml.MappingNode || kind == yaml.SequenceNode {
+			var buf bytes.Buffer
+			encoder := yaml.NewEncoder(&buf)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(&node); err == nil && encoder.Close() == nil {
+				return strings.TrimRight(buf.String(), "\n"), formatYAML
+			}
+		}
+	}
+
+	return value, formatText
+}
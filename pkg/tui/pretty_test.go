@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrettyValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   string
+		format string
+	}{
+		{
+			name:   "json object is indented",
+			value:  `{"a":1,"b":[true]}`,
+			want:   "{\n  \"a\": 1,\n  \"b\": [\n    true\n  ]\n}",
+			format: formatJSON,
+		},
+		{
+			name:   "json array",
+			value:  ` [1,2] `,
+			want:   "[\n  1,\n  2\n]",
+			format: formatJSON,
+		},
+		{
+			name:   "yaml mapping is re-indented",
+			value:  "a:\n    b: 1\n",
+			want:   "a:\n  b: 1",
+			format: formatYAML,
+		},
+		{
+			name:   "yaml scalar stays text",
+			value:  "8080",
+			want:   "8080",
+			format: formatText,
+		},
+		{
+			name:   "invalid json falls back to text",
+			value:  `{"a":`,
+			want:   `{"a":`,
+			format: formatText,
+		},
+		{
+			name:   "empty value",
+			value:  "",
+			want:   "",
+			format: formatText,
+		},
+		{
+			name:   "binary value",
+			value:  "\xff\xfe\x00",
+			want:   "(binary value, 3 B)",
+			format: formatBinary,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, format := prettyValue(tt.value)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.format, format)
+		})
+	}
+}
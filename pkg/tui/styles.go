@@ -0,0 +1,47 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+// Colors follow the palette used by pkg/output.
+var (
+	colorPrimary   = lipgloss.Color("#7C3AED") // Purple
+	colorSuccess   = lipgloss.Color("#10B981") // Green
+	colorWarning   = lipgloss.Color("#F59E0B") // Amber
+	colorError     = lipgloss.Color("#EF4444") // Red
+	colorMuted     = lipgloss.Color("#6B7280") // Gray
+	colorHighlight = lipgloss.Color("#06B6D4") // Cyan
+)
+
+var (
+	titleStyle = lipgloss.NewStyle().
+			Foreground(colorPrimary).
+			Bold(true)
+
+	paneStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(colorMuted).
+			Padding(0, 1)
+
+	activePaneStyle = paneStyle.BorderForeground(colorPrimary)
+
+	dirStyle = lipgloss.NewStyle().
+			Foreground(colorHighlight).
+			Bold(true)
+
+	selectedStyle = lipgloss.NewStyle().
+			Reverse(true)
+
+	mutedStyle = lipgloss.NewStyle().
+			Foreground(colorMuted)
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(colorError).
+			Bold(true)
+
+	warningStyle = lipgloss.NewStyle().
+			Foreground(colorWarning).
+			Bold(true)
+
+	successStyle = lipgloss.NewStyle().
+			Foreground(colorSuccess)
+)
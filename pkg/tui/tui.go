@@ -0,0 +1,52 @@
+// Package tui implements the interactive key browser behind 'etu ui'.
+//
+// The browser lists one directory level at a time with client.ListChildren,
+// shows the selected value pretty-printed, and edits and deletes keys with
+// transactions guarded by the revision that was displayed.
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/kazuma-desu/etu/pkg/client"
+)
+
+// DefaultTimeout bounds each etcd request made by the browser.
+const DefaultTimeout = 10 * time.Second
+
+// Options configures the browser.
+type Options struct {
+	// Client is the initial connection. Run takes ownership and calls
+	// Cleanup when the browser exits or switches context.
+	Client  client.EtcdClient
+	Cleanup func()
+
+	// Connect opens a connection for a context name. It is used by the
+	// context switcher; without it only the initial context is available.
+	Connect func(contextName string) (client.EtcdClient, func(), error)
+
+	// Context is the name of the initial context.
+	Context string
+
+	// Root is the directory shown first.
+	// Default: "/"
+	Root string
+
+	// Contexts lists the context names offered by the context switcher.
+	Contexts []string
+
+	// Timeout bounds each etcd request.
+	// Default: DefaultTimeout
+	Timeout time.Duration
+}
+
+// Run starts the full-screen browser and blocks until the user quits.
+func Run(opts Options) error {
+	m := newModel(opts)
+	defer m.close()
+
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
@@ -20,7 +20,9 @@ const (
 )
 
 var (
-	validKeyRE = regexp.MustCompile(`^/[a-zA-Z0-9/_\-\.]+$`)
+	// validKeyRE matches a key after its root, with separators replaced
+	// by "/"
+	validKeyRE = regexp.MustCompile(`^[a-zA-Z0-9/_\-\.]+$`)
 )
 
 // Func defines a validation function that can be plugged into the validator.
@@ -28,15 +30,28 @@ type Func func(pair *models.ConfigPair, result *ValidationResult)
 
 // KeyFormatValidator validates the format of an etcd key
 func KeyFormatValidator(pair *models.ConfigPair, result *ValidationResult) {
+	validateKeyFormat(pair, result, models.KeyLayout{})
+}
+
+// KeyLayoutValidator returns a KeyFormatValidator for keys in layout. Keys
+// must start with its root and may contain its separator.
+func KeyLayoutValidator(layout models.KeyLayout) Func {
+	return func(pair *models.ConfigPair, result *ValidationResult) {
+		validateKeyFormat(pair, result, layout)
+	}
+}
+
+func validateKeyFormat(pair *models.ConfigPair, result *ValidationResult, layout models.KeyLayout) {
 	if pair == nil {
 		result.addError("", "nil ConfigPair passed to KeyFormatValidator")
 		return
 	}
 
 	key := pair.Key
+	r := layout.Resolved()
 
-	if !strings.HasPrefix(key, "/") {
-		result.addError(key, "key must start with '/'")
+	if !strings.HasPrefix(key, r.Root) {
+		result.addError(key, fmt.Sprintf("key must start with '%s'", r.Root))
 		return
 	}
 
@@ -44,13 +59,18 @@ func KeyFormatValidator(pair *models.ConfigPair, result *ValidationResult) {
 		result.addError(key, fmt.Sprintf("key length exceeds maximum of %d characters", maxKeyLength))
 	}
 
-	depth := strings.Count(key, "/") - 1
+	depth := len(layout.Split(key)) - 1
 	if depth > maxKeyDepth {
 		result.addError(key, fmt.Sprintf("key depth exceeds maximum of %d levels", maxKeyDepth))
 	}
 
-	if !validKeyRE.MatchString(key) {
-		result.addError(key, "key contains invalid characters (allowed: a-z, A-Z, 0-9, /, _, -, .)")
+	rest := strings.ReplaceAll(strings.TrimPrefix(key, r.Root), r.Separator, "/")
+	if !validKeyRE.MatchString(rest) {
+		allowed := "/"
+		if r.Separator != "/" {
+			allowed = "/, " + r.Separator
+		}
+		result.addError(key, fmt.Sprintf("key contains invalid characters (allowed: a-z, A-Z, 0-9, %s, _, -, .)", allowed))
 	}
 }
 
@@ -159,6 +179,8 @@ type ValidationIssue struct {
 	Key     string
 	Message string
 	Level   string
+	// Position of the offending pair in its source file, if known
+	models.Position `yaml:",inline"`
 }
 
 // ValidationResult contains the results of validation
@@ -195,8 +217,14 @@ type Validator struct {
 
 // NewValidator creates a new validator with optional custom validators
 func NewValidator(strict bool, custom ...Func) *Validator {
+	return NewValidatorWithLayout(strict, models.KeyLayout{}, custom...)
+}
+
+// NewValidatorWithLayout creates a validator like NewValidator that checks
+// keys against layout
+func NewValidatorWithLayout(strict bool, layout models.KeyLayout, custom ...Func) *Validator {
 	validators := []Func{
-		KeyFormatValidator,
+		KeyLayoutValidator(layout),
 		ValueValidator,
 		StructuredDataValidator,
 		URLValidator,
@@ -218,7 +246,9 @@ func (v *Validator) Validate(pairs []*models.ConfigPair) *ValidationResult {
 		Issues: []ValidationIssue{},
 	}
 
-	seenKeys := make(map[string]bool)
+	// Keys only clash within the same target context
+	type contextKey struct{ context, key string }
+	seenKeys := make(map[contextKey]*models.ConfigPair)
 
 	for _, pair := range pairs {
 		// Guard against nil config pair
@@ -227,16 +257,28 @@ func (v *Validator) Validate(pairs []*models.ConfigPair) *ValidationResult {
 			continue
 		}
 
+		// Issues found for this pair take its source position
+		first := len(result.Issues)
+
 		// Check for duplicates
-		if seenKeys[pair.Key] {
-			result.addError(pair.Key, "duplicate key found")
-			continue
+		id := contextKey{pair.Context, pair.Key}
+		if seen, ok := seenKeys[id]; ok {
+			message := "duplicate key found"
+			if seen.Pos.IsValid() {
+				message += " (first defined at " + seen.Pos.String() + ")"
+			}
+			result.addError(pair.Key, message)
+		} else {
+			seenKeys[id] = pair
+
+			// Run all validators
+			for _, validator := range v.validators {
+				validator(pair, result)
+			}
 		}
-		seenKeys[pair.Key] = true
 
-		// Run all validators
-		for _, validator := range v.validators {
-			validator(pair, result)
+		for i := first; i < len(result.Issues); i++ {
+			result.Issues[i].Position = pair.Pos
 		}
 	}
 
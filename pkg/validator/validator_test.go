@@ -216,6 +216,46 @@ func TestValidator_ValidateKey(t *testing.T) {
 	}
 }
 
+func TestKeyLayoutValidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		layout   models.KeyLayout
+		key      string
+		errorMsg string
+	}{
+		{"dotted key", models.KeyLayout{Separator: "."}, "app.db.host", ""},
+		{"colon key with root", models.KeyLayout{Separator: ":", Root: "config:"}, "config:app:db", ""},
+		{"missing root", models.KeyLayout{Separator: ":", Root: "config:"}, "app:db", "key must start with 'config:'"},
+		{"separator is allowed", models.KeyLayout{Separator: ":"}, "app:db", ""},
+		{"other characters are not", models.KeyLayout{Separator: ":"}, "app@db", "invalid characters (allowed: a-z, A-Z, 0-9, /, :, _, -, .)"},
+		{"depth counts segments", models.KeyLayout{Separator: "."}, strings.Repeat("a.", 21) + "a", "depth exceeds"},
+		{"bare root", models.KeyLayout{}, "/", "invalid characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ValidationResult{Issues: []ValidationIssue{}}
+			KeyLayoutValidator(tt.layout)(&models.ConfigPair{Key: tt.key, Value: "test"}, result)
+
+			if tt.errorMsg == "" {
+				assert.Empty(t, result.Issues)
+				return
+			}
+			require.NotEmpty(t, result.Issues)
+			assert.Contains(t, result.Issues[0].Message, tt.errorMsg)
+		})
+	}
+}
+
+func TestNewValidatorWithLayout(t *testing.T) {
+	v := NewValidatorWithLayout(false, models.KeyLayout{Separator: "."})
+	result := v.Validate([]*models.ConfigPair{{Key: "app.name", Value: "api"}})
+	assert.True(t, result.Valid)
+
+	result = NewValidator(false).Validate([]*models.ConfigPair{{Key: "app.name", Value: "api"}})
+	assert.False(t, result.Valid)
+}
+
 func TestValidator_ValidateValue_NilPair(t *testing.T) {
 	result := &ValidationResult{Issues: []ValidationIssue{}}
 	ValueValidator(nil, result)
@@ -467,6 +507,44 @@ func TestValidator_StructuredDataValidation(t *testing.T) {
 	}
 }
 
+func TestValidator_IssuePositions(t *testing.T) {
+	v := NewValidator(false)
+	pairs := []*models.ConfigPair{
+		{Key: "/app/name", Value: "ok", Pos: models.Position{File: "app.yaml", Line: 1, Column: 1}},
+		{Key: "/app/empty", Value: "", Pos: models.Position{File: "app.yaml", Line: 2, Column: 3}},
+		{Key: "/app/name", Value: "again", Pos: models.Position{File: "app.yaml", Line: 7, Column: 1}},
+		{Key: "no-slash", Value: "x"},
+	}
+
+	result := v.Validate(pairs)
+
+	require.Len(t, result.Issues, 3)
+	assert.Equal(t, "/app/empty", result.Issues[0].Key)
+	assert.Equal(t, "app.yaml:2:3", result.Issues[0].Position.String())
+
+	assert.Equal(t, "/app/name", result.Issues[1].Key)
+	assert.Equal(t, "duplicate key found (first defined at app.yaml:1:1)", result.Issues[1].Message)
+	assert.Equal(t, 7, result.Issues[1].Line)
+
+	assert.Equal(t, "no-slash", result.Issues[2].Key)
+	assert.False(t, result.Issues[2].Position.IsValid())
+}
+
+func TestValidator_DuplicatesPerContext(t *testing.T) {
+	v := NewValidator(false)
+	pairs := []*models.ConfigPair{
+		{Key: "/app/name", Value: "staging", Context: "staging"},
+		{Key: "/app/name", Value: "prod", Context: "prod"},
+		{Key: "/app/name", Value: "again", Context: "prod"},
+	}
+
+	result := v.Validate(pairs)
+
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, "/app/name", result.Issues[0].Key)
+	assert.Contains(t, result.Issues[0].Message, "duplicate key found")
+}
+
 func TestNewValidator_CustomValidators(t *testing.T) {
 	customCalled := false
 	customValidator := func(pair *models.ConfigPair, result *ValidationResult) {